@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/fatih/color"
+
+	"project-starter/internal/agent"
+	"project-starter/internal/archive"
+	"project-starter/internal/project"
+)
+
+func runAgentCommand(args []string) error {
+	flags := flag.NewFlagSet("agent", flag.ContinueOnError)
+	root := flags.String("root", "", "workspace root to watch, defaults to the configured workspace root")
+	format := flags.String("format", string(archive.FormatZip), "archive format: zip, tar.gz, tar.zst or tar")
+	level := flags.Int("level", archive.DefaultLevel, "compression level, -1 uses the format default")
+	printUnit := flags.Bool("print-unit", false, "print a systemd user unit for the agent and exit")
+	installUnit := flags.Bool("install-unit", false, "install the systemd user unit and exit")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: project-starter agent [--root dir] [--format f] [--level n] [--print-unit | --install-unit]")
+		flags.PrintDefaults()
+	}
+	if _, err := parseFlags(flags, args); err != nil {
+		if isHelp(err) {
+			return nil
+		}
+		return err
+	}
+
+	workspaceRoot, err := workspaceRoot(*root)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case *printUnit:
+		unit, err := agent.Unit(workspaceRoot)
+		if err != nil {
+			return err
+		}
+		fmt.Print(unit)
+		return nil
+	case *installUnit:
+		path, err := agent.InstallUnit(workspaceRoot)
+		if err != nil {
+			return err
+		}
+		color.Green("Installed %s", path)
+		color.Cyan("Enable it with: systemctl --user daemon-reload && systemctl --user enable --now project-starter-agent")
+		return nil
+	}
+
+	archiveFormat, err := archive.ParseFormat(*format)
+	if err != nil {
+		return err
+	}
+	if err := archiveFormat.ValidateLevel(*level); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return agent.Run(ctx, agent.Options{
+		Root:   workspaceRoot,
+		Backup: project.BackupOptions{Format: archiveFormat, Level: *level},
+	})
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"project-starter/internal/project"
+)
+
+func runAuditCommand(args []string) error {
+	if len(args) > 0 && args[0] == "import" {
+		return runAuditImportCommand(args[1:])
+	}
+
+	flags := flag.NewFlagSet("audit", flag.ContinueOnError)
+	format := flags.String("format", "table", "output format: table or json")
+	db := flags.String("db", "", "directory of the OSV database, defaults to the configured one")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: project-starter audit [--format table|json] [--db dir] [project-dir]")
+		fmt.Fprintln(flags.Output(), "       project-starter audit import <osv-zip>... [--db dir]")
+		flags.PrintDefaults()
+	}
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		if isHelp(err) {
+			return nil
+		}
+		return err
+	}
+	if len(positional) > 1 {
+		flags.Usage()
+		return fmt.Errorf("expected at most one project directory")
+	}
+
+	projectPath := "."
+	if len(positional) == 1 {
+		projectPath = positional[0]
+	}
+	if info, err := os.Stat(projectPath); err != nil {
+		return err
+	} else if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", projectPath)
+	}
+	dbDir, err := auditDatabase(*db)
+	if err != nil {
+		return err
+	}
+	return project.AuditDependencies(os.Stdout, projectPath, dbDir, *format)
+}
+
+func runAuditImportCommand(args []string) error {
+	flags := flag.NewFlagSet("audit import", flag.ContinueOnError)
+	db := flags.String("db", "", "directory to extract the records to, defaults to the configured database")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: project-starter audit import <osv-zip>... [--db dir]")
+		fmt.Fprintln(flags.Output(), "Exports are published per ecosystem, e.g. https://osv-vulnerabilities.storage.googleapis.com/Go/all.zip")
+		flags.PrintDefaults()
+	}
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		if isHelp(err) {
+			return nil
+		}
+		return err
+	}
+	if len(positional) == 0 {
+		flags.Usage()
+		return fmt.Errorf("expected at least one zip file")
+	}
+	dbDir, err := auditDatabase(*db)
+	if err != nil {
+		return err
+	}
+	return project.ImportVulnerabilityDatabase(positional, dbDir)
+}
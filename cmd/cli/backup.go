@@ -0,0 +1,176 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"project-starter/internal/agent"
+	"project-starter/internal/archive"
+	"project-starter/internal/project"
+	"project-starter/internal/secrets"
+)
+
+func runBackupCommand(args []string) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "verify":
+			return runBackupVerifyCommand(args[1:])
+		case "upload":
+			return runBackupUploadCommand(args[1:])
+		case "status":
+			return agent.PrintStatus()
+		case "ls":
+			return runBackupListCommand(args[1:])
+		case "cat":
+			return runBackupCatCommand(args[1:])
+		case "browse":
+			return runBackupBrowseCommand(args[1:])
+		}
+	}
+
+	flags := flag.NewFlagSet("backup", flag.ContinueOnError)
+	format := flags.String("format", string(archive.FormatZip), "archive format: zip, tar.gz, tar.zst or tar")
+	level := flags.Int("level", archive.DefaultLevel, "compression level, -1 uses the format default")
+	store := flags.String("store", "", "configured backup store to upload to instead of the project's default")
+	all := flags.Bool("all", false, "back up every project under the workspace root")
+	root := flags.String("root", "", "workspace root for --all, defaults to the configured workspace root")
+	changedSince := flags.String("changed-since", "", "with --all, only back up projects modified within this window (e.g. 24h, 7d)")
+	jobs := flags.Int("jobs", 2, "with --all, number of projects backed up at the same time")
+	secretsAction := flags.String("secrets", "", "what to do with files containing secrets: exclude, redact, abort or ignore (asks by default)")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: project-starter backup [--format zip|tar.gz|tar.zst|tar] [--level n] [--store name] [--secrets action] [project-dir]")
+		fmt.Fprintln(flags.Output(), "       project-starter backup --all [--root dir] [--changed-since 24h] [--jobs n]")
+		fmt.Fprintln(flags.Output(), "       project-starter backup verify <archive> [--against project-dir]")
+		fmt.Fprintln(flags.Output(), "       project-starter backup upload <archive> [--store name]")
+		fmt.Fprintln(flags.Output(), "       project-starter backup status")
+		fmt.Fprintln(flags.Output(), "       project-starter backup ls <archive> [path]")
+		fmt.Fprintln(flags.Output(), "       project-starter backup cat <archive> <file>")
+		fmt.Fprintln(flags.Output(), "       project-starter backup browse <archive>")
+		flags.PrintDefaults()
+	}
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		if isHelp(err) {
+			return nil
+		}
+		return err
+	}
+
+	archiveFormat, err := archive.ParseFormat(*format)
+	if err != nil {
+		return err
+	}
+	if err := archiveFormat.ValidateLevel(*level); err != nil {
+		return err
+	}
+	opts := project.BackupOptions{Format: archiveFormat, Level: *level, Store: *store}
+	if *secretsAction != "" {
+		if opts.Secrets, err = secrets.ParseAction(*secretsAction); err != nil {
+			return err
+		}
+	}
+
+	if *all {
+		if len(positional) > 0 {
+			return fmt.Errorf("--all does not take a project directory")
+		}
+		batch := project.BatchOptions{Jobs: *jobs}
+		if *changedSince != "" {
+			if batch.ChangedSince, err = parseAge(*changedSince); err != nil {
+				return fmt.Errorf("invalid --changed-since: %v", err)
+			}
+		}
+		workspaceRoot, err := workspaceRoot(*root)
+		if err != nil {
+			return err
+		}
+		return project.BackupWorkspace(workspaceRoot, opts, batch)
+	}
+
+	// Without a project directory, pick one from the current directory
+	if len(positional) == 0 {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		return project.BackupProject(cwd, opts)
+	}
+
+	projectPath, err := filepath.Abs(positional[0])
+	if err != nil {
+		return err
+	}
+	_, err = project.CreateBackup(projectPath, filepath.Dir(projectPath), opts)
+	return err
+}
+
+func runBackupVerifyCommand(args []string) error {
+	flags := flag.NewFlagSet("backup verify", flag.ContinueOnError)
+	against := flags.String("against", "", "project directory to compare the backup with")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: project-starter backup verify <archive> [--against project-dir]")
+		flags.PrintDefaults()
+	}
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		if isHelp(err) {
+			return nil
+		}
+		return err
+	}
+	if len(positional) != 1 {
+		flags.Usage()
+		return fmt.Errorf("expected exactly one archive")
+	}
+
+	return project.VerifyBackup(positional[0], *against)
+}
+
+func runBackupUploadCommand(args []string) error {
+	flags := flag.NewFlagSet("backup upload", flag.ContinueOnError)
+	store := flags.String("store", "", "configured backup store, defaults to the one configured for the project")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: project-starter backup upload <archive> [--store name]")
+		flags.PrintDefaults()
+	}
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		if isHelp(err) {
+			return nil
+		}
+		return err
+	}
+	if len(positional) != 1 {
+		flags.Usage()
+		return fmt.Errorf("expected exactly one archive")
+	}
+
+	return project.UploadBackup(positional[0], *store)
+}
+
+func runBackupListCommand(args []string) error {
+	if len(args) < 1 || len(args) > 2 {
+		return fmt.Errorf("usage: project-starter backup ls <archive> [path]")
+	}
+	dir := ""
+	if len(args) == 2 {
+		dir = args[1]
+	}
+	return project.ListBackup(args[0], dir)
+}
+
+func runBackupCatCommand(args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("usage: project-starter backup cat <archive> <file>")
+	}
+	return archive.Cat(args[0], args[1], os.Stdout)
+}
+
+func runBackupBrowseCommand(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: project-starter backup browse <archive>")
+	}
+	return project.BrowseBackup(args[0])
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"project-starter/internal/project"
+)
+
+func runDashboardCommand(args []string) error {
+	flags := flag.NewFlagSet("dashboard", flag.ContinueOnError)
+	root := flags.String("root", "", "workspace root, defaults to the configured workspace root")
+	sortBy := flags.String("sort", "name", "column to sort by: "+strings.Join(project.DashboardSorts, ", "))
+	stale := flags.String("stale", "", "only show projects not modified within this window (e.g. 90d)")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: project-starter dashboard [--root dir] [--sort column] [--stale 90d]")
+		flags.PrintDefaults()
+	}
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		if isHelp(err) {
+			return nil
+		}
+		return err
+	}
+	if len(positional) > 0 {
+		flags.Usage()
+		return fmt.Errorf("unexpected argument %q", positional[0])
+	}
+
+	opts := project.DashboardOptions{Sort: *sortBy}
+	if *stale != "" {
+		if opts.Stale, err = parseAge(*stale); err != nil {
+			return fmt.Errorf("invalid --stale: %v", err)
+		}
+	}
+	workspaceRoot, err := workspaceRoot(*root)
+	if err != nil {
+		return err
+	}
+	return project.ShowDashboard(workspaceRoot, opts)
+}
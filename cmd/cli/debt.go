@@ -0,0 +1,40 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"project-starter/internal/project"
+)
+
+func runDebtCommand(args []string) error {
+	flags := flag.NewFlagSet("debt", flag.ContinueOnError)
+	format := flags.String("format", "table", "output format: table or json")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: project-starter debt [--format table|json] [project-dir]")
+		flags.PrintDefaults()
+	}
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		if isHelp(err) {
+			return nil
+		}
+		return err
+	}
+	if len(positional) > 1 {
+		flags.Usage()
+		return fmt.Errorf("expected at most one project directory")
+	}
+
+	projectPath := "."
+	if len(positional) == 1 {
+		projectPath = positional[0]
+	}
+	if info, err := os.Stat(projectPath); err != nil {
+		return err
+	} else if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", projectPath)
+	}
+	return project.PrintDebt(os.Stdout, projectPath, *format)
+}
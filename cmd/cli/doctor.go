@@ -0,0 +1,53 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"project-starter/internal/project"
+)
+
+func runDoctorCommand(args []string) error {
+	flags := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	format := flags.String("format", "table", "output format: table or json")
+	strict := flags.Bool("strict", false, "exit with an error on warnings too")
+	list := flags.Bool("rules", false, "list the rules instead of running them")
+	fix := flags.Bool("fix", false, "fix what the rules found after showing a diff of the changes")
+	yes := flags.Bool("yes", false, "with --fix, write the changes without asking")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: project-starter doctor [--format table|json] [--strict] [project-dir]")
+		fmt.Fprintln(flags.Output(), "       project-starter doctor --fix [--yes] [--strict] [project-dir]")
+		fmt.Fprintln(flags.Output(), "       project-starter doctor --rules [project-dir]")
+		fmt.Fprintln(flags.Output(), "Rules are configured in the doctor section of the config file and in a project's .doctor.json.")
+		flags.PrintDefaults()
+	}
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		if isHelp(err) {
+			return nil
+		}
+		return err
+	}
+	if len(positional) > 1 {
+		flags.Usage()
+		return fmt.Errorf("expected at most one project directory")
+	}
+
+	projectPath := "."
+	if len(positional) == 1 {
+		projectPath = positional[0]
+	}
+	if info, err := os.Stat(projectPath); err != nil {
+		return err
+	} else if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", projectPath)
+	}
+	if *yes && !*fix {
+		return fmt.Errorf("--yes only applies to --fix")
+	}
+	if *list {
+		return project.PrintDoctorRules(os.Stdout, projectPath)
+	}
+	return project.RunDoctor(os.Stdout, projectPath, project.DoctorOptions{Format: *format, Strict: *strict, Fix: *fix, Yes: *yes})
+}
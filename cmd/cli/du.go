@@ -0,0 +1,39 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"project-starter/internal/project"
+)
+
+func runDiskUsageCommand(args []string) error {
+	flags := flag.NewFlagSet("du", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: project-starter du [project-dir]")
+		fmt.Fprintln(flags.Output(), "Browse the disk usage of a project, leave paths out of backups and delete build output.")
+	}
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		if isHelp(err) {
+			return nil
+		}
+		return err
+	}
+	if len(positional) > 1 {
+		flags.Usage()
+		return fmt.Errorf("expected at most one project directory")
+	}
+
+	projectPath := "."
+	if len(positional) == 1 {
+		projectPath = positional[0]
+	}
+	if info, err := os.Stat(projectPath); err != nil {
+		return err
+	} else if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", projectPath)
+	}
+	return project.BrowseDiskUsage(projectPath)
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/dustin/go-humanize"
+
+	"project-starter/internal/project"
+)
+
+func runDupesCommand(args []string) error {
+	flags := flag.NewFlagSet("dupes", flag.ContinueOnError)
+	root := flags.String("root", "", "workspace root, defaults to the configured workspace root")
+	projectName := flags.String("project", "", "only look for duplicates within this project of the workspace")
+	minSize := flags.String("min-size", "1KB", "leave out files smaller than this (e.g. 100KB, 1MB)")
+	limit := flags.Int("limit", 25, "number of duplicate sets to list, 0 for all")
+	format := flags.String("format", "table", "output format: table or json")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: project-starter dupes [--root dir] [--project name] [--min-size 1KB] [--limit n] [--format table|json]")
+		flags.PrintDefaults()
+	}
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		if isHelp(err) {
+			return nil
+		}
+		return err
+	}
+	if len(positional) > 0 {
+		flags.Usage()
+		return fmt.Errorf("unexpected argument %q", positional[0])
+	}
+
+	min, err := humanize.ParseBytes(*minSize)
+	if err != nil {
+		return fmt.Errorf("invalid --min-size: %v", err)
+	}
+	workspaceRoot, err := workspaceRoot(*root)
+	if err != nil {
+		return err
+	}
+	return project.FindDuplicates(os.Stdout, workspaceRoot, project.DupesOptions{
+		Project: *projectName,
+		MinSize: int64(min),
+		Limit:   *limit,
+		Format:  *format,
+	})
+}
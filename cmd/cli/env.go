@@ -0,0 +1,37 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"project-starter/internal/project"
+)
+
+func runEnvCommand(args []string) error {
+	if len(args) == 0 || args[0] != "doctor" {
+		fmt.Fprintln(os.Stderr, "Usage: project-starter env doctor [--template name] [--format table|json]")
+		return fmt.Errorf("expected the doctor subcommand")
+	}
+
+	flags := flag.NewFlagSet("env doctor", flag.ContinueOnError)
+	template := flags.String("template", "", "only check the tools of this template, and fail when one is missing")
+	format := flags.String("format", "table", "output format: table or json")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: project-starter env doctor [--template name] [--format table|json]")
+		fmt.Fprintln(flags.Output(), "Checks the tools templates need are installed and recent enough.")
+		flags.PrintDefaults()
+	}
+	positional, err := parseFlags(flags, args[1:])
+	if err != nil {
+		if isHelp(err) {
+			return nil
+		}
+		return err
+	}
+	if len(positional) > 0 {
+		flags.Usage()
+		return fmt.Errorf("unexpected arguments")
+	}
+	return project.CheckEnvironment(os.Stdout, *template, *format)
+}
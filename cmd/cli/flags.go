@@ -0,0 +1,78 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"strconv"
+	"strings"
+	"time"
+
+	"project-starter/internal/config"
+)
+
+// parseFlags parses args allowing flags to follow positional arguments, so
+// "backup verify x.zip --against dir" works like the flags came first. It
+// returns the positional arguments. Arguments after "--" are positional even
+// when they look like flags.
+func parseFlags(flags *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := flags.Args()
+		// Parse stops after consuming a "--" terminator
+		if consumed := len(args) - len(rest); consumed > 0 && args[consumed-1] == "--" {
+			return append(positional, rest...), nil
+		}
+		args = rest
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// isHelp reports whether err is the result of -h or --help.
+func isHelp(err error) bool {
+	return errors.Is(err, flag.ErrHelp)
+}
+
+// parseAge parses a duration like time.ParseDuration and also accepts whole
+// days such as "90d".
+func parseAge(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, errors.New("expected a number of days like 7d")
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
+
+// workspaceRoot returns root, or the configured workspace root when empty.
+func workspaceRoot(root string) (string, error) {
+	if root != "" {
+		return root, nil
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return "", err
+	}
+	return cfg.WorkspaceRoot, nil
+}
+
+// auditDatabase returns db, or the configured vulnerability database when
+// empty.
+func auditDatabase(db string) (string, error) {
+	if db != "" {
+		return db, nil
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return "", err
+	}
+	return cfg.AuditDatabase()
+}
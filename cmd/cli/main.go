@@ -12,13 +12,43 @@ import (
 	"github.com/common-nighthawk/go-figure"
 	"github.com/fatih/color"
 
+	"project-starter/internal/archive"
+	"project-starter/internal/config"
 	"project-starter/internal/project"
 	"project-starter/internal/update"
 )
 
 const Version = "1.0.0"
 
+// commands maps subcommand names to their handlers. Anything else starts the
+// interactive menu.
+var commands = map[string]func(args []string) error{
+	"agent":     runAgentCommand,
+	"audit":     runAuditCommand,
+	"backup":    runBackupCommand,
+	"dashboard": runDashboardCommand,
+	"debt":      runDebtCommand,
+	"deps":      runDepsCommand,
+	"doctor":    runDoctorCommand,
+	"du":        runDiskUsageCommand,
+	"dupes":     runDupesCommand,
+	"env":       runEnvCommand,
+	"sbom":      runSBOMCommand,
+	"scan":      runScanCommand,
+	"stats":     runStatsCommand,
+}
+
 func main() {
+	if len(os.Args) > 1 {
+		if command, ok := commands[os.Args[1]]; ok {
+			if err := command(os.Args[2:]); err != nil {
+				color.New(color.FgRed).Fprintf(os.Stderr, "%s failed: %v\n", os.Args[1], err)
+				os.Exit(1)
+			}
+			os.Exit(0)
+		}
+	}
+
 	// Set up context for graceful exit
 	interrupt := make(chan os.Signal, 1)
 	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
@@ -49,7 +79,7 @@ func main() {
 	// Handle interrupt in a separate goroutine
 	go func() {
 		<-interrupt
-		fmt.Println("\n")
+		fmt.Print("\n\n")
 		color.Yellow("Operation canceled. Goodbye!")
 		cancel()
 		os.Exit(0)
@@ -63,8 +93,13 @@ func main() {
 	// Ask what to build
 	color.Yellow("\nWhat would you like to build today?")
 
-	// Start from D:/vscode/
-	currentPath := "D:/vscode/"
+	// Start from the configured workspace root
+	cfg, err := config.Load()
+	if err != nil {
+		color.Red("Error loading config: %v", err)
+		os.Exit(1)
+	}
+	currentPath := cfg.WorkspaceRoot
 
 	// Run the main loop with context
 	err = run(ctx, currentPath)
@@ -72,7 +107,7 @@ func main() {
 	// Check if the context was canceled (i.e., Ctrl-C was pressed)
 	if err != nil {
 		if err == context.Canceled {
-			fmt.Println("\n")
+			fmt.Print("\n\n")
 			color.Yellow("Operation canceled. Goodbye!")
 		} else {
 			color.Red("An error occurred: %v", err)
@@ -98,6 +133,7 @@ func run(ctx context.Context, startPath string) error {
 				"[Go back]",
 				"[View Project Statistics]",
 				"[Backup Project]",
+				"[Explore Disk Usage]",
 			}, dirs...)
 
 			var selected string
@@ -131,12 +167,19 @@ func run(ctx context.Context, startPath string) error {
 					color.Red("Error viewing project statistics: %v", err)
 				}
 			case "[Backup Project]":
-				if err := project.BackupProject(currentPath); err != nil {
+				if err := project.BackupProject(currentPath, project.BackupOptions{Level: archive.DefaultLevel}); err != nil {
 					if err == context.Canceled {
 						return err
 					}
 					color.Red("Error backing up project: %v", err)
 				}
+			case "[Explore Disk Usage]":
+				if err := project.ExploreDiskUsage(currentPath); err != nil {
+					if err == context.Canceled {
+						return err
+					}
+					color.Red("Error exploring disk usage: %v", err)
+				}
 			default:
 				currentPath = filepath.Join(currentPath, selected)
 			}
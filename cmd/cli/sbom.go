@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"project-starter/internal/project"
+	"project-starter/internal/sbom"
+)
+
+func runSBOMCommand(args []string) error {
+	flags := flag.NewFlagSet("sbom", flag.ContinueOnError)
+	format := flags.String("format", sbom.FormatCycloneDX, "document format: "+strings.Join(sbom.Formats, " or "))
+	output := flags.String("output", "", "file to write the document to instead of standard output")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: project-starter sbom [--format cyclonedx-json|spdx-json] [--output file] [project-dir]")
+		flags.PrintDefaults()
+	}
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		if isHelp(err) {
+			return nil
+		}
+		return err
+	}
+	if len(positional) > 1 {
+		flags.Usage()
+		return fmt.Errorf("expected at most one project directory")
+	}
+
+	projectPath := "."
+	if len(positional) == 1 {
+		projectPath = positional[0]
+	}
+	if info, err := os.Stat(projectPath); err != nil {
+		return err
+	} else if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", projectPath)
+	}
+
+	if *output == "" {
+		return project.WriteSBOM(os.Stdout, projectPath, *format, Version)
+	}
+	file, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	err = project.WriteSBOM(file, projectPath, *format, Version)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(*output)
+	}
+	return err
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"project-starter/internal/project"
+)
+
+func runScanCommand(args []string) error {
+	if len(args) == 0 || args[0] != "secrets" {
+		return fmt.Errorf("usage: project-starter scan secrets [path]")
+	}
+
+	flags := flag.NewFlagSet("scan secrets", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: project-starter scan secrets [path]")
+		fmt.Fprintln(flags.Output(), "Reports possible secrets and exits with status 1 if any are found.")
+		flags.PrintDefaults()
+	}
+	positional, err := parseFlags(flags, args[1:])
+	if err != nil {
+		if isHelp(err) {
+			return nil
+		}
+		return err
+	}
+	if len(positional) > 1 {
+		flags.Usage()
+		return fmt.Errorf("expected at most one path")
+	}
+
+	path := "."
+	if len(positional) == 1 {
+		path = positional[0]
+	}
+	if _, err := os.Stat(path); err != nil {
+		return err
+	}
+	return project.ScanSecrets(path)
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"project-starter/internal/project"
+)
+
+func runStatsCommand(args []string) error {
+	flags := flag.NewFlagSet("stats", flag.ContinueOnError)
+	format := flags.String("format", "table", "output format: table, json or csv")
+	trend := flags.Bool("trend", false, "show how the statistics recorded by earlier runs changed instead")
+	since := flags.String("since", "", "with --trend, only use statistics recorded within this window (e.g. 30d)")
+	noHistory := flags.Bool("no-history", false, "don't add the statistics to the history --trend reads")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: project-starter stats [--format table|json|csv] [--no-history] [project-dir]")
+		fmt.Fprintln(flags.Output(), "       project-starter stats --trend [--since 90d] [--format table|json|csv] [project-dir]")
+		flags.PrintDefaults()
+	}
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		if isHelp(err) {
+			return nil
+		}
+		return err
+	}
+	if len(positional) > 1 {
+		flags.Usage()
+		return fmt.Errorf("expected at most one project directory")
+	}
+
+	projectPath := "."
+	if len(positional) == 1 {
+		projectPath = positional[0]
+	}
+	if info, err := os.Stat(projectPath); err != nil {
+		return err
+	} else if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", projectPath)
+	}
+
+	if *since != "" && !*trend {
+		return fmt.Errorf("--since only applies to --trend")
+	}
+	if *noHistory && *trend {
+		return fmt.Errorf("--no-history doesn't apply to --trend")
+	}
+	if *trend {
+		var from time.Time
+		if *since != "" {
+			age, err := parseAge(*since)
+			if err != nil {
+				return fmt.Errorf("invalid --since: %v", err)
+			}
+			from = time.Now().Add(-age)
+		}
+		return project.PrintStatsTrend(os.Stdout, projectPath, *format, from)
+	}
+	return project.PrintStats(os.Stdout, projectPath, *format, !*noHistory)
+}
@@ -2,9 +2,17 @@ module project-starter
 
 go 1.22.0
 
-require github.com/briandowns/spinner v1.23.1
+require (
+	github.com/briandowns/spinner v1.23.1
+	github.com/fsnotify/fsnotify v1.7.0
+	github.com/klauspost/compress v1.17.11
+	github.com/pkg/sftp v1.13.7
+	golang.org/x/crypto v0.28.0
+	golang.org/x/net v0.30.0
+)
 
 require (
+	github.com/kr/fs v0.1.0 // indirect
 	github.com/mitchellh/colorstring v0.0.0-20190213212951-d06e56a500db // indirect
 	github.com/rivo/uniseg v0.4.7 // indirect
 )
@@ -22,7 +30,7 @@ require (
 	github.com/mattn/go-isatty v0.0.20 // indirect
 	github.com/mgutz/ansi v0.0.0-20170206155736-9520e82c474b // indirect
 	github.com/schollz/progressbar/v3 v3.16.1
-	golang.org/x/sys v0.26.0 // indirect
-	golang.org/x/term v0.24.0 // indirect
-	golang.org/x/text v0.4.0 // indirect
+	golang.org/x/sys v0.26.0
+	golang.org/x/term v0.25.0 // indirect
+	golang.org/x/text v0.19.0 // indirect
 )
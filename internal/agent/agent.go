@@ -0,0 +1,391 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"project-starter/internal/config"
+	"project-starter/internal/project"
+)
+
+const defaultDebounce = 30 * time.Second
+
+// skipWatchDirs are not watched because they churn constantly and are
+// regenerated by tools rather than edited.
+var skipWatchDirs = map[string]bool{
+	".git":         true,
+	"node_modules": true,
+	"target":       true,
+	".next":        true,
+	"dist":         true,
+	"build":        true,
+	"vendor":       true,
+	"__pycache__":  true,
+	".venv":        true,
+}
+
+type Options struct {
+	Root   string
+	Backup project.BackupOptions
+}
+
+type projectState struct {
+	schedule *Schedule
+	status   ProjectStatus
+	debounce *time.Timer
+	// settling is set while changes arrive and the debounce timer runs.
+	settling bool
+	// due is set when the schedule fired while the project was settling.
+	due bool
+}
+
+type backupDone struct {
+	name   string
+	start  time.Time
+	result *project.BackupResult
+	err    error
+}
+
+type agent struct {
+	ctx      context.Context
+	opts     Options
+	cfg      *config.Config
+	debounce time.Duration
+	watcher  *fsnotify.Watcher
+	projects map[string]*projectState
+	settled  chan string
+	done     chan backupDone
+	queue    []string
+	running  string
+	status   Status
+}
+
+// Run watches the projects under opts.Root and backs up changed projects on
+// their schedule until ctx is canceled. Only one backup runs at a time.
+func Run(ctx context.Context, opts Options) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	debounce := defaultDebounce
+	if cfg.Backup.Debounce != "" {
+		if debounce, err = time.ParseDuration(cfg.Backup.Debounce); err != nil {
+			return fmt.Errorf("invalid backup debounce: %v", err)
+		}
+	}
+
+	root, err := filepath.Abs(opts.Root)
+	if err != nil {
+		return err
+	}
+	opts.Root = root
+	opts.Backup.Quiet = true
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to start file watcher: %v", err)
+	}
+	defer watcher.Close()
+	if err := watcher.Add(root); err != nil {
+		return fmt.Errorf("failed to watch %s: %v", root, err)
+	}
+
+	a := &agent{
+		ctx:      ctx,
+		opts:     opts,
+		cfg:      cfg,
+		debounce: debounce,
+		watcher:  watcher,
+		projects: make(map[string]*projectState),
+		settled:  make(chan string),
+		done:     make(chan backupDone),
+		status: Status{
+			PID:      os.Getpid(),
+			Root:     root,
+			Started:  time.Now(),
+			Projects: make(map[string]ProjectStatus),
+		},
+	}
+
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
+			a.addProject(entry.Name())
+		}
+	}
+	log.Printf("Watching %d projects in %s", len(a.projects), root)
+	a.saveStatus()
+
+	tick := time.NewTicker(15 * time.Second)
+	defer tick.Stop()
+	statusTick := time.NewTicker(statusInterval)
+	defer statusTick.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if a.running != "" {
+				log.Printf("Waiting for the backup of %s to finish...", a.running)
+				a.finishBackup(<-a.done)
+			}
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return errors.New("file watcher stopped")
+			}
+			a.handleEvent(event)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return errors.New("file watcher stopped")
+			}
+			log.Printf("Watcher error: %v", err)
+		case name := <-a.settled:
+			a.markDirty(name)
+		case now := <-tick.C:
+			a.runDue(now)
+		case done := <-a.done:
+			a.finishBackup(done)
+		case <-statusTick.C:
+			a.saveStatus()
+		}
+	}
+}
+
+func (a *agent) addProject(name string) {
+	path := filepath.Join(a.opts.Root, name)
+	expr := a.cfg.ScheduleFor(name)
+	schedule, err := ParseSchedule(expr)
+	if err != nil {
+		log.Printf("%s: invalid schedule, using @hourly: %v", name, err)
+		expr = "@hourly"
+		schedule, _ = ParseSchedule(expr)
+	}
+
+	p := &projectState{schedule: schedule, status: projectStatus(path, expr)}
+	p.status.NextRun = schedule.Next(time.Now())
+
+	if err := a.watchTree(path); err != nil {
+		// Typically the inotify watch limit; fall back to checking the
+		// modification times when the schedule fires
+		log.Printf("%s: not watching for changes, polling instead: %v", name, err)
+	} else {
+		p.status.Watched = true
+	}
+
+	if changed, lastModified, err := project.NeedsBackup(path); err != nil {
+		p.status.LastError = err.Error()
+	} else if changed {
+		p.status.Dirty = true
+		p.status.LastChange = lastModified
+	}
+	a.projects[name] = p
+}
+
+func (a *agent) removeProject(name string) {
+	p, ok := a.projects[name]
+	if !ok {
+		return
+	}
+	if p.debounce != nil {
+		p.debounce.Stop()
+	}
+	delete(a.projects, name)
+	delete(a.status.Projects, name)
+	log.Printf("%s: project removed", name)
+}
+
+func (a *agent) watchTree(dir string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != dir && skipWatchDirs[d.Name()] {
+			return filepath.SkipDir
+		}
+		return a.watcher.Add(path)
+	})
+}
+
+func (a *agent) handleEvent(event fsnotify.Event) {
+	rel, err := filepath.Rel(a.opts.Root, event.Name)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return
+	}
+	parts := strings.Split(rel, string(filepath.Separator))
+	name := parts[0]
+	if strings.HasPrefix(name, ".") {
+		return
+	}
+
+	// Events directly in the root add and remove projects. Files there,
+	// like backups written next to the projects, are ignored.
+	if len(parts) == 1 {
+		switch {
+		case event.Has(fsnotify.Create):
+			if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+				log.Printf("%s: new project", name)
+				a.addProject(name)
+			}
+		case event.Has(fsnotify.Remove), event.Has(fsnotify.Rename):
+			a.removeProject(name)
+		}
+		return
+	}
+
+	p, ok := a.projects[name]
+	if !ok {
+		return
+	}
+	for _, part := range parts[1:] {
+		if skipWatchDirs[part] {
+			return
+		}
+	}
+
+	if event.Has(fsnotify.Create) && p.status.Watched {
+		if info, err := os.Lstat(event.Name); err == nil && info.IsDir() {
+			if err := a.watchTree(event.Name); err != nil {
+				log.Printf("%s: not watching for changes, polling instead: %v", name, err)
+				p.status.Watched = false
+			}
+		}
+	}
+
+	// Restart the debounce timer on every change
+	p.settling = true
+	if p.debounce == nil {
+		p.debounce = time.AfterFunc(a.debounce, func() {
+			select {
+			case a.settled <- name:
+			case <-a.ctx.Done():
+			}
+		})
+	} else {
+		p.debounce.Reset(a.debounce)
+	}
+}
+
+func (a *agent) markDirty(name string) {
+	p, ok := a.projects[name]
+	if !ok {
+		return
+	}
+	p.settling = false
+	p.status.Dirty = true
+	p.status.LastChange = time.Now()
+	if p.due {
+		p.due = false
+		a.enqueue(name)
+	}
+}
+
+func (a *agent) runDue(now time.Time) {
+	for name, p := range a.projects {
+		if now.Before(p.status.NextRun) {
+			continue
+		}
+		p.status.NextRun = p.schedule.Next(now)
+
+		if !p.status.Watched {
+			changed, lastModified, err := project.NeedsBackup(p.status.Path)
+			if err != nil {
+				p.status.LastError = err.Error()
+				continue
+			}
+			if changed {
+				p.status.Dirty = true
+				p.status.LastChange = lastModified
+			}
+		}
+
+		switch {
+		case p.settling:
+			// Wait until the changes settle before taking the snapshot
+			p.due = true
+		case p.status.Dirty:
+			a.enqueue(name)
+		}
+	}
+	a.saveStatus()
+}
+
+func (a *agent) enqueue(name string) {
+	if a.running == name {
+		return
+	}
+	for _, queued := range a.queue {
+		if queued == name {
+			return
+		}
+	}
+	a.queue = append(a.queue, name)
+	a.startNext()
+}
+
+func (a *agent) startNext() {
+	if a.running != "" || len(a.queue) == 0 || a.ctx.Err() != nil {
+		return
+	}
+	name := a.queue[0]
+	a.queue = a.queue[1:]
+	p, ok := a.projects[name]
+	if !ok {
+		a.startNext()
+		return
+	}
+
+	a.running = name
+	log.Printf("%s: backing up", name)
+	go func(path string) {
+		start := time.Now()
+		result, err := project.CreateBackup(path, a.opts.Root, a.opts.Backup)
+		a.done <- backupDone{name: name, start: start, result: result, err: err}
+	}(p.status.Path)
+}
+
+func (a *agent) finishBackup(done backupDone) {
+	a.running = ""
+	if p, ok := a.projects[done.name]; ok {
+		if done.err != nil {
+			log.Printf("%s: backup failed: %v", done.name, done.err)
+			p.status.LastError = done.err.Error()
+		} else {
+			log.Printf("%s: backed up to %s", done.name, done.result.Location)
+			if done.result.Secrets > 0 {
+				log.Printf("%s: %d files with secrets were left out or redacted", done.name, done.result.Secrets)
+			}
+			p.status.LastError = ""
+			p.status.LastBackup = done.start
+			p.status.Location = done.result.Location
+			// Changes made while the backup ran need another one
+			p.status.Dirty = p.settling || p.status.LastChange.After(done.start)
+		}
+	}
+	a.saveStatus()
+	a.startNext()
+}
+
+func (a *agent) saveStatus() {
+	a.status.Updated = time.Now()
+	for name, p := range a.projects {
+		a.status.Projects[name] = p.status
+	}
+	if err := writeStatus(&a.status); err != nil {
+		log.Printf("Failed to write status: %v", err)
+	}
+}
@@ -0,0 +1,164 @@
+package agent
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/fatih/color"
+
+	"project-starter/internal/config"
+	"project-starter/internal/project"
+)
+
+// statusInterval is how often a running agent refreshes its status file. A
+// status older than a few intervals means the agent is no longer running.
+const statusInterval = time.Minute
+
+// Status is written by the agent to agent-status.json.
+type Status struct {
+	PID      int                      `json:"pid"`
+	Root     string                   `json:"root"`
+	Started  time.Time                `json:"started"`
+	Updated  time.Time                `json:"updated"`
+	Projects map[string]ProjectStatus `json:"projects"`
+}
+
+type ProjectStatus struct {
+	Path       string    `json:"path"`
+	Schedule   string    `json:"schedule"`
+	NextRun    time.Time `json:"next_run"`
+	Dirty      bool      `json:"dirty"`
+	LastChange time.Time `json:"last_change,omitempty"`
+	Watched    bool      `json:"watched"`
+	LastBackup time.Time `json:"last_backup,omitempty"`
+	Location   string    `json:"location,omitempty"`
+	LastError  string    `json:"last_error,omitempty"`
+}
+
+func statusPath() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "agent-status.json"), nil
+}
+
+func writeStatus(status *Status) error {
+	path, err := statusPath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(status, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+	// Write to a temporary file first so the CLI never reads a partial
+	// status
+	tmp, err := os.CreateTemp(filepath.Dir(path), "agent-status-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// ReadStatus returns the status last written by the agent, or nil if it has
+// never run.
+func ReadStatus() (*Status, error) {
+	path, err := statusPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var status Status
+	if err := json.Unmarshal(data, &status); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", path, err)
+	}
+	return &status, nil
+}
+
+// PrintStatus shows whether the agent is running and the backup state of
+// every project it watches.
+func PrintStatus() error {
+	status, err := ReadStatus()
+	if err != nil {
+		return err
+	}
+	if status == nil {
+		color.Yellow("The backup agent has never run. Start it with 'project-starter agent'.")
+		return nil
+	}
+
+	if time.Since(status.Updated) > 3*statusInterval {
+		color.Yellow("Agent is not running (last seen %s)", humanize.Time(status.Updated))
+	} else {
+		color.Green("Agent is running (pid %d, since %s)", status.PID, humanize.Time(status.Started))
+	}
+	color.Cyan("Watching %s\n", status.Root)
+
+	names := make([]string, 0, len(status.Projects))
+	for name := range status.Projects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROJECT\tSCHEDULE\tCHANGED\tLAST BACKUP\tNEXT RUN\tERROR")
+	for _, name := range names {
+		p := status.Projects[name]
+		changed := "no"
+		if p.Dirty {
+			changed = "yes"
+		}
+		if !p.Watched {
+			changed += " (polled)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", name, p.Schedule, changed,
+			timeOrNever(p.LastBackup), p.NextRun.Local().Format("2006-01-02 15:04"), p.LastError)
+	}
+	return w.Flush()
+}
+
+func timeOrNever(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	return humanize.Time(t)
+}
+
+// projectStatus seeds a project's status from the recorded backup state.
+func projectStatus(path, schedule string) ProjectStatus {
+	p := ProjectStatus{Path: path, Schedule: schedule}
+	if record, ok, err := project.LastBackup(path); err == nil && ok {
+		p.LastBackup = record.Time
+		p.Location = record.Location
+	}
+	return p
+}
@@ -0,0 +1,59 @@
+package agent
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+const unitName = "project-starter-agent.service"
+
+// Unit returns a systemd user unit that runs the agent for root.
+func Unit(root string) (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	if exe, err = filepath.EvalSymlinks(exe); err != nil {
+		return "", err
+	}
+	if root, err = filepath.Abs(root); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf(`[Unit]
+Description=project-starter backup agent
+After=network-online.target
+
+[Service]
+Type=simple
+ExecStart=%s agent --root %s
+Restart=on-failure
+RestartSec=30
+
+[Install]
+WantedBy=default.target
+`, strconv.Quote(exe), strconv.Quote(root)), nil
+}
+
+// InstallUnit writes the systemd user unit and returns its path.
+func InstallUnit(root string) (string, error) {
+	unit, err := Unit(root)
+	if err != nil {
+		return "", err
+	}
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(configDir, "systemd", "user")
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return "", fmt.Errorf("failed to create %s: %v", dir, err)
+	}
+	path := filepath.Join(dir, unitName)
+	if err := os.WriteFile(path, []byte(unit), 0644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %v", path, err)
+	}
+	return path, nil
+}
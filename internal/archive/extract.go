@@ -0,0 +1,217 @@
+package archive
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ReadEntries lists the entries of the archive at archivePath without their
+// content. The manifest is left out.
+func ReadEntries(archivePath string) ([]Entry, error) {
+	reader, err := OpenReader(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	var entries []Entry
+	err = reader.Walk(func(entry Entry, _ io.Reader) error {
+		if entry.Name != ManifestName {
+			entries = append(entries, entry)
+		}
+		return nil
+	})
+	return entries, err
+}
+
+// Cat copies the content of the file name in the archive to w.
+func Cat(archivePath, name string, w io.Writer) error {
+	reader, err := OpenReader(archivePath)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	name = strings.Trim(path.Clean("/"+filepath.ToSlash(name)), "/")
+	found := false
+	err = reader.Walk(func(entry Entry, r io.Reader) error {
+		if found || entry.Name != name {
+			return nil
+		}
+		found = true
+		if r == nil {
+			return fmt.Errorf("%s is not a regular file", name)
+		}
+		_, err := io.Copy(w, r)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("%s not found in archive", name)
+	}
+	return nil
+}
+
+// Extract restores the entries listed in names, and everything below those
+// that are directories, into dest. Each entry is written at its archive path
+// relative to base, so extracting "src/app/main.go" with base "src" creates
+// dest/app/main.go. It returns the paths written.
+func Extract(archivePath string, names []string, base, dest string) ([]string, error) {
+	var written []string
+	err := walkSelected(archivePath, names, base, func(entry Entry, r io.Reader, rel string) error {
+		target, err := safeJoin(dest, rel)
+		if err != nil {
+			return err
+		}
+		if err := checkParents(dest, rel); err != nil {
+			return err
+		}
+
+		if err := extractEntry(entry, r, dest, target); err != nil {
+			return fmt.Errorf("%s: %v", entry.Name, err)
+		}
+		written = append(written, target)
+		return nil
+	})
+	return written, err
+}
+
+// Conflicts returns the paths relative to dest that Extract with the same
+// arguments would overwrite. Directories that already exist are merged
+// into and aren't listed.
+func Conflicts(archivePath string, names []string, base, dest string) ([]string, error) {
+	var existing []string
+	err := walkSelected(archivePath, names, base, func(entry Entry, _ io.Reader, rel string) error {
+		target, err := safeJoin(dest, rel)
+		if err != nil {
+			return err
+		}
+		info, err := os.Lstat(target)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() || !info.IsDir() {
+			existing = append(existing, rel)
+		}
+		return nil
+	})
+	return existing, err
+}
+
+// walkSelected calls fn for the entries listed in names and everything
+// below them, with their path relative to base.
+func walkSelected(archivePath string, names []string, base string, fn func(entry Entry, r io.Reader, rel string) error) error {
+	reader, err := OpenReader(archivePath)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	base = strings.Trim(base, "/")
+	selected := func(name string) bool {
+		for _, n := range names {
+			if name == n || strings.HasPrefix(name, n+"/") {
+				return true
+			}
+		}
+		return false
+	}
+
+	return reader.Walk(func(entry Entry, r io.Reader) error {
+		if entry.Name == ManifestName || !selected(entry.Name) {
+			return nil
+		}
+		rel := entry.Name
+		if base != "" {
+			rel = strings.TrimPrefix(strings.TrimPrefix(rel, base), "/")
+		}
+		return fn(entry, r, rel)
+	})
+}
+
+// safeJoin joins name to dir and rejects names that would escape dir.
+func safeJoin(dir, name string) (string, error) {
+	target := filepath.Join(dir, filepath.FromSlash(name))
+	rel, err := filepath.Rel(dir, target)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("entry %s points outside the destination", name)
+	}
+	return target, nil
+}
+
+// checkParents rejects names below dir whose parent directories are
+// symlinks, such as one extracted earlier from the same archive, as
+// writing through them could leave dir.
+func checkParents(dir, name string) error {
+	parts := strings.Split(path.Clean(name), "/")
+	current := dir
+	for _, part := range parts[:len(parts)-1] {
+		current = filepath.Join(current, part)
+		info, err := os.Lstat(current)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return fmt.Errorf("entry %s is below the symlink %s", name, current)
+		}
+	}
+	return nil
+}
+
+func extractEntry(entry Entry, r io.Reader, dest, target string) error {
+	// An existing symlink at the target is replaced rather than followed
+	if info, err := os.Lstat(target); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		if err := os.Remove(target); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case entry.IsDir():
+		return os.MkdirAll(target, entry.Mode.Perm()|0700)
+	case entry.Link != "":
+		if filepath.IsAbs(entry.Link) || strings.HasPrefix(entry.Link, "/") {
+			return fmt.Errorf("symlink to the absolute path %s", entry.Link)
+		}
+		linked := filepath.Join(filepath.Dir(target), filepath.FromSlash(entry.Link))
+		if rel, err := filepath.Rel(dest, linked); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("symlink to %s points outside the destination", entry.Link)
+		}
+		if err := os.MkdirAll(filepath.Dir(target), os.ModePerm); err != nil {
+			return err
+		}
+		os.Remove(target)
+		return os.Symlink(entry.Link, target)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), os.ModePerm); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, entry.Mode.Perm())
+	if err != nil {
+		return err
+	}
+	if r != nil {
+		if _, err := io.Copy(file, r); err != nil {
+			file.Close()
+			return err
+		}
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Chtimes(target, entry.ModTime, entry.ModTime)
+}
@@ -0,0 +1,70 @@
+package archive
+
+import (
+	"compress/flate"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+type Format string
+
+const (
+	FormatZip    Format = "zip"
+	FormatTarGz  Format = "tar.gz"
+	FormatTarZst Format = "tar.zst"
+	FormatTar    Format = "tar"
+)
+
+// DefaultLevel selects the default compression level of the chosen format.
+const DefaultLevel = -1
+
+var Formats = []Format{FormatZip, FormatTarGz, FormatTarZst, FormatTar}
+
+func ParseFormat(s string) (Format, error) {
+	for _, f := range Formats {
+		if strings.EqualFold(s, string(f)) {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported archive format %q (expected zip, tar.gz, tar.zst or tar)", s)
+}
+
+// FormatFromPath detects the archive format from a file name.
+func FormatFromPath(path string) (Format, error) {
+	name := strings.ToLower(filepath.Base(path))
+	switch {
+	case strings.HasSuffix(name, ".zip"):
+		return FormatZip, nil
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		return FormatTarGz, nil
+	case strings.HasSuffix(name, ".tar.zst"):
+		return FormatTarZst, nil
+	case strings.HasSuffix(name, ".tar"):
+		return FormatTar, nil
+	}
+	return "", fmt.Errorf("cannot detect archive format of %s", path)
+}
+
+func (f Format) Extension() string {
+	return "." + string(f)
+}
+
+func (f Format) ValidateLevel(level int) error {
+	if level == DefaultLevel {
+		return nil
+	}
+	switch f {
+	case FormatZip, FormatTarGz:
+		if level < flate.NoCompression || level > flate.BestCompression {
+			return fmt.Errorf("compression level for %s must be between 0 and 9", f)
+		}
+	case FormatTarZst:
+		if level < 1 || level > 22 {
+			return fmt.Errorf("compression level for %s must be between 1 and 22", f)
+		}
+	case FormatTar:
+		return fmt.Errorf("%s archives are not compressed, --level is not supported", f)
+	}
+	return nil
+}
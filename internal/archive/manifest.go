@@ -0,0 +1,67 @@
+package archive
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"time"
+)
+
+// ManifestName is the archive entry holding the manifest. It is written after
+// all project files.
+const ManifestName = ".project-starter-manifest.json"
+
+const manifestVersion = 1
+
+type Manifest struct {
+	Version int             `json:"version"`
+	Created time.Time       `json:"created"`
+	Files   []ManifestEntry `json:"files"`
+}
+
+type ManifestEntry struct {
+	Path    string      `json:"path"`
+	Size    int64       `json:"size"`
+	Mode    fs.FileMode `json:"mode"`
+	ModTime time.Time   `json:"mtime"`
+	SHA256  string      `json:"sha256,omitempty"`
+	Link    string      `json:"link,omitempty"`
+}
+
+// manifestRecorder collects manifest entries while files are archived.
+type manifestRecorder struct {
+	manifest Manifest
+}
+
+func (m *manifestRecorder) record(p *Prepared) {
+	entry := ManifestEntry{
+		Path:    p.Name,
+		Mode:    p.Info.Mode(),
+		ModTime: p.Info.ModTime().UTC(),
+		SHA256:  p.sum,
+		Link:    p.link,
+	}
+	if p.Info.Mode().IsRegular() {
+		entry.Size = p.Size
+	}
+	m.manifest.Files = append(m.manifest.Files, entry)
+}
+
+func (m *manifestRecorder) encode() ([]byte, error) {
+	m.manifest.Version = manifestVersion
+	m.manifest.Created = time.Now().UTC()
+	return json.MarshalIndent(m.manifest, "", "  ")
+}
+
+// HashReader returns the hex encoded SHA-256 of everything read from r and
+// the number of bytes read.
+func HashReader(r io.Reader) (string, int64, error) {
+	h := sha256.New()
+	n, err := io.Copy(h, r)
+	if err != nil {
+		return "", n, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), n, nil
+}
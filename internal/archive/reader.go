@@ -0,0 +1,191 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Entry describes a single archive member.
+type Entry struct {
+	Name    string
+	Size    int64
+	Mode    fs.FileMode
+	ModTime time.Time
+	Link    string
+}
+
+func (e Entry) IsDir() bool {
+	return e.Mode.IsDir()
+}
+
+// Reader reads back archives produced by Writer.
+type Reader interface {
+	// Walk calls fn for every entry in archive order. For regular files r
+	// yields the content; it is nil for directories and symlinks.
+	Walk(fn func(entry Entry, r io.Reader) error) error
+	Close() error
+}
+
+func OpenReader(path string) (Reader, error) {
+	format, err := FormatFromPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if format == FormatZip {
+		zr, err := zip.OpenReader(path)
+		if err != nil {
+			return nil, err
+		}
+		return &zipReader{zr: zr}, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var stream io.Reader = file
+	var decompressor io.Closer
+	switch format {
+	case FormatTarGz:
+		gr, err := gzip.NewReader(file)
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		stream, decompressor = gr, gr
+	case FormatTarZst:
+		zr, err := zstd.NewReader(file)
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		stream, decompressor = zr, zr.IOReadCloser()
+	}
+	return &tarReader{file: file, stream: stream, decompressor: decompressor}, nil
+}
+
+// cleanName normalizes entry names from other tools, which may start with
+// "./" or end directories with a slash.
+func cleanName(name string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(name, "./"), "/")
+}
+
+func decodeManifest(data []byte) (*Manifest, error) {
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %v", err)
+	}
+	if manifest.Version > manifestVersion {
+		return nil, fmt.Errorf("manifest version %d is newer than this tool supports", manifest.Version)
+	}
+	return &manifest, nil
+}
+
+type zipReader struct {
+	zr *zip.ReadCloser
+}
+
+func (z *zipReader) Walk(fn func(entry Entry, r io.Reader) error) error {
+	for _, file := range z.zr.File {
+		entry := Entry{
+			Name:    cleanName(file.Name),
+			Size:    int64(file.UncompressedSize64),
+			Mode:    file.Mode(),
+			ModTime: file.Modified,
+		}
+
+		if entry.IsDir() {
+			entry.Size = 0
+			if err := fn(entry, nil); err != nil {
+				return err
+			}
+			continue
+		}
+
+		rc, err := file.Open()
+		if err != nil {
+			return fmt.Errorf("%s: %v", file.Name, err)
+		}
+
+		if entry.Mode&fs.ModeSymlink != 0 {
+			target, err := io.ReadAll(rc)
+			rc.Close()
+			if err != nil {
+				return fmt.Errorf("%s: %v", file.Name, err)
+			}
+			entry.Link = string(target)
+			entry.Size = 0
+			if err := fn(entry, nil); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// The CRC-32 is checked when fn reads the entry to EOF
+		err = fn(entry, rc)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (z *zipReader) Close() error {
+	return z.zr.Close()
+}
+
+type tarReader struct {
+	file         *os.File
+	stream       io.Reader
+	decompressor io.Closer
+}
+
+func (t *tarReader) Walk(fn func(entry Entry, r io.Reader) error) error {
+	tr := tar.NewReader(t.stream)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		info := header.FileInfo()
+		entry := Entry{
+			Name:    cleanName(header.Name),
+			Mode:    info.Mode(),
+			ModTime: header.ModTime,
+			Link:    header.Linkname,
+		}
+
+		var r io.Reader
+		if info.Mode().IsRegular() {
+			entry.Size = header.Size
+			r = tr
+		}
+		if err := fn(entry, r); err != nil {
+			return err
+		}
+	}
+}
+
+func (t *tarReader) Close() error {
+	if t.decompressor != nil {
+		t.decompressor.Close()
+	}
+	return t.file.Close()
+}
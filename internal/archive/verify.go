@@ -0,0 +1,232 @@
+package archive
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"project-starter/internal/ignore"
+)
+
+type ProblemKind string
+
+const (
+	// ProblemCorrupt marks an archived file whose content does not match the manifest.
+	ProblemCorrupt ProblemKind = "corrupt"
+	// ProblemMissing marks a file that should be in the archive but is not.
+	ProblemMissing ProblemKind = "missing"
+	// ProblemChanged marks a file that differs between archive and project.
+	ProblemChanged ProblemKind = "changed"
+	// ProblemExtra marks a file that is in the archive but not where it is compared to.
+	ProblemExtra ProblemKind = "extra"
+)
+
+type Problem struct {
+	Path   string
+	Kind   ProblemKind
+	Detail string
+}
+
+type VerifyReport struct {
+	Manifest *Manifest
+	// Checked is the number of manifest entries found intact in the archive.
+	Checked  int
+	Problems []Problem
+}
+
+type archivedFile struct {
+	entry Entry
+	sum   string
+	size  int64
+	err   error
+}
+
+// Verify reads every entry of the archive at path and checks it against the
+// archive's manifest.
+func Verify(path string) (*VerifyReport, error) {
+	reader, err := OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	var manifestData []byte
+	archived := make(map[string]archivedFile)
+	err = reader.Walk(func(entry Entry, r io.Reader) error {
+		if entry.Name == ManifestName {
+			data, err := io.ReadAll(r)
+			manifestData = data
+			return err
+		}
+
+		file := archivedFile{entry: entry}
+		if r != nil {
+			file.sum, file.size, file.err = HashReader(r)
+		}
+		archived[entry.Name] = file
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("archive is damaged: %v", err)
+	}
+	if manifestData == nil {
+		return nil, fmt.Errorf("archive has no manifest")
+	}
+
+	manifest, err := decodeManifest(manifestData)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &VerifyReport{Manifest: manifest}
+	for _, expected := range manifest.Files {
+		file, ok := archived[expected.Path]
+		if !ok {
+			report.add(expected.Path, ProblemMissing, "listed in manifest but not in archive")
+			continue
+		}
+		delete(archived, expected.Path)
+
+		switch {
+		case file.err != nil:
+			report.add(expected.Path, ProblemCorrupt, file.err.Error())
+		case file.entry.Mode.Type() != expected.Mode.Type():
+			report.add(expected.Path, ProblemCorrupt, fmt.Sprintf("type %s, manifest says %s", typeName(file.entry.Mode), typeName(expected.Mode)))
+		case file.size != expected.Size:
+			report.add(expected.Path, ProblemCorrupt, fmt.Sprintf("size %d, manifest says %d", file.size, expected.Size))
+		case file.sum != expected.SHA256:
+			report.add(expected.Path, ProblemCorrupt, "SHA-256 does not match manifest")
+		case file.entry.Link != expected.Link:
+			report.add(expected.Path, ProblemCorrupt, fmt.Sprintf("link target %q, manifest says %q", file.entry.Link, expected.Link))
+		default:
+			report.Checked++
+		}
+	}
+	for name := range archived {
+		report.add(name, ProblemExtra, "in archive but not listed in manifest")
+	}
+
+	report.sort()
+	return report, nil
+}
+
+// CompareWithDirectory reports how the live project in dir differs from the
+// backup described by manifest. Missing files exist in the project but not in
+// the backup, extra files only exist in the backup. Paths matching ignored
+// were left out of the backup on purpose and are not compared.
+func CompareWithDirectory(manifest *Manifest, dir string, ignored *ignore.Matcher) ([]Problem, error) {
+	expected := make(map[string]ManifestEntry, len(manifest.Files))
+	for _, entry := range manifest.Files {
+		expected[entry.Path] = entry
+	}
+
+	report := &VerifyReport{}
+	err := filepath.Walk(dir, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(dir, filePath)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(relPath)
+		if name == "." || name == ManifestName || !isArchivable(info) {
+			return nil
+		}
+		if ignored.Match(name, info.IsDir()) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		entry, ok := expected[name]
+		if !ok {
+			report.add(name, ProblemMissing, "in project but not in backup")
+			return nil
+		}
+		delete(expected, name)
+
+		detail, err := compareLive(filePath, info, entry)
+		if err != nil {
+			return err
+		}
+		if detail != "" {
+			report.add(name, ProblemChanged, detail)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for name := range expected {
+		report.add(name, ProblemExtra, "in backup but no longer in project")
+	}
+
+	report.sort()
+	return report.Problems, nil
+}
+
+func compareLive(filePath string, info fs.FileInfo, entry ManifestEntry) (string, error) {
+	if info.Mode().Type() != entry.Mode.Type() {
+		return fmt.Sprintf("now a %s, backup has a %s", typeName(info.Mode()), typeName(entry.Mode)), nil
+	}
+
+	switch {
+	case info.Mode()&fs.ModeSymlink != 0:
+		target, err := os.Readlink(filePath)
+		if err != nil {
+			return "", err
+		}
+		if target != entry.Link {
+			return fmt.Sprintf("link target now %q, backup has %q", target, entry.Link), nil
+		}
+	case info.Mode().IsRegular():
+		if info.Size() != entry.Size {
+			return fmt.Sprintf("size now %d, backup has %d", info.Size(), entry.Size), nil
+		}
+		file, err := os.Open(filePath)
+		if err != nil {
+			return "", err
+		}
+		sum, _, err := HashReader(file)
+		file.Close()
+		if err != nil {
+			return "", err
+		}
+		if sum != entry.SHA256 {
+			return "content differs", nil
+		}
+	}
+
+	if info.Mode().Perm() != entry.Mode.Perm() {
+		return fmt.Sprintf("mode now %s, backup has %s", info.Mode().Perm(), entry.Mode.Perm()), nil
+	}
+	return "", nil
+}
+
+func (r *VerifyReport) add(path string, kind ProblemKind, detail string) {
+	r.Problems = append(r.Problems, Problem{Path: path, Kind: kind, Detail: detail})
+}
+
+func (r *VerifyReport) sort() {
+	sort.Slice(r.Problems, func(i, j int) bool {
+		return r.Problems[i].Path < r.Problems[j].Path
+	})
+}
+
+func typeName(mode fs.FileMode) string {
+	switch {
+	case mode.IsDir():
+		return "directory"
+	case mode&fs.ModeSymlink != 0:
+		return "symlink"
+	default:
+		return "file"
+	}
+}
@@ -0,0 +1,402 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// maxBufferedSize is the largest file Prepare reads into memory. Bigger files
+// are streamed from disk by Write.
+const maxBufferedSize = 4 << 20
+
+// Writer adds files from disk to an archive. Prepare does the expensive work
+// (reading, hashing and, for zip, compressing) and is safe for concurrent use,
+// so callers can prepare entries in parallel and Write them in order. Close
+// appends the manifest of everything written.
+type Writer interface {
+	// Prepare reads the file at path for the archive entry name. It returns
+	// nil for file types that are not archived (sockets, devices, pipes).
+	Prepare(path, name string, info fs.FileInfo) (*Prepared, error)
+	// PrepareData is Prepare for a regular file whose archived content is
+	// data rather than what is on disk, such as a file with secrets redacted.
+	PrepareData(name string, info fs.FileInfo, data []byte) (*Prepared, error)
+	Write(p *Prepared) error
+	Close() error
+}
+
+// Prepared is a file ready to be appended to an archive.
+type Prepared struct {
+	Name string
+	Info fs.FileInfo
+	// Size is the number of content bytes, which may differ from Info.Size()
+	// if the file changed since it was listed.
+	Size int64
+
+	path     string
+	link     string
+	sum      string
+	data     []byte
+	crc      uint32
+	method   uint16
+	streamed bool
+}
+
+// storedExtensions lists formats that are already compressed, so deflating
+// them again in zip archives only costs time.
+var storedExtensions = map[string]bool{
+	".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".webp": true, ".avif": true,
+	".zip": true, ".gz": true, ".tgz": true, ".zst": true, ".bz2": true, ".xz": true, ".7z": true, ".rar": true,
+	".woff": true, ".woff2": true,
+	".mp3": true, ".mp4": true, ".m4a": true, ".mov": true, ".mkv": true, ".webm": true, ".ogg": true,
+	".jar": true, ".docx": true, ".xlsx": true, ".pptx": true,
+}
+
+func NewWriter(w io.Writer, format Format, level int) (Writer, error) {
+	if err := format.ValidateLevel(level); err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case FormatZip:
+		zw := zip.NewWriter(w)
+		zw.RegisterCompressor(zip.Deflate, func(out io.Writer) (io.WriteCloser, error) {
+			return flate.NewWriter(out, level)
+		})
+		return &zipWriter{zw: zw, level: level}, nil
+	case FormatTarGz:
+		gw, err := gzip.NewWriterLevel(w, level)
+		if err != nil {
+			return nil, err
+		}
+		return &tarWriter{tw: tar.NewWriter(gw), compressor: gw}, nil
+	case FormatTarZst:
+		encoderLevel := zstd.SpeedDefault
+		if level != DefaultLevel {
+			encoderLevel = zstd.EncoderLevelFromZstd(level)
+		}
+		zw, err := zstd.NewWriter(w, zstd.WithEncoderLevel(encoderLevel))
+		if err != nil {
+			return nil, err
+		}
+		return &tarWriter{tw: tar.NewWriter(zw), compressor: zw}, nil
+	case FormatTar:
+		return &tarWriter{tw: tar.NewWriter(w)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported archive format %q", format)
+	}
+}
+
+func isArchivable(info fs.FileInfo) bool {
+	return info.IsDir() || info.Mode().IsRegular() || info.Mode()&fs.ModeSymlink != 0
+}
+
+// prepare fills in everything but the compressed data, which is format specific.
+func prepare(filePath, name string, info fs.FileInfo) (*Prepared, error) {
+	if !isArchivable(info) {
+		return nil, nil
+	}
+
+	p := &Prepared{Name: filepath.ToSlash(name), Info: info, path: filePath}
+	switch {
+	case info.IsDir():
+	case info.Mode()&fs.ModeSymlink != 0:
+		target, err := os.Readlink(filePath)
+		if err != nil {
+			return nil, err
+		}
+		p.link = target
+	case info.Size() > maxBufferedSize:
+		p.streamed = true
+		p.Size = info.Size()
+	default:
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			return nil, err
+		}
+		p.setData(data)
+	}
+	return p, nil
+}
+
+func prepareData(name string, info fs.FileInfo, data []byte) (*Prepared, error) {
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("%s: content can only be replaced for regular files", name)
+	}
+	p := &Prepared{Name: filepath.ToSlash(name), Info: info}
+	p.setData(data)
+	return p, nil
+}
+
+func (p *Prepared) setData(data []byte) {
+	p.sum, _, _ = HashReader(bytes.NewReader(data))
+	p.data = data
+	p.Size = int64(len(data))
+}
+
+type zipWriter struct {
+	zw       *zip.Writer
+	level    int
+	compress sync.Pool
+	buffers  sync.Pool
+	manifestRecorder
+}
+
+func (z *zipWriter) Prepare(filePath, name string, info fs.FileInfo) (*Prepared, error) {
+	p, err := prepare(filePath, name, info)
+	if err != nil || p == nil {
+		return p, err
+	}
+	return z.finish(p)
+}
+
+func (z *zipWriter) PrepareData(name string, info fs.FileInfo, data []byte) (*Prepared, error) {
+	p, err := prepareData(name, info, data)
+	if err != nil {
+		return nil, err
+	}
+	return z.finish(p)
+}
+
+// finish picks the zip method for p and deflates buffered content.
+func (z *zipWriter) finish(p *Prepared) (*Prepared, error) {
+	switch {
+	case p.Info.IsDir():
+		p.method = zip.Store
+	case p.link != "":
+		// Zip stores symlinks as entries whose content is the link target
+		p.method = zip.Store
+		p.data = []byte(p.link)
+		p.Size = int64(len(p.data))
+		p.crc = crc32.ChecksumIEEE(p.data)
+	case storedExtensions[strings.ToLower(path.Ext(p.Name))]:
+		p.method = zip.Store
+		p.crc = crc32.ChecksumIEEE(p.data)
+	default:
+		p.method = zip.Deflate
+		if p.streamed {
+			return p, nil
+		}
+		p.crc = crc32.ChecksumIEEE(p.data)
+		compressed, err := z.deflate(p.data)
+		if err != nil {
+			return nil, err
+		}
+		// Keep incompressible data as it is
+		if len(compressed) < len(p.data) {
+			p.data = compressed
+		} else {
+			p.method = zip.Store
+		}
+	}
+	return p, nil
+}
+
+func (z *zipWriter) deflate(data []byte) ([]byte, error) {
+	buf, _ := z.buffers.Get().(*bytes.Buffer)
+	if buf == nil {
+		buf = new(bytes.Buffer)
+	}
+	defer z.buffers.Put(buf)
+	buf.Reset()
+
+	fw, _ := z.compress.Get().(*flate.Writer)
+	if fw == nil {
+		var err error
+		if fw, err = flate.NewWriter(buf, z.level); err != nil {
+			return nil, err
+		}
+	} else {
+		fw.Reset(buf)
+	}
+	defer z.compress.Put(fw)
+
+	if _, err := fw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := fw.Close(); err != nil {
+		return nil, err
+	}
+	return bytes.Clone(buf.Bytes()), nil
+}
+
+func (z *zipWriter) Write(p *Prepared) error {
+	header, err := zip.FileInfoHeader(p.Info)
+	if err != nil {
+		return err
+	}
+	header.Name = p.Name
+	header.Method = p.method
+	if p.Info.IsDir() {
+		header.Name += "/"
+	}
+
+	if p.streamed {
+		writer, err := z.zw.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		sum, n, err := copyFile(writer, p.path)
+		if err != nil {
+			return err
+		}
+		p.sum, p.Size = sum, n
+		z.record(p)
+		return nil
+	}
+
+	header.CRC32 = p.crc
+	header.CompressedSize64 = uint64(len(p.data))
+	header.UncompressedSize64 = uint64(p.Size)
+	writer, err := z.zw.CreateRaw(header)
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(p.data); err != nil {
+		return err
+	}
+	z.record(p)
+	return nil
+}
+
+func (z *zipWriter) Close() error {
+	data, err := z.encode()
+	if err != nil {
+		return err
+	}
+	writer, err := z.zw.CreateHeader(&zip.FileHeader{
+		Name:     ManifestName,
+		Method:   zip.Deflate,
+		Modified: z.manifest.Created,
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(data); err != nil {
+		return err
+	}
+	return z.zw.Close()
+}
+
+type tarWriter struct {
+	tw         *tar.Writer
+	compressor io.WriteCloser
+	manifestRecorder
+}
+
+func (t *tarWriter) Prepare(filePath, name string, info fs.FileInfo) (*Prepared, error) {
+	return prepare(filePath, name, info)
+}
+
+func (t *tarWriter) PrepareData(name string, info fs.FileInfo, data []byte) (*Prepared, error) {
+	return prepareData(name, info, data)
+}
+
+func (t *tarWriter) Write(p *Prepared) error {
+	// FileInfoHeader keeps the permission bits and, on Unix, the owner and group
+	header, err := tar.FileInfoHeader(p.Info, p.link)
+	if err != nil {
+		return err
+	}
+	header.Name = p.Name
+	if p.Info.IsDir() {
+		header.Name += "/"
+	}
+	if p.Info.Mode().IsRegular() {
+		header.Size = p.Size
+	}
+
+	if err := t.tw.WriteHeader(header); err != nil {
+		return err
+	}
+
+	switch {
+	case p.streamed:
+		// The header already holds the size from when the file was listed
+		sum, err := copyFileSize(t.tw, p.path, p.Size)
+		if err != nil {
+			return err
+		}
+		p.sum = sum
+	case p.data != nil:
+		if _, err := t.tw.Write(p.data); err != nil {
+			return err
+		}
+	}
+	t.record(p)
+	return nil
+}
+
+func (t *tarWriter) Close() error {
+	data, err := t.encode()
+	if err != nil {
+		return err
+	}
+	err = t.tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     ManifestName,
+		Size:     int64(len(data)),
+		Mode:     0644,
+		ModTime:  t.manifest.Created,
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := t.tw.Write(data); err != nil {
+		return err
+	}
+	if err := t.tw.Close(); err != nil {
+		return err
+	}
+	if t.compressor != nil {
+		return t.compressor.Close()
+	}
+	return nil
+}
+
+// copyFile copies the file at filePath to w and returns its SHA-256 and size.
+func copyFile(w io.Writer, filePath string) (string, int64, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", 0, err
+	}
+	defer file.Close()
+
+	return HashReader(io.TeeReader(file, w))
+}
+
+// copyFileSize copies exactly size bytes of the file at filePath to w: a
+// file that grew is cut off and one that shrank is padded with zeros. It
+// returns the SHA-256 of the bytes written.
+func copyFileSize(w io.Writer, filePath string, size int64) (string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	content := io.MultiReader(io.LimitReader(file, size), zeros{})
+	sum, _, err := HashReader(io.TeeReader(io.LimitReader(content, size), w))
+	return sum, err
+}
+
+// zeros reads an endless run of zero bytes.
+type zeros struct{}
+
+func (zeros) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
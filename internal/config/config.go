@@ -0,0 +1,218 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const defaultWorkspaceRoot = "D:/vscode/"
+
+// Config is read from config.json in the project-starter config directory.
+// Values of the form $NAME or ${NAME} in credentials are expanded from the
+// environment so secrets don't have to live in the file.
+type Config struct {
+	WorkspaceRoot string        `json:"workspace_root"`
+	Backup        BackupConfig  `json:"backup"`
+	Secrets       SecretsConfig `json:"secrets"`
+	Audit         AuditConfig   `json:"audit"`
+	Doctor        DoctorConfig  `json:"doctor"`
+}
+
+type BackupConfig struct {
+	// Stores are the named backup destinations projects can refer to.
+	Stores map[string]StoreConfig `json:"stores,omitempty"`
+	// DefaultStore is used for projects without their own entry. When empty,
+	// backups are written next to the project.
+	DefaultStore string `json:"default_store,omitempty"`
+	// Projects is keyed by project directory name.
+	Projects map[string]ProjectBackupConfig `json:"projects,omitempty"`
+	// Schedule is the cron expression the agent backs up changed projects
+	// on, unless a project has its own.
+	Schedule string `json:"schedule,omitempty"`
+	// Debounce is how long a project must be quiet after a change before
+	// the agent considers it for a backup, such as "30s".
+	Debounce string `json:"debounce,omitempty"`
+}
+
+type ProjectBackupConfig struct {
+	Store    string `json:"store,omitempty"`
+	Schedule string `json:"schedule,omitempty"`
+}
+
+// SecretsConfig tunes the secret scanner that runs before backups and git
+// initialization.
+type SecretsConfig struct {
+	// AllowPaths are globs of files that are never reported, matched against
+	// the path relative to the project and against the file name.
+	AllowPaths []string `json:"allow_paths,omitempty"`
+	// AllowPatterns are regular expressions for known harmless values, such
+	// as test keys. A secret matching one is not reported.
+	AllowPatterns []string `json:"allow_patterns,omitempty"`
+	// DisabledRules lists rule IDs to skip.
+	DisabledRules []string `json:"disabled_rules,omitempty"`
+	// OnBackup is what unattended backups do with files containing secrets:
+	// exclude (the default), redact, abort or ignore.
+	OnBackup string `json:"on_backup,omitempty"`
+}
+
+// AuditConfig locates the vulnerability database dependency audits use.
+type AuditConfig struct {
+	// Database is a directory of OSV JSON records. It defaults to osv in
+	// the config directory, where imported databases are extracted.
+	Database string `json:"database,omitempty"`
+}
+
+// DoctorConfig tunes the project health checks. Projects can refine it
+// with a .doctor.json file of the same shape, which teams commit to share
+// their rules.
+type DoctorConfig struct {
+	// DisabledRules lists rule IDs to skip.
+	DisabledRules []string `json:"disabled_rules,omitempty"`
+	// Severity overrides the status of failed rules by ID, "warn" or "fail".
+	Severity map[string]string `json:"severity,omitempty"`
+	// Require adds rules that fail unless one of their files exists.
+	Require []RequiredFileRule `json:"require,omitempty"`
+	// GoVersion is the oldest go directive go.mod files may declare. It
+	// defaults to the oldest Go release still supported.
+	GoVersion string `json:"go_version,omitempty"`
+	// Actions are the oldest major versions of GitHub Actions workflows may
+	// use, such as {"actions/checkout": 4}, added to the built-in ones.
+	Actions map[string]int `json:"actions,omitempty"`
+	// License is the SPDX ID of the license doctor --fix adds to projects
+	// without one, MIT by default.
+	License string `json:"license,omitempty"`
+}
+
+// RequiredFileRule is a doctor rule defined in the configuration.
+type RequiredFileRule struct {
+	ID          string `json:"id"`
+	Description string `json:"description"`
+	// Paths are globs relative to the project; any match passes.
+	Paths []string `json:"paths"`
+	// Severity is "warn" or "fail", the default.
+	Severity string `json:"severity,omitempty"`
+}
+
+// AuditDatabase returns the directory of the OSV database.
+func (c *Config) AuditDatabase() (string, error) {
+	if c.Audit.Database != "" {
+		return c.Audit.Database, nil
+	}
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "osv"), nil
+}
+
+type StoreConfig struct {
+	// Type is one of local, sftp, webdav or s3.
+	Type string `json:"type"`
+	// Path is the target directory for local and sftp stores and a key
+	// prefix for webdav and s3.
+	Path string `json:"path,omitempty"`
+
+	// SFTP
+	Host       string `json:"host,omitempty"`
+	User       string `json:"user,omitempty"`
+	Password   string `json:"password,omitempty"`
+	KeyFile    string `json:"key_file,omitempty"`
+	KnownHosts string `json:"known_hosts,omitempty"`
+
+	// WebDAV
+	URL string `json:"url,omitempty"`
+
+	// S3 and compatible services. A custom endpoint (such as MinIO) is
+	// addressed path-style.
+	Endpoint   string `json:"endpoint,omitempty"`
+	Region     string `json:"region,omitempty"`
+	Bucket     string `json:"bucket,omitempty"`
+	AccessKey  string `json:"access_key,omitempty"`
+	SecretKey  string `json:"secret_key,omitempty"`
+	PartSizeMB int    `json:"part_size_mb,omitempty"`
+}
+
+// Dir returns the directory holding the config file and other state. It can
+// be overridden with PROJECT_STARTER_HOME.
+func Dir() (string, error) {
+	if dir := os.Getenv("PROJECT_STARTER_HOME"); dir != "" {
+		return dir, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "project-starter"), nil
+}
+
+func Path() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.json"), nil
+}
+
+// Load reads the config file. A missing file yields the defaults.
+func Load() (*Config, error) {
+	cfg := &Config{WorkspaceRoot: defaultWorkspaceRoot}
+
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %v", err)
+	}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", path, err)
+	}
+	if cfg.WorkspaceRoot == "" {
+		cfg.WorkspaceRoot = defaultWorkspaceRoot
+	}
+	return cfg, nil
+}
+
+// StoreFor returns the name and settings of the backup store for a project.
+// ok is false when the project's backups stay next to the project.
+func (c *Config) StoreFor(projectName string) (name string, store StoreConfig, ok bool, err error) {
+	name = c.Backup.DefaultStore
+	if project, found := c.Backup.Projects[projectName]; found && project.Store != "" {
+		name = project.Store
+	}
+	if name == "" {
+		return "", StoreConfig{}, false, nil
+	}
+	store, err = c.Store(name)
+	return name, store, err == nil, err
+}
+
+// ScheduleFor returns the agent's backup schedule for a project.
+func (c *Config) ScheduleFor(projectName string) string {
+	if project, ok := c.Backup.Projects[projectName]; ok && project.Schedule != "" {
+		return project.Schedule
+	}
+	if c.Backup.Schedule != "" {
+		return c.Backup.Schedule
+	}
+	return "@hourly"
+}
+
+// Store returns the named store with environment references expanded.
+func (c *Config) Store(name string) (StoreConfig, error) {
+	store, ok := c.Backup.Stores[name]
+	if !ok {
+		return StoreConfig{}, fmt.Errorf("backup store %q is not configured", name)
+	}
+	store.Password = os.ExpandEnv(store.Password)
+	store.AccessKey = os.ExpandEnv(store.AccessKey)
+	store.SecretKey = os.ExpandEnv(store.SecretKey)
+	return store, nil
+}
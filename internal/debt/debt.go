@@ -0,0 +1,127 @@
+package debt
+
+import (
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"project-starter/internal/languages"
+)
+
+// Kinds are the markers Scan looks for.
+var Kinds = []string{"TODO", "FIXME", "HACK", "XXX"}
+
+// markerPattern matches a marker at the start of a comment, such as
+// "TODO: implement" or "FIXME(alice) handle errors". Markers in the middle
+// of a sentence are usually prose about them rather than debt.
+var markerPattern = regexp.MustCompile(`^[\s*/#!-]*(TODO|FIXME|HACK|XXX)\b(?:\(([^)]*)\))?:?\s*(.*)$`)
+
+// Marker is a tech-debt comment in a source file.
+type Marker struct {
+	Kind string `json:"kind"`
+	// Path is relative to the project, with forward slashes.
+	Path string `json:"path"`
+	Line int    `json:"line"`
+	Text string `json:"text"`
+	// Owner is the name in parentheses of markers like TODO(alice).
+	Owner string `json:"owner,omitempty"`
+	// Author is who last changed the line according to git blame. It is
+	// empty outside git repositories and for uncommitted lines.
+	Author string `json:"author,omitempty"`
+	// Date is when the line was committed, or the modification time of
+	// the file when blame isn't available.
+	Date time.Time `json:"date"`
+}
+
+// Dir returns the directory of the marker's file, "." for the project root.
+func (m Marker) Dir() string {
+	return path.Dir(m.Path)
+}
+
+// ScanFile returns the markers in the comments of the source file at
+// filePath, whose path relative to the project is rel. Files in unknown
+// languages have none.
+func ScanFile(filePath, rel string) ([]Marker, error) {
+	lang := languages.Detect(filePath)
+	if lang == nil {
+		return nil, nil
+	}
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	var markers []Marker
+	err = languages.Comments(file, lang, func(line int, text string) {
+		if m, ok := Match(text, rel, line, info.ModTime()); ok {
+			markers = append(markers, m)
+		}
+	})
+	return markers, err
+}
+
+// Match returns the marker a comment starts with, if any. The comment is
+// on line of the file rel, last modified at modified.
+func Match(comment, rel string, line int, modified time.Time) (Marker, bool) {
+	match := markerPattern.FindStringSubmatch(comment)
+	if match == nil {
+		return Marker{}, false
+	}
+	return Marker{
+		Kind:  match[1],
+		Path:  rel,
+		Line:  line,
+		Text:  strings.TrimSpace(match[3]),
+		Owner: strings.TrimSpace(match[2]),
+		Date:  modified,
+	}, true
+}
+
+// Sort orders markers by directory, file and line, so markers of a
+// directory are listed together.
+func Sort(markers []Marker) {
+	sort.Slice(markers, func(i, j int) bool {
+		a, b := markers[i], markers[j]
+		if a.Dir() != b.Dir() {
+			return a.Dir() < b.Dir()
+		}
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Line < b.Line
+	})
+}
+
+// Summary counts markers for project statistics.
+type Summary struct {
+	Total int `json:"total"`
+	Files int `json:"files"`
+	// Kinds and Directories count markers by kind and by directory.
+	Kinds       map[string]int `json:"kinds"`
+	Directories map[string]int `json:"directories"`
+	// Oldest is the date of the oldest marker, zero when there are none.
+	Oldest time.Time `json:"oldest"`
+}
+
+func Summarize(markers []Marker) Summary {
+	s := Summary{Total: len(markers), Kinds: make(map[string]int), Directories: make(map[string]int)}
+	files := make(map[string]bool)
+	for _, m := range markers {
+		files[m.Path] = true
+		s.Kinds[m.Kind]++
+		s.Directories[m.Dir()]++
+		if s.Oldest.IsZero() || m.Date.Before(s.Oldest) {
+			s.Oldest = m.Date
+		}
+	}
+	s.Files = len(files)
+	return s
+}
@@ -0,0 +1,180 @@
+package deps
+
+import (
+	"bufio"
+	"os"
+	"strings"
+)
+
+// readCargo returns the dependencies of Cargo.toml resolved against
+// Cargo.lock.
+func readCargo(projectPath string) ([]Dependency, error) {
+	declared, found, err := readFile(projectPath, "Cargo.toml", parseCargoToml)
+	if err != nil || !found {
+		return nil, err
+	}
+	locked, _, err := readFile(projectPath, "Cargo.lock", parseCargoLock)
+	if err != nil {
+		return nil, err
+	}
+	return merge(declared, locked), nil
+}
+
+// parseCargoToml reads the dependency tables of a Cargo.toml file, in both
+// the inline form (serde = "1") and the table form ([dependencies.serde]).
+func parseCargoToml(path string) ([]Dependency, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var deps []Dependency
+	section := ""
+	// tableDep is the dependency of a [dependencies.name] section
+	var tableDep *Dependency
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(stripTomlComment(scanner.Text()))
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			section = strings.Trim(line, "[] ")
+			tableDep = nil
+			if kind, name, ok := cargoDependencyTable(section); ok {
+				deps = append(deps, Dependency{Name: name, Ecosystem: EcosystemCargo, Direct: true, Dev: kind == "dev-dependencies"})
+				tableDep = &deps[len(deps)-1]
+			}
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		key = strings.Trim(strings.TrimSpace(key), `"`)
+		value = strings.TrimSpace(value)
+
+		if tableDep != nil {
+			if key == "version" {
+				tableDep.Version = strings.Trim(value, `"'`)
+				tableDep.Constraint = tableDep.Version
+			}
+			continue
+		}
+		kind, ok := cargoDependencySection(section)
+		if !ok {
+			continue
+		}
+		dep := Dependency{Name: key, Ecosystem: EcosystemCargo, Direct: true, Dev: kind == "dev-dependencies"}
+		if strings.HasPrefix(value, "{") {
+			dep.Version = tomlInlineValue(value, "version")
+		} else {
+			dep.Version = strings.Trim(value, `"'`)
+		}
+		dep.Constraint = dep.Version
+		deps = append(deps, dep)
+	}
+	return deps, scanner.Err()
+}
+
+// cargoDependencySection returns the kind of dependency table a section is,
+// including target specific ones like target.'cfg(unix)'.dependencies.
+func cargoDependencySection(section string) (string, bool) {
+	for _, kind := range []string{"dependencies", "dev-dependencies", "build-dependencies"} {
+		if section == kind || strings.HasSuffix(section, "."+kind) {
+			return kind, true
+		}
+	}
+	return "", false
+}
+
+func cargoDependencyTable(section string) (kind, name string, ok bool) {
+	for _, kind := range []string{"dependencies", "dev-dependencies", "build-dependencies"} {
+		prefix := kind + "."
+		if i := strings.Index(section, prefix); i >= 0 && (i == 0 || section[i-1] == '.') {
+			return kind, strings.Trim(section[i+len(prefix):], `"`), true
+		}
+	}
+	return "", "", false
+}
+
+// tomlInlineValue returns the string value of key in an inline table such
+// as { version = "1.0", features = ["derive"] }.
+func tomlInlineValue(table, key string) string {
+	table = strings.Trim(table, "{} ")
+	for _, part := range strings.Split(table, ",") {
+		k, v, ok := strings.Cut(part, "=")
+		if ok && strings.TrimSpace(k) == key {
+			return strings.Trim(strings.TrimSpace(v), `"'`)
+		}
+	}
+	return ""
+}
+
+func stripTomlComment(line string) string {
+	inString := false
+	for i, c := range line {
+		switch c {
+		case '"':
+			inString = !inString
+		case '#':
+			if !inString {
+				return line[:i]
+			}
+		}
+	}
+	return line
+}
+
+// parseCargoLock reads the [[package]] tables of Cargo.lock. Packages
+// without a source are the crates of the project's own workspace.
+func parseCargoLock(path string) ([]Dependency, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var deps []Dependency
+	var current *Dependency
+	local := false
+	flush := func() {
+		if current != nil && !local {
+			deps = append(deps, *current)
+		}
+		current, local = nil, true
+	}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			flush()
+			if line == "[[package]]" {
+				current = &Dependency{Ecosystem: EcosystemCargo}
+			}
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		value = strings.Trim(strings.TrimSpace(value), `"`)
+		switch strings.TrimSpace(key) {
+		case "name":
+			current.Name = value
+		case "version":
+			current.Version = value
+		case "source":
+			local = false
+		case "checksum":
+			current.Checksum = value
+		}
+	}
+	flush()
+	return deps, scanner.Err()
+}
@@ -0,0 +1,31 @@
+package deps
+
+import "testing"
+
+func TestParseCargoToml(t *testing.T) {
+	crate := func(name, version string) Dependency {
+		return Dependency{Name: name, Version: version, Constraint: version, Ecosystem: EcosystemCargo, Direct: true}
+	}
+	checkDependencies(t, "Cargo.toml", parseTestdata(t, "Cargo.toml", parseCargoToml), []Dependency{
+		crate("serde", "1.0"),
+		crate("anyhow", "1"),
+		crate("local-util", ""),
+		crate("tokio", "1.35"),
+		dev(crate("tempfile", "3.8")),
+		crate("cc", "1.0"),
+		crate("libc", "0.2"),
+		crate("windows-sys", "0.52"),
+	})
+}
+
+func TestParseCargoLock(t *testing.T) {
+	crate := func(name, version, checksum string) Dependency {
+		return Dependency{Name: name, Version: version, Ecosystem: EcosystemCargo, Checksum: checksum}
+	}
+	// The project's own crate has no source and is left out
+	checkDependencies(t, "Cargo.lock", parseTestdata(t, "Cargo.lock", parseCargoLock), []Dependency{
+		crate("anyhow", "1.0.79", "080e9890a082662b09c1ad45f567faeeb47f22b5fb23895fbe1e651e718e25ca"),
+		crate("serde", "1.0.195", "63261df402c67811e9ac6def069e4786148c4563f4b50fd4bf30aa370d626b02"),
+		crate("serde_derive", "1.0.195", "46fe8f8603d81ba86327b23a2e9cdf49e1255fb94a4c5f297f6ee0547178ea2c"),
+	})
+}
@@ -0,0 +1,207 @@
+package deps
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// Ecosystems of the package managers deps understands.
+const (
+	EcosystemGo    = "go"
+	EcosystemNPM   = "npm"
+	EcosystemCargo = "cargo"
+)
+
+// Dependency is a package a project depends on.
+type Dependency struct {
+	Name string `json:"name"`
+	// Version is the version a lockfile resolved, or the declared one for
+	// projects without a lockfile.
+	Version string `json:"version"`
+	// Locked is set when Version was resolved by a lockfile. Declared
+	// versions may be ranges, such as ^18.0.0 in package.json.
+	Locked bool `json:"locked"`
+	// Constraint is the version range the manifest declares.
+	Constraint string `json:"constraint,omitempty"`
+	Ecosystem  string `json:"ecosystem"`
+	// Direct is set for dependencies the project declares itself.
+	Direct bool `json:"direct"`
+	// Dev is set for dependencies only needed for development and tests.
+	Dev bool `json:"dev,omitempty"`
+	// License is the license the package declares, as an SPDX expression
+	// where possible. It is empty when the package isn't available locally.
+	License string `json:"license,omitempty"`
+	// Checksum is the hash the lockfile pins the package to: an h1: hash for
+	// Go, an SRI hash such as sha512-... for npm and a hex SHA-256 for Cargo.
+	Checksum string `json:"checksum,omitempty"`
+}
+
+// Read returns the dependencies of the project at projectPath from its
+// manifests (go.mod, package.json and Cargo.toml) and their lockfiles
+// (go.sum, package-lock.json, pnpm-lock.yaml, yarn.lock, bun.lock and
+// Cargo.lock). Lockfiles add resolved versions and transitive dependencies.
+// Licenses are looked up offline, in the module cache, node_modules and the
+// Cargo registry. Projects without any manifest have none.
+func Read(projectPath string) ([]Dependency, error) {
+	var all []Dependency
+	for _, read := range []func(string) ([]Dependency, error){readGo, readNPM, readCargo} {
+		found, err := read(projectPath)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, found...)
+	}
+	resolveLicenses(all, projectPath)
+	Sort(all)
+	return dedupe(all), nil
+}
+
+// dedupe merges copies of the same package, which lockfiles list once for
+// every place it is installed. deps must be sorted.
+func dedupe(deps []Dependency) []Dependency {
+	var out []Dependency
+	for _, d := range deps {
+		if n := len(out); n > 0 && out[n-1].Ecosystem == d.Ecosystem && out[n-1].Name == d.Name && out[n-1].Version == d.Version {
+			last := &out[n-1]
+			if d.Direct && !last.Direct {
+				last.Constraint = d.Constraint
+			}
+			last.Direct = last.Direct || d.Direct
+			last.Locked = last.Locked || d.Locked
+			last.Dev = last.Dev && d.Dev
+			if last.License == "" {
+				last.License = d.License
+			}
+			if last.Checksum == "" {
+				last.Checksum = d.Checksum
+			}
+			continue
+		}
+		out = append(out, d)
+	}
+	return out
+}
+
+// readFile parses the file name in projectPath. found is false when the
+// file doesn't exist.
+func readFile(projectPath, name string, parse func(path string) ([]Dependency, error)) (deps []Dependency, found bool, err error) {
+	deps, err = parse(filepath.Join(projectPath, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("%s: %v", name, err)
+	}
+	return deps, true, nil
+}
+
+// merge resolves declared dependencies against the packages of a lockfile.
+// Declared dependencies get the locked version that satisfies their
+// constraint, and locked packages that are not declared are transitive.
+func merge(declared, locked []Dependency) []Dependency {
+	byName := make(map[string][]int)
+	for i, l := range locked {
+		byName[l.Name] = append(byName[l.Name], i)
+	}
+
+	used := make([]bool, len(locked))
+	for i := range declared {
+		d := &declared[i]
+		candidates := byName[d.Name]
+		if len(candidates) == 0 {
+			continue
+		}
+		pick := candidates[0]
+		for _, j := range candidates {
+			if locked[j].Version == d.Version || satisfies(locked[j].Version, d.Constraint, d.Ecosystem) {
+				pick = j
+				break
+			}
+		}
+		used[pick] = true
+		d.Version = locked[pick].Version
+		d.Locked = true
+		d.Checksum = locked[pick].Checksum
+		if d.License == "" {
+			d.License = locked[pick].License
+		}
+	}
+
+	all := declared
+	for i, l := range locked {
+		if !used[i] {
+			l.Direct = false
+			l.Locked = true
+			all = append(all, l)
+		}
+	}
+	return all
+}
+
+// satisfies reports whether version is in the range constraint. Ranges it
+// can't parse, such as git URLs, are never satisfied.
+func satisfies(version, constraint, ecosystem string) bool {
+	// A bare Cargo version like "1.2" means ^1.2
+	if ecosystem == EcosystemCargo && constraint != "" && constraint[0] >= '0' && constraint[0] <= '9' {
+		constraint = "^" + constraint
+	}
+	c, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return false
+	}
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return false
+	}
+	return c.Check(v)
+}
+
+// Sort orders dependencies by ecosystem, name and version.
+func Sort(deps []Dependency) {
+	sort.Slice(deps, func(i, j int) bool {
+		if deps[i].Ecosystem != deps[j].Ecosystem {
+			return deps[i].Ecosystem < deps[j].Ecosystem
+		}
+		if deps[i].Name != deps[j].Name {
+			return deps[i].Name < deps[j].Name
+		}
+		return deps[i].Version < deps[j].Version
+	})
+}
+
+// Summary counts dependencies for project statistics.
+type Summary struct {
+	Direct     int            `json:"direct"`
+	Transitive int            `json:"transitive"`
+	Dev        int            `json:"dev"`
+	Ecosystems map[string]int `json:"ecosystems"`
+	// Licenses counts dependencies per license, with "unknown" for those
+	// whose license wasn't found.
+	Licenses map[string]int `json:"licenses"`
+}
+
+func Summarize(deps []Dependency) Summary {
+	s := Summary{Ecosystems: make(map[string]int), Licenses: make(map[string]int)}
+	for _, d := range deps {
+		if d.Direct {
+			s.Direct++
+		} else {
+			s.Transitive++
+		}
+		if d.Dev {
+			s.Dev++
+		}
+		s.Ecosystems[d.Ecosystem]++
+		license := d.License
+		if license == "" {
+			license = "unknown"
+		}
+		s.Licenses[license]++
+	}
+	return s
+}
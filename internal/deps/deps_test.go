@@ -0,0 +1,58 @@
+package deps
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// parseTestdata parses the file name of testdata with parse.
+func parseTestdata(t *testing.T, name string, parse func(path string) ([]Dependency, error)) []Dependency {
+	t.Helper()
+	deps, err := parse(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	return deps
+}
+
+// checkDependencies compares dependencies regardless of their order.
+func checkDependencies(t *testing.T, name string, got, want []Dependency) {
+	t.Helper()
+	got = append([]Dependency(nil), got...)
+	want = append([]Dependency(nil), want...)
+	Sort(got)
+	Sort(want)
+	if reflect.DeepEqual(got, want) {
+		return
+	}
+	t.Errorf("%s: got %d dependencies, want %d", name, len(got), len(want))
+	for i := 0; i < max(len(got), len(want)); i++ {
+		switch {
+		case i >= len(got):
+			t.Errorf("  missing %+v", want[i])
+		case i >= len(want):
+			t.Errorf("  unexpected %+v", got[i])
+		case !reflect.DeepEqual(got[i], want[i]):
+			t.Errorf("  got  %+v\n  want %+v", got[i], want[i])
+		}
+	}
+}
+
+func TestMerge(t *testing.T) {
+	declared := []Dependency{
+		{Name: "react", Version: "^18.0.0", Constraint: "^18.0.0", Ecosystem: EcosystemNPM, Direct: true},
+		{Name: "left-pad", Version: "^1.0.0", Constraint: "^1.0.0", Ecosystem: EcosystemNPM, Direct: true},
+	}
+	locked := []Dependency{
+		{Name: "react", Version: "17.0.2", Ecosystem: EcosystemNPM},
+		{Name: "react", Version: "18.2.0", Ecosystem: EcosystemNPM, Checksum: "sha512-a"},
+		{Name: "loose-envify", Version: "1.4.0", Ecosystem: EcosystemNPM},
+	}
+	checkDependencies(t, "merge", merge(declared, locked), []Dependency{
+		{Name: "react", Version: "18.2.0", Locked: true, Constraint: "^18.0.0", Ecosystem: EcosystemNPM, Direct: true, Checksum: "sha512-a"},
+		{Name: "left-pad", Version: "^1.0.0", Constraint: "^1.0.0", Ecosystem: EcosystemNPM, Direct: true},
+		{Name: "react", Version: "17.0.2", Locked: true, Ecosystem: EcosystemNPM},
+		{Name: "loose-envify", Version: "1.4.0", Locked: true, Ecosystem: EcosystemNPM},
+	})
+}
@@ -0,0 +1,133 @@
+package deps
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// readGo returns the requirements of go.mod with the checksums of go.sum.
+// Since Go 1.17 go.mod lists every module of the build, and go.sum also
+// has the modules only tests of dependencies use, so go.sum just adds
+// checksums. For older go.mod files modules in go.sum that go.mod doesn't
+// list are transitive. Other versions of modules go.mod requires are left
+// over from earlier builds and are dropped.
+func readGo(projectPath string) ([]Dependency, error) {
+	var goVersion string
+	declared, found, err := readFile(projectPath, "go.mod", func(path string) ([]Dependency, error) {
+		deps, version, err := parseGoMod(path)
+		goVersion = version
+		return deps, err
+	})
+	if err != nil || !found {
+		return nil, err
+	}
+	sums, _, err := readFile(projectPath, "go.sum", parseGoSum)
+	if err != nil {
+		return nil, err
+	}
+
+	complete := prunedModuleGraph(goVersion)
+	required := make(map[string]string, len(declared))
+	for _, d := range declared {
+		required[d.Name] = d.Version
+	}
+	var locked []Dependency
+	for _, s := range sums {
+		version, ok := required[s.Name]
+		if (!ok && !complete) || version == s.Version {
+			locked = append(locked, s)
+		}
+	}
+	return merge(declared, locked), nil
+}
+
+// prunedModuleGraph reports whether the go directive version is 1.17 or
+// later, from which go.mod lists every module of the build.
+func prunedModuleGraph(version string) bool {
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 || parts[0] != "1" {
+		return false
+	}
+	minor := parts[1]
+	if i := strings.IndexFunc(minor, func(r rune) bool { return r < '0' || r > '9' }); i >= 0 {
+		minor = minor[:i]
+	}
+	n, err := strconv.Atoi(minor)
+	return err == nil && n >= 17
+}
+
+// parseGoMod reads the require directives and the go directive version of
+// a go.mod file. Requirements marked "// indirect" are transitive.
+func parseGoMod(path string) ([]Dependency, string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, "", err
+	}
+	defer file.Close()
+
+	var deps []Dependency
+	var goVersion string
+	inRequire := false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		indirect := strings.HasSuffix(line, "// indirect")
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+
+		switch {
+		case line == "":
+			continue
+		case !inRequire && strings.HasPrefix(line, "go "):
+			goVersion = strings.TrimSpace(strings.TrimPrefix(line, "go "))
+			continue
+		case inRequire && line == ")":
+			inRequire = false
+			continue
+		case line == "require (":
+			inRequire = true
+			continue
+		case strings.HasPrefix(line, "require "):
+			line = strings.TrimSpace(strings.TrimPrefix(line, "require "))
+		case !inRequire:
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		deps = append(deps, Dependency{
+			Name:      strings.Trim(fields[0], `"`),
+			Version:   fields[1],
+			Ecosystem: EcosystemGo,
+			Direct:    !indirect,
+		})
+	}
+	return deps, goVersion, scanner.Err()
+}
+
+// parseGoSum reads the module hashes of a go.sum file. Modules that only
+// have a hash of their go.mod were needed for version selection but aren't
+// part of the build, so they are left out.
+func parseGoSum(path string) ([]Dependency, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var deps []Dependency
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 || strings.HasSuffix(fields[1], "/go.mod") {
+			continue
+		}
+		deps = append(deps, Dependency{Name: fields[0], Version: fields[1], Ecosystem: EcosystemGo, Checksum: fields[2]})
+	}
+	return deps, scanner.Err()
+}
@@ -0,0 +1,70 @@
+package deps
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testGoSum = `github.com/fatih/color v1.16.0 h1:zmkK9Ngbjj+K0yRhTVONQh1p/HknKYSlNT+vZCzyokM=
+github.com/fatih/color v1.16.0/go.mod h1:fL2Sau1YI5c0pdgEVUltKM/mjSo7+V6tqJZnoV2lZJA=
+github.com/fatih/color v1.17.0 h1:GlRw1BRJxkpqUCBKzKOw098ed57fEsKeNjpTe3cSjK4=
+github.com/fatih/color v1.17.0/go.mod h1:YZ7TlrGPkiz6ku9fK3TLD/pl3CpsiFyu8N92HLgmosI=
+github.com/mattn/go-isatty v0.0.20 h1:xfD0iDuEKnDkl03q4limB+vH+GxLEtL/jb4xVJSWWEY=
+github.com/mattn/go-isatty v0.0.20/go.mod h1:W+V8PltTTMOvKvAeJH7IuucS94S2C6jfK/D7dTCTo3Y=
+github.com/stretchr/testify v1.8.4 h1:CcVxjf3Q8PM0mHUKJCdn+eZZtm5yQwehR5yeSVQQcUk=
+github.com/stretchr/testify v1.8.4/go.mod h1:sz/lmYIOXD/1dqDmKjjqLyZ2RngseejIcXlSw2iwfAo=
+golang.org/x/text v0.14.0/go.mod h1:18ZOQIKpY8NJVqYksKHtTdi31H5itFRjB5/qKTNYzSU=
+`
+
+func writeGoModule(t *testing.T, goMod string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(goMod), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "go.sum"), []byte(testGoSum), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestReadGo(t *testing.T) {
+	module := func(name, version, checksum string, direct bool) Dependency {
+		return Dependency{Name: name, Version: version, Locked: true, Ecosystem: EcosystemGo, Direct: direct, Checksum: checksum}
+	}
+	color := module("github.com/fatih/color", "v1.17.0", "h1:GlRw1BRJxkpqUCBKzKOw098ed57fEsKeNjpTe3cSjK4=", true)
+	isatty := module("github.com/mattn/go-isatty", "v0.0.20", "h1:xfD0iDuEKnDkl03q4limB+vH+GxLEtL/jb4xVJSWWEY=", false)
+	testify := module("github.com/stretchr/testify", "v1.8.4", "h1:CcVxjf3Q8PM0mHUKJCdn+eZZtm5yQwehR5yeSVQQcUk=", false)
+
+	tests := []struct {
+		name, goMod string
+		want        []Dependency
+	}{
+		{
+			// go.sum has testify for the tests of a dependency, which
+			// go.mod leaves out as it isn't built
+			"go 1.22",
+			"module example.com/app\n\ngo 1.22.0\n\nrequire github.com/fatih/color v1.17.0\n\nrequire github.com/mattn/go-isatty v0.0.20 // indirect\n",
+			[]Dependency{color, isatty},
+		},
+		{
+			"go 1.21rc1",
+			"module example.com/app\n\ngo 1.21rc1\n\nrequire (\n\tgithub.com/fatih/color v1.17.0\n\tgithub.com/mattn/go-isatty v0.0.20 // indirect\n)\n",
+			[]Dependency{color, isatty},
+		},
+		{
+			// Before Go 1.17 go.mod only lists what the module imports
+			"go 1.16",
+			"module example.com/app\n\ngo 1.16\n\nrequire github.com/fatih/color v1.17.0\n",
+			[]Dependency{color, isatty, testify},
+		},
+	}
+	for _, tt := range tests {
+		deps, err := readGo(writeGoModule(t, tt.goMod))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		checkDependencies(t, tt.name, deps, tt.want)
+	}
+}
@@ -0,0 +1,189 @@
+package deps
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// resolveLicenses fills in the licenses lockfiles didn't declare from the
+// packages available locally: the Go module cache, node_modules next to the
+// manifest and the Cargo registry sources. Nothing is downloaded.
+func resolveLicenses(deps []Dependency, projectPath string) {
+	goModCache := goModuleCache()
+	cargoSources := cargoRegistrySources()
+	for i := range deps {
+		d := &deps[i]
+		if d.License != "" {
+			continue
+		}
+		switch d.Ecosystem {
+		case EcosystemGo:
+			if goModCache != "" {
+				d.License = goModuleLicense(goModCache, d.Name, d.Version)
+			}
+		case EcosystemNPM:
+			d.License = nodeModulesLicense(projectPath, d.Name)
+		case EcosystemCargo:
+			d.License = cargoLicense(cargoSources, d.Name, d.Version)
+		}
+	}
+}
+
+func goModuleCache() string {
+	if dir := os.Getenv("GOMODCACHE"); dir != "" {
+		return dir
+	}
+	if gopath := os.Getenv("GOPATH"); gopath != "" {
+		return filepath.Join(filepath.SplitList(gopath)[0], "pkg", "mod")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, "go", "pkg", "mod")
+}
+
+// goModuleLicense identifies the license file at the root of a module in
+// the module cache, where upper case letters in paths are escaped as !
+// followed by the lower case letter.
+func goModuleLicense(cache, module, version string) string {
+	var escaped strings.Builder
+	for _, r := range module + "@" + version {
+		if unicode.IsUpper(r) {
+			escaped.WriteByte('!')
+			r = unicode.ToLower(r)
+		}
+		escaped.WriteRune(r)
+	}
+	return licenseInDir(filepath.Join(cache, filepath.FromSlash(escaped.String())))
+}
+
+// licenseInDir identifies the first license file in dir.
+func licenseInDir(dir string) string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+	for _, entry := range entries {
+		name := strings.ToUpper(entry.Name())
+		if entry.IsDir() || !(strings.HasPrefix(name, "LICENSE") || strings.HasPrefix(name, "LICENCE") || strings.HasPrefix(name, "COPYING")) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		if license := identifyLicense(string(data)); license != "" {
+			return license
+		}
+	}
+	return ""
+}
+
+// licenseTexts identify common licenses by phrases from their text, most
+// specific first.
+var licenseTexts = []struct {
+	id      string
+	phrases []string
+}{
+	{"Apache-2.0", []string{"Apache License", "Version 2.0"}},
+	{"MPL-2.0", []string{"Mozilla Public License", "2.0"}},
+	{"AGPL-3.0", []string{"GNU AFFERO GENERAL PUBLIC LICENSE", "Version 3"}},
+	{"LGPL-3.0", []string{"GNU LESSER GENERAL PUBLIC LICENSE", "Version 3"}},
+	{"LGPL-2.1", []string{"GNU LESSER GENERAL PUBLIC LICENSE", "Version 2.1"}},
+	{"GPL-3.0", []string{"GNU GENERAL PUBLIC LICENSE", "Version 3"}},
+	{"GPL-2.0", []string{"GNU GENERAL PUBLIC LICENSE", "Version 2"}},
+	{"BSD-3-Clause", []string{"Redistribution and use in source and binary forms", "Neither the name"}},
+	{"BSD-3-Clause", []string{"Redistribution and use in source and binary forms", "names of its contributors"}},
+	{"BSD-2-Clause", []string{"Redistribution and use in source and binary forms"}},
+	{"ISC", []string{"Permission to use, copy, modify, and/or distribute this software for any"}},
+	{"ISC", []string{"Permission to use, copy, modify, and distribute this software for any"}},
+	{"MIT", []string{"Permission is hereby granted, free of charge"}},
+	{"Unlicense", []string{"This is free and unencumbered software released into the public domain"}},
+	{"CC0-1.0", []string{"CC0 1.0 Universal"}},
+}
+
+// identifyLicense returns the SPDX identifier of a license text, or an
+// empty string for texts it doesn't recognize.
+func identifyLicense(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	for _, license := range licenseTexts {
+		matched := true
+		for _, phrase := range license.phrases {
+			if !strings.Contains(text, phrase) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return license.id
+		}
+	}
+	return ""
+}
+
+// nodeModulesLicense reads the license field of an installed package.
+func nodeModulesLicense(projectPath, name string) string {
+	data, err := os.ReadFile(filepath.Join(projectPath, "node_modules", filepath.FromSlash(name), "package.json"))
+	if err != nil {
+		return ""
+	}
+	var pkg struct {
+		License json.RawMessage `json:"license"`
+	}
+	if json.Unmarshal(data, &pkg) != nil {
+		return ""
+	}
+	return npmLicense(pkg.License)
+}
+
+// cargoRegistrySources returns the directories Cargo extracts downloaded
+// crates into, one per registry.
+func cargoRegistrySources() []string {
+	home := os.Getenv("CARGO_HOME")
+	if home == "" {
+		userHome, err := os.UserHomeDir()
+		if err != nil {
+			return nil
+		}
+		home = filepath.Join(userHome, ".cargo")
+	}
+	dirs, _ := filepath.Glob(filepath.Join(home, "registry", "src", "*"))
+	return dirs
+}
+
+// cargoLicense reads the license field of a crate's Cargo.toml in the
+// registry sources, falling back to its license file.
+func cargoLicense(sources []string, name, version string) string {
+	for _, dir := range sources {
+		crateDir := filepath.Join(dir, name+"-"+version)
+		file, err := os.Open(filepath.Join(crateDir, "Cargo.toml"))
+		if err != nil {
+			continue
+		}
+		license := ""
+		inPackage := false
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if strings.HasPrefix(line, "[") {
+				inPackage = line == "[package]"
+				continue
+			}
+			if key, value, ok := strings.Cut(line, "="); ok && inPackage && strings.TrimSpace(key) == "license" {
+				license = strings.Trim(strings.TrimSpace(value), `"'`)
+				break
+			}
+		}
+		file.Close()
+		if license == "" {
+			license = licenseInDir(crateDir)
+		}
+		return license
+	}
+	return ""
+}
@@ -0,0 +1,392 @@
+package deps
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"sort"
+	"strings"
+)
+
+// npmLockfiles are tried in order; a project normally has only one.
+var npmLockfiles = []struct {
+	name  string
+	parse func(path string) ([]Dependency, error)
+}{
+	{"package-lock.json", parsePackageLock},
+	{"pnpm-lock.yaml", parsePnpmLock},
+	{"yarn.lock", parseYarnLock},
+	{"bun.lock", parseBunLock},
+}
+
+// readNPM returns the dependencies of package.json resolved against the
+// first lockfile found.
+func readNPM(projectPath string) ([]Dependency, error) {
+	declared, found, err := readFile(projectPath, "package.json", parsePackageJSON)
+	if err != nil || !found {
+		return nil, err
+	}
+	for _, lockfile := range npmLockfiles {
+		locked, found, err := readFile(projectPath, lockfile.name, lockfile.parse)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			return merge(declared, locked), nil
+		}
+	}
+	return declared, nil
+}
+
+type packageJSON struct {
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+}
+
+func parsePackageJSON(path string) ([]Dependency, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var pkg packageJSON
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil, err
+	}
+
+	var deps []Dependency
+	add := func(m map[string]string, dev bool) {
+		for name, version := range m {
+			deps = append(deps, Dependency{Name: name, Version: version, Constraint: version, Ecosystem: EcosystemNPM, Direct: true, Dev: dev})
+		}
+	}
+	add(pkg.Dependencies, false)
+	add(pkg.OptionalDependencies, false)
+	add(pkg.DevDependencies, true)
+	return deps, nil
+}
+
+type packageLockEntry struct {
+	Version   string          `json:"version"`
+	Integrity string          `json:"integrity"`
+	License   json.RawMessage `json:"license"`
+	Dev       bool            `json:"dev"`
+	Link      bool            `json:"link"`
+}
+
+// packageLockV1Entry is a package of a version 1 lockfile, which nests the
+// packages installed below it. In "packages" dependencies are version
+// ranges instead.
+type packageLockV1Entry struct {
+	packageLockEntry
+	Dependencies map[string]packageLockV1Entry `json:"dependencies"`
+}
+
+// parsePackageLock reads package-lock.json. Version 2 and 3 lockfiles list
+// every installed package under "packages" keyed by its node_modules path;
+// version 1 nests them under "dependencies".
+func parsePackageLock(path string) ([]Dependency, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var lock struct {
+		Packages     map[string]packageLockEntry   `json:"packages"`
+		Dependencies map[string]packageLockV1Entry `json:"dependencies"`
+	}
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, err
+	}
+
+	var deps []Dependency
+	if lock.Packages != nil {
+		// Top level packages first, so they are preferred over nested
+		// copies when resolving declared dependencies
+		keys := make([]string, 0, len(lock.Packages))
+		for key := range lock.Packages {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			di, dj := strings.Count(keys[i], "node_modules/"), strings.Count(keys[j], "node_modules/")
+			if di != dj {
+				return di < dj
+			}
+			return keys[i] < keys[j]
+		})
+		for _, key := range keys {
+			entry := lock.Packages[key]
+			i := strings.LastIndex(key, "node_modules/")
+			// The project itself and workspace packages aren't dependencies
+			if i < 0 || entry.Link || entry.Version == "" {
+				continue
+			}
+			deps = append(deps, packageLockDependency(key[i+len("node_modules/"):], entry))
+		}
+		return deps, nil
+	}
+
+	var walk func(entries map[string]packageLockV1Entry)
+	walk = func(entries map[string]packageLockV1Entry) {
+		names := make([]string, 0, len(entries))
+		for name := range entries {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			deps = append(deps, packageLockDependency(name, entries[name].packageLockEntry))
+		}
+		for _, name := range names {
+			walk(entries[name].Dependencies)
+		}
+	}
+	walk(lock.Dependencies)
+	return deps, nil
+}
+
+func packageLockDependency(name string, entry packageLockEntry) Dependency {
+	return Dependency{
+		Name:      name,
+		Version:   entry.Version,
+		Ecosystem: EcosystemNPM,
+		Dev:       entry.Dev,
+		License:   npmLicense(entry.License),
+		Checksum:  entry.Integrity,
+	}
+}
+
+// npmLicense reads the license field of a package, which is an SPDX
+// expression or, in old packages, an object with a type.
+func npmLicense(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var license string
+	if json.Unmarshal(raw, &license) == nil {
+		return license
+	}
+	var object struct {
+		Type string `json:"type"`
+	}
+	if json.Unmarshal(raw, &object) == nil {
+		return object.Type
+	}
+	return ""
+}
+
+// parsePnpmLock reads the packages section of pnpm-lock.yaml. Keys are
+// "/name/1.0.0" in lockfile version 5, "/name@1.0.0" in version 6 and
+// "name@1.0.0" in version 9, with peer dependencies appended in parentheses.
+func parsePnpmLock(path string) ([]Dependency, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var deps []Dependency
+	var current *Dependency
+	inPackages := false
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, " ") {
+			inPackages = line == "packages:"
+			current = nil
+			continue
+		}
+		if !inPackages {
+			continue
+		}
+
+		if strings.HasPrefix(line, "  ") && !strings.HasPrefix(line, "   ") {
+			current = nil
+			key := strings.Trim(strings.TrimSuffix(strings.TrimSpace(line), ":"), `'"`)
+			name, version, ok := parsePnpmKey(key)
+			if ok {
+				deps = append(deps, Dependency{Name: name, Version: version, Ecosystem: EcosystemNPM})
+				current = &deps[len(deps)-1]
+			}
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		field := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(field, "resolution:"):
+			if i := strings.Index(field, "integrity: "); i >= 0 {
+				current.Checksum = strings.TrimRight(field[i+len("integrity: "):], "} ")
+			}
+		case field == "dev: true":
+			current.Dev = true
+		}
+	}
+	return deps, scanner.Err()
+}
+
+func parsePnpmKey(key string) (name, version string, ok bool) {
+	if i := strings.IndexByte(key, '('); i >= 0 {
+		key = key[:i]
+	}
+	if strings.HasPrefix(key, "/") {
+		key = key[1:]
+		// Version 5 separates the version with a slash and appends peer
+		// dependencies after an underscore, as in /name/1.0.0_react@18.2.0
+		if i := strings.LastIndexByte(key, '/'); i > 0 {
+			version, _, _ = strings.Cut(key[i+1:], "_")
+			if version != "" && version[0] >= '0' && version[0] <= '9' && !strings.Contains(version, "@") {
+				return key[:i], version, true
+			}
+		}
+	}
+	i := strings.LastIndexByte(key, '@')
+	if i <= 0 {
+		return "", "", false
+	}
+	return key[:i], key[i+1:], true
+}
+
+// parseYarnLock reads yarn.lock in both the classic format and the YAML
+// format of Yarn 2 and later. Each entry starts with the specifiers that
+// resolved to it, such as "react@^18.0.0, react@^18.2.0:".
+func parseYarnLock(path string) ([]Dependency, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var deps []Dependency
+	var current *Dependency
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if !strings.HasPrefix(line, " ") {
+			current = nil
+			spec, _, _ := strings.Cut(strings.TrimSuffix(line, ":"), ",")
+			spec = strings.Trim(spec, `"`)
+			// The name may start with @ for scoped packages, so the version
+			// separator is searched from the second character
+			if len(spec) < 2 || spec == "__metadata" {
+				continue
+			}
+			i := strings.IndexByte(spec[1:], '@')
+			if i < 0 {
+				continue
+			}
+			deps = append(deps, Dependency{Name: spec[:i+1], Ecosystem: EcosystemNPM})
+			current = &deps[len(deps)-1]
+			continue
+		}
+		if current == nil || strings.HasPrefix(line, "    ") {
+			continue
+		}
+
+		key, value, _ := strings.Cut(strings.TrimSpace(line), " ")
+		key = strings.TrimSuffix(key, ":")
+		value = strings.Trim(strings.TrimSpace(value), `"`)
+		switch key {
+		case "version":
+			current.Version = value
+		case "integrity":
+			current.Checksum = value
+		case "resolution":
+			// Workspace packages are part of the project
+			if strings.Contains(value, "@workspace:") {
+				current.Version = ""
+			}
+		}
+	}
+
+	kept := deps[:0]
+	for _, d := range deps {
+		if d.Version != "" {
+			kept = append(kept, d)
+		}
+	}
+	return kept, scanner.Err()
+}
+
+// parseBunLock reads the text lockfile of Bun 1.2 and later, which is JSON
+// with trailing commas. Packages are arrays starting with "name@version" and
+// ending with the integrity hash.
+func parseBunLock(path string) ([]Dependency, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var lock struct {
+		Packages map[string][]json.RawMessage `json:"packages"`
+	}
+	if err := json.Unmarshal(stripTrailingCommas(data), &lock); err != nil {
+		return nil, err
+	}
+
+	var deps []Dependency
+	for _, fields := range lock.Packages {
+		if len(fields) == 0 {
+			continue
+		}
+		var id string
+		if json.Unmarshal(fields[0], &id) != nil {
+			continue
+		}
+		i := strings.LastIndexByte(id, '@')
+		if i <= 0 || strings.Contains(id[i+1:], ":") {
+			// Workspace, git and file packages have no registry version
+			continue
+		}
+		dep := Dependency{Name: id[:i], Version: id[i+1:], Ecosystem: EcosystemNPM}
+		var integrity string
+		if json.Unmarshal(fields[len(fields)-1], &integrity) == nil && strings.HasPrefix(integrity, "sha") {
+			dep.Checksum = integrity
+		}
+		deps = append(deps, dep)
+	}
+	sort.Slice(deps, func(i, j int) bool { return deps[i].Name < deps[j].Name })
+	return deps, nil
+}
+
+// stripTrailingCommas removes commas before a closing bracket or brace,
+// outside of strings.
+func stripTrailingCommas(data []byte) []byte {
+	var out bytes.Buffer
+	inString, escaped := false, false
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			out.WriteByte(c)
+			continue
+		}
+		if c == '"' {
+			inString = true
+		}
+		if c == ',' {
+			j := i + 1
+			for j < len(data) && (data[j] == ' ' || data[j] == '\t' || data[j] == '\n' || data[j] == '\r') {
+				j++
+			}
+			if j < len(data) && (data[j] == '}' || data[j] == ']') {
+				continue
+			}
+		}
+		out.WriteByte(c)
+	}
+	return out.Bytes()
+}
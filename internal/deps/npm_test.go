@@ -0,0 +1,139 @@
+package deps
+
+import "testing"
+
+const (
+	reactIntegrity       = "sha512-/3IjMdb2L9QbBdWiW5e3P2/npwMBaU9mHCSCUzNln0ZCYbcfTsGbTJrU/kGemdH2IWmB2ioZ+zkxtmq6g09fGQ=="
+	reactDOMIntegrity    = "sha512-6IMTriUmvsjHUjNtEDudZfuDQUoWXVxKHhlEGSk81n4YFS+r/Kl99wXiwlVXtPBtJenozv2P+hxDsw9eA7Xo6g=="
+	babelIntegrity       = "sha512-97z/ju/Jy1rZmDxybphrBuI+jtJjFVoz7Mr9yUQVVVi+DNZE333uFQeMOqcCIy1x3WYBIbWftUSLmbNXNT7qFQ=="
+	jsTokensIntegrity    = "sha512-RdJUflcE3cUzKiMqQgsCu06FPu9UdIJO0beYbPhHN4k6apgJtifcoCtT9bcxOpYBtpD2kCM6Sbzg4CausW/PKQ=="
+	mochaIntegrity       = "sha512-IDY7fl/BecMwFHzoqF2sg/SHHANeBoMMXFlS9r0OXKDssYE1M5O43wUY/9BVPeIvfH2zmEbBfseqN9gBQZzXkg=="
+	msIntegrity          = "sha512-6FlzubTLZG3J2a/NVCAleEhjzq5oxgHyaCU9yYXvcLsvoVaHJq/s5xXI6/XXP6tz7R9xAOtHnSO/tXtF3WRTlA=="
+	looseEnvifyIntegrity = "sha512-lyuxPGr/Wfhrlem2CL/UcnUc1zcqKAImBDzukY7Y5F/yQiNdko6+fRLevlw1HgMySw7f611UIY408EtxRSoK3Q=="
+	propTypesIntegrity   = "sha512-ga8y9v9uyeiLdpKddhxYQkxNDrfvuPrlFb0N1qnZZByvcElJaXthF1UhvCh9TLWJBEHeNtdnbysW7Y6Uq8CVng=="
+)
+
+func npm(name, version, checksum string) Dependency {
+	return Dependency{Name: name, Version: version, Ecosystem: EcosystemNPM, Checksum: checksum}
+}
+
+func dev(d Dependency) Dependency {
+	d.Dev = true
+	return d
+}
+
+func licensed(d Dependency, license string) Dependency {
+	d.License = license
+	return d
+}
+
+func TestParsePackageLock(t *testing.T) {
+	tests := []struct {
+		file string
+		want []Dependency
+	}{
+		{"package-lock-v1.json", []Dependency{
+			npm("js-tokens", "4.0.0", jsTokensIntegrity),
+			npm("loose-envify", "1.4.0", looseEnvifyIntegrity),
+			dev(npm("mocha", "10.2.0", mochaIntegrity)),
+			dev(npm("ms", "2.1.3", msIntegrity)),
+			npm("react", "18.2.0", reactIntegrity),
+		}},
+		// Version 2 also has the version 1 section, which is ignored
+		{"package-lock-v2.json", []Dependency{
+			licensed(npm("js-tokens", "4.0.0", jsTokensIntegrity), "MIT"),
+			licensed(dev(npm("mocha", "10.2.0", mochaIntegrity)), "MIT"),
+			licensed(dev(npm("ms", "2.1.3", msIntegrity)), "MIT"),
+			licensed(npm("react", "18.2.0", reactIntegrity), "MIT"),
+		}},
+		// Workspace links and packages are left out
+		{"package-lock-v3.json", []Dependency{
+			licensed(dev(npm("@types/prop-types", "15.7.11", propTypesIntegrity)), "MIT"),
+			licensed(npm("react", "18.2.0", reactIntegrity), "MIT"),
+		}},
+	}
+	for _, tt := range tests {
+		checkDependencies(t, tt.file, parseTestdata(t, tt.file, parsePackageLock), tt.want)
+	}
+}
+
+func TestParsePackageLockPrefersTopLevel(t *testing.T) {
+	deps := parseTestdata(t, "package-lock-v2.json", parsePackageLock)
+	if deps[len(deps)-1].Name != "ms" {
+		t.Errorf("nested package ms isn't last: %+v", deps)
+	}
+}
+
+func TestParsePnpmLock(t *testing.T) {
+	for _, file := range []string{"pnpm-lock-v5.yaml", "pnpm-lock-v6.yaml"} {
+		checkDependencies(t, file, parseTestdata(t, file, parsePnpmLock), []Dependency{
+			dev(npm("@babel/core", "7.23.0", babelIntegrity)),
+			npm("react-dom", "18.2.0", reactDOMIntegrity),
+			npm("react", "18.2.0", reactIntegrity),
+		})
+	}
+	// Version 9 moved dev flags to the importers
+	checkDependencies(t, "pnpm-lock-v9.yaml", parseTestdata(t, "pnpm-lock-v9.yaml", parsePnpmLock), []Dependency{
+		npm("@babel/core", "7.23.0", babelIntegrity),
+		npm("react-dom", "18.2.0", reactDOMIntegrity),
+		npm("react", "18.2.0", reactIntegrity),
+	})
+}
+
+func TestParsePnpmKey(t *testing.T) {
+	tests := []struct {
+		key, name, version string
+	}{
+		{"/react/18.2.0", "react", "18.2.0"},
+		{"/@babel/core/7.23.0", "@babel/core", "7.23.0"},
+		{"/react-dom/18.2.0_react@18.2.0", "react-dom", "18.2.0"},
+		{"/@emotion/react/11.11.1_4ft3fsvo6qpgl3dy5ocvbcpciq", "@emotion/react", "11.11.1"},
+		{"/react@18.2.0", "react", "18.2.0"},
+		{"/@babel/core@7.23.0", "@babel/core", "7.23.0"},
+		{"/react-dom@18.2.0(react@18.2.0)", "react-dom", "18.2.0"},
+		{"/@scope/3d-view@1.0.0", "@scope/3d-view", "1.0.0"},
+		{"react@18.2.0", "react", "18.2.0"},
+		{"@babel/core@7.23.0", "@babel/core", "7.23.0"},
+		{"react-dom@18.2.0(react@18.2.0)", "react-dom", "18.2.0"},
+	}
+	for _, tt := range tests {
+		name, version, ok := parsePnpmKey(tt.key)
+		if !ok || name != tt.name || version != tt.version {
+			t.Errorf("parsePnpmKey(%q) = %q, %q, %v, want %q, %q", tt.key, name, version, ok, tt.name, tt.version)
+		}
+	}
+}
+
+func TestParseYarnLock(t *testing.T) {
+	checkDependencies(t, "yarn-classic.lock", parseTestdata(t, "yarn-classic.lock", parseYarnLock), []Dependency{
+		npm("@babel/core", "7.23.0", babelIntegrity),
+		npm("js-tokens", "4.0.0", jsTokensIntegrity),
+		npm("react", "18.2.0", reactIntegrity),
+	})
+	// Berry checksums aren't SRI hashes and workspaces are left out
+	checkDependencies(t, "yarn-berry.lock", parseTestdata(t, "yarn-berry.lock", parseYarnLock), []Dependency{
+		npm("@babel/core", "7.23.0", ""),
+		npm("react", "18.2.0", ""),
+	})
+}
+
+func TestParseBunLock(t *testing.T) {
+	checkDependencies(t, "bun.lock", parseTestdata(t, "bun.lock", parseBunLock), []Dependency{
+		npm("@types/prop-types", "15.7.11", propTypesIntegrity),
+		npm("js-tokens", "4.0.0", jsTokensIntegrity),
+		npm("react", "18.2.0", reactIntegrity),
+	})
+}
+
+func TestStripTrailingCommas(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{`{"a": [1, 2,], "b": {"c": 1,},}`, `{"a": [1, 2], "b": {"c": 1}}`},
+		{"[1,\n  ]", "[1\n  ]"},
+		{`{"a": "x,]", "b": "\",}"}`, `{"a": "x,]", "b": "\",}"}`},
+	}
+	for _, tt := range tests {
+		if got := string(stripTrailingCommas([]byte(tt.in))); got != tt.want {
+			t.Errorf("stripTrailingCommas(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package doctor
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is how many unchanged lines surround each hunk.
+const diffContext = 3
+
+// maxDiffCells bounds the table the line diff fills. Bigger files are
+// shown as replaced whole.
+const maxDiffCells = 4 << 20
+
+// Diff returns the change as a unified diff, like git diff shows it.
+func (c Change) Diff() string {
+	oldName, newName := "a/"+c.Path, "b/"+c.Path
+	if c.Old == nil {
+		oldName = "/dev/null"
+	}
+	oldLines, newLines := splitLines(string(c.Old)), splitLines(string(c.New))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldName, newName)
+	ops := diffLines(oldLines, newLines)
+	for start := 0; start < len(ops); {
+		// Find the next change and the hunk around it
+		for start < len(ops) && ops[start].kind == ' ' {
+			start++
+		}
+		if start == len(ops) {
+			break
+		}
+		from := max(start-diffContext, 0)
+		end := start
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+			// Merge changes closer than twice the context into one hunk
+			next := end
+			for next < len(ops) && ops[next].kind == ' ' {
+				next++
+			}
+			if next == len(ops) || next-end > 2*diffContext {
+				break
+			}
+			end = next
+		}
+		to := min(end+diffContext, len(ops))
+		writeHunk(&b, ops[from:to])
+		start = to
+	}
+	return b.String()
+}
+
+type diffOp struct {
+	kind byte // ' ', '-' or '+'
+	text string
+	// oldLine and newLine are the 1-based line numbers before the op.
+	oldLine, newLine int
+}
+
+func writeHunk(b *strings.Builder, ops []diffOp) {
+	oldCount, newCount := 0, 0
+	for _, op := range ops {
+		if op.kind != '+' {
+			oldCount++
+		}
+		if op.kind != '-' {
+			newCount++
+		}
+	}
+	// Empty ranges start at the line before them
+	oldStart, newStart := ops[0].oldLine, ops[0].newLine
+	if oldCount == 0 {
+		oldStart--
+	}
+	if newCount == 0 {
+		newStart--
+	}
+	fmt.Fprintf(b, "@@ -%s +%s @@\n", hunkRange(oldStart, oldCount), hunkRange(newStart, newCount))
+	for _, op := range ops {
+		text := op.text
+		b.WriteByte(op.kind)
+		if strings.HasSuffix(text, "\n") {
+			b.WriteString(text)
+		} else {
+			b.WriteString(text + "\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+func hunkRange(start, count int) string {
+	if count == 1 {
+		return fmt.Sprint(start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+// splitLines splits text after each newline; the last line may lack one.
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines turns a into b with the fewest removed and added lines, found
+// with a longest common subsequence table.
+func diffLines(a, b []string) []diffOp {
+	// Trim the common prefix and suffix, which is most of a small fix
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	midA, midB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+
+	var ops []diffOp
+	oldLine, newLine := 1, 1
+	emit := func(kind byte, text string) {
+		ops = append(ops, diffOp{kind: kind, text: text, oldLine: oldLine, newLine: newLine})
+		if kind != '+' {
+			oldLine++
+		}
+		if kind != '-' {
+			newLine++
+		}
+	}
+
+	for _, line := range a[:prefix] {
+		emit(' ', line)
+	}
+	if (len(midA)+1)*(len(midB)+1) > maxDiffCells {
+		for _, line := range midA {
+			emit('-', line)
+		}
+		for _, line := range midB {
+			emit('+', line)
+		}
+	} else {
+		// lcs[i][j] is the longest common subsequence of midA[i:] and midB[j:]
+		lcs := make([][]int, len(midA)+1)
+		for i := range lcs {
+			lcs[i] = make([]int, len(midB)+1)
+		}
+		for i := len(midA) - 1; i >= 0; i-- {
+			for j := len(midB) - 1; j >= 0; j-- {
+				if midA[i] == midB[j] {
+					lcs[i][j] = lcs[i+1][j+1] + 1
+				} else {
+					lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+				}
+			}
+		}
+		i, j := 0, 0
+		for i < len(midA) || j < len(midB) {
+			switch {
+			case i < len(midA) && j < len(midB) && midA[i] == midB[j]:
+				emit(' ', midA[i])
+				i++
+				j++
+			case j == len(midB) || (i < len(midA) && lcs[i+1][j] >= lcs[i][j+1]):
+				emit('-', midA[i])
+				i++
+			default:
+				emit('+', midB[j])
+				j++
+			}
+		}
+	}
+	for _, line := range a[len(a)-suffix:] {
+		emit(' ', line)
+	}
+	return ops
+}
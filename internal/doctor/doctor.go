@@ -0,0 +1,293 @@
+package doctor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"project-starter/internal/config"
+	"project-starter/internal/ignore"
+	"project-starter/internal/walk"
+)
+
+// Status is the outcome of a rule.
+type Status string
+
+const (
+	Pass Status = "pass"
+	Warn Status = "warn"
+	Fail Status = "fail"
+	// Skip is for rules that don't apply, such as Dockerfile checks in a
+	// project without one.
+	Skip Status = "skip"
+)
+
+// ProjectConfigFile is the per-project configuration, merged over the
+// doctor section of the global configuration.
+const ProjectConfigFile = ".doctor.json"
+
+// Rule is a health check.
+type Rule struct {
+	ID          string
+	Description string
+	// Severity is the status the rule reports problems with, Warn or Fail.
+	// The configuration can override it.
+	Severity Status
+	Check    func(p *Project) Outcome
+	// Fix, when set, returns the changes that fix what Check found without
+	// writing them. Rules without one are fixed by hand.
+	Fix func(p *Project) ([]Change, error)
+}
+
+// Outcome is what a rule found.
+type Outcome struct {
+	// Skip, when set, is why the rule doesn't apply.
+	Skip string
+	// Problems are reported with the rule's severity.
+	Problems []string
+	// Warnings are reported as Warn whatever the severity, for things
+	// that will become problems, like an image reaching end of life soon.
+	Warnings []string
+	// Detail describes what passed, such as the README found.
+	Detail string
+}
+
+// Result is the status of one rule for a project.
+type Result struct {
+	Rule        string   `json:"rule"`
+	Description string   `json:"description"`
+	Status      Status   `json:"status"`
+	Messages    []string `json:"messages,omitempty"`
+}
+
+// Report are the results of every enabled rule for a project.
+type Report struct {
+	Name    string   `json:"name"`
+	Path    string   `json:"path"`
+	Type    string   `json:"type,omitempty"`
+	Results []Result `json:"results"`
+	Summary Summary  `json:"summary"`
+}
+
+type Summary struct {
+	Passed   int `json:"passed"`
+	Warnings int `json:"warnings"`
+	Failed   int `json:"failed"`
+	Skipped  int `json:"skipped"`
+}
+
+// registry holds the rules every check runs, built-in ones first.
+var registry = append([]Rule(nil), builtinRules...)
+
+// Register adds a rule to every check, for rules that need code rather
+// than configuration. Rule IDs must be unique.
+func Register(rule Rule) {
+	for _, r := range registry {
+		if r.ID == rule.ID {
+			panic("doctor: rule " + rule.ID + " registered twice")
+		}
+	}
+	registry = append(registry, rule)
+}
+
+// Rules returns the registered rules followed by those the configuration
+// requires.
+func Rules(cfg config.DoctorConfig) []Rule {
+	rules := append([]Rule(nil), registry...)
+	for _, r := range cfg.Require {
+		rules = append(rules, requiredFileRule(r))
+	}
+	return rules
+}
+
+// Project is what rules check.
+type Project struct {
+	// Path is the absolute project directory.
+	Path string
+	// Type is the kind of project, as detected for the dashboard, or empty.
+	Type   string
+	Config config.DoctorConfig
+	Now    time.Time
+	// Files are the project's files relative to Path with forward slashes,
+	// leaving out dependencies, build output and what .gitignore excludes.
+	Files []string
+}
+
+// NewProject lists the files of the project at the absolute projectPath.
+func NewProject(projectPath, projectType string, cfg config.DoctorConfig) (*Project, error) {
+	ignored, err := ignore.Load(projectPath, ".gitignore")
+	if err != nil {
+		return nil, err
+	}
+	p := &Project{Path: projectPath, Type: projectType, Config: cfg, Now: time.Now()}
+	err = walk.Walk(projectPath, walk.Options{
+		Skip: func(rel string, d fs.DirEntry) bool { return ignored.MatchDir(rel) },
+	}, func(_, rel string, d fs.DirEntry) error {
+		if !d.IsDir() && !ignored.MatchFile(rel) {
+			p.Files = append(p.Files, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(p.Files)
+	return p, nil
+}
+
+// Glob returns the files matching any of the patterns. Patterns with a
+// slash match the whole relative path, others only files at the root.
+// Matching ignores case, as README.md and readme.md are the same to
+// readers.
+func (p *Project) Glob(patterns ...string) []string {
+	var matches []string
+	for _, file := range p.Files {
+		for _, pattern := range patterns {
+			if ok, _ := path.Match(strings.ToLower(pattern), strings.ToLower(file)); ok {
+				matches = append(matches, file)
+				break
+			}
+		}
+	}
+	return matches
+}
+
+// ReadFile reads a file of the project by its relative path.
+func (p *Project) ReadFile(rel string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(p.Path, filepath.FromSlash(rel)))
+}
+
+// Run checks the project with every rule the configuration doesn't
+// disable.
+func Run(p *Project) Report {
+	report := Report{Name: filepath.Base(p.Path), Path: p.Path, Type: p.Type, Results: []Result{}}
+	disabled := make(map[string]bool)
+	for _, id := range p.Config.DisabledRules {
+		disabled[id] = true
+	}
+
+	for _, rule := range Rules(p.Config) {
+		if disabled[rule.ID] {
+			continue
+		}
+		outcome := rule.Check(p)
+		result := Result{Rule: rule.ID, Description: rule.Description, Status: Pass}
+		switch {
+		case outcome.Skip != "":
+			result.Status = Skip
+			result.Messages = []string{outcome.Skip}
+		case len(outcome.Problems) > 0:
+			result.Status = rule.Severity
+			if severity, ok := p.Config.Severity[rule.ID]; ok {
+				result.Status = Status(severity)
+			}
+			result.Messages = append(outcome.Problems, outcome.Warnings...)
+		case len(outcome.Warnings) > 0:
+			result.Status = Warn
+			result.Messages = outcome.Warnings
+		case outcome.Detail != "":
+			result.Messages = []string{outcome.Detail}
+		}
+
+		switch result.Status {
+		case Pass:
+			report.Summary.Passed++
+		case Warn:
+			report.Summary.Warnings++
+		case Fail:
+			report.Summary.Failed++
+		case Skip:
+			report.Summary.Skipped++
+		}
+		report.Results = append(report.Results, result)
+	}
+	return report
+}
+
+// LoadConfig merges the project's .doctor.json, if it has one, over the
+// global configuration: lists are appended and project values win.
+func LoadConfig(projectPath string, global config.DoctorConfig) (config.DoctorConfig, error) {
+	data, err := os.ReadFile(filepath.Join(projectPath, ProjectConfigFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return global, validateConfig(global)
+	}
+	if err != nil {
+		return global, err
+	}
+	var local config.DoctorConfig
+	if err := json.Unmarshal(data, &local); err != nil {
+		return global, fmt.Errorf("failed to parse %s: %v", ProjectConfigFile, err)
+	}
+
+	merged := global
+	merged.DisabledRules = append(append([]string(nil), global.DisabledRules...), local.DisabledRules...)
+	merged.Require = append(append([]config.RequiredFileRule(nil), global.Require...), local.Require...)
+	merged.Severity = mergeMaps(global.Severity, local.Severity)
+	merged.Actions = mergeMaps(global.Actions, local.Actions)
+	if local.GoVersion != "" {
+		merged.GoVersion = local.GoVersion
+	}
+	if local.License != "" {
+		merged.License = local.License
+	}
+	return merged, validateConfig(merged)
+}
+
+func mergeMaps[V any](a, b map[string]V) map[string]V {
+	merged := make(map[string]V, len(a)+len(b))
+	for k, v := range a {
+		merged[k] = v
+	}
+	for k, v := range b {
+		merged[k] = v
+	}
+	return merged
+}
+
+func validateConfig(cfg config.DoctorConfig) error {
+	for id, severity := range cfg.Severity {
+		if Status(severity) != Warn && Status(severity) != Fail {
+			return fmt.Errorf("invalid severity %q for rule %s, expected warn or fail", severity, id)
+		}
+	}
+	for _, r := range cfg.Require {
+		if r.ID == "" || len(r.Paths) == 0 {
+			return fmt.Errorf("required file rules need an id and paths")
+		}
+		if r.Severity != "" && Status(r.Severity) != Warn && Status(r.Severity) != Fail {
+			return fmt.Errorf("invalid severity %q for rule %s, expected warn or fail", r.Severity, r.ID)
+		}
+	}
+	return nil
+}
+
+// requiredFileRule turns a rule from the configuration into one that fails
+// unless one of its files exists.
+func requiredFileRule(r config.RequiredFileRule) Rule {
+	severity := Fail
+	if r.Severity != "" {
+		severity = Status(r.Severity)
+	}
+	description := r.Description
+	if description == "" {
+		description = strings.Join(r.Paths, " or ") + " present"
+	}
+	return Rule{
+		ID:          r.ID,
+		Description: description,
+		Severity:    severity,
+		Check: func(p *Project) Outcome {
+			if found := p.Glob(r.Paths...); len(found) > 0 {
+				return Outcome{Detail: found[0]}
+			}
+			return Outcome{Problems: []string{"none of " + strings.Join(r.Paths, ", ") + " found"}}
+		},
+	}
+}
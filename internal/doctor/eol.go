@@ -0,0 +1,203 @@
+package doctor
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// eolWarningMonths is how long before end of life images are warned about.
+const eolWarningMonths = 6
+
+// nodeEOL are the end of life dates of Node.js major versions. Odd versions
+// are supported for about eight months.
+var nodeEOL = map[int]string{
+	12: "2022-04-30", 13: "2020-06-01", 14: "2023-04-30", 15: "2021-06-01",
+	16: "2023-09-11", 17: "2022-06-01", 18: "2025-04-30", 19: "2023-06-01",
+	20: "2026-04-30", 21: "2024-06-01", 22: "2027-04-30", 23: "2025-06-01",
+	24: "2028-04-30", 25: "2026-06-01",
+}
+
+// pythonEOL are the end of life dates of Python 3 minor versions.
+var pythonEOL = map[int]string{
+	6: "2021-12-23", 7: "2023-06-27", 8: "2024-10-07", 9: "2025-10-31",
+	10: "2026-10-31", 11: "2027-10-31", 12: "2028-10-31", 13: "2029-10-31",
+	14: "2030-10-31",
+}
+
+// goRelease returns when Go 1.minor was released. Releases come out every
+// February (even minors) and August (odd minors) since Go 1.6 in 2016.
+func goRelease(minor int) time.Time {
+	month := time.February
+	if minor%2 == 1 {
+		month = time.August
+	}
+	return time.Date(2013+minor/2, month, 15, 0, 0, 0, 0, time.UTC)
+}
+
+// goEOL returns when Go 1.minor stopped being supported, which is when the
+// second release after it comes out.
+func goEOL(minor int) time.Time {
+	return goRelease(minor + 2)
+}
+
+// oldestSupportedGo returns the oldest Go release supported at now, such as
+// "1.26".
+func oldestSupportedGo(now time.Time) string {
+	return fmt.Sprintf("1.%d", latestGo(now)-1)
+}
+
+// latestGo returns the minor version of the latest Go release at now.
+func latestGo(now time.Time) int {
+	latest := 6
+	for !goRelease(latest + 1).After(now) {
+		latest++
+	}
+	return latest
+}
+
+// BaseImage is an image a Dockerfile builds FROM.
+type BaseImage struct {
+	Line int
+	// Name is the image without registry or tag, such as golang.
+	Name string
+	Tag  string
+}
+
+// officialImage strips the registry prefixes of Docker Hub's official
+// images, which are the ones with known release dates.
+var officialImage = regexp.MustCompile(`^(?:(?:docker\.io|index\.docker\.io)/)?(?:library/)?([a-z0-9._-]+)$`)
+
+// BaseImages returns the official images in the FROM lines of a
+// Dockerfile. Stages built from other stages, images set by build
+// arguments and images from other registries are left out.
+func BaseImages(dockerfile []byte) []BaseImage {
+	var images []BaseImage
+	scanner := bufio.NewScanner(bytes.NewReader(dockerfile))
+	for n := 1; scanner.Scan(); n++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || !strings.EqualFold(fields[0], "FROM") {
+			continue
+		}
+		// Skip flags like --platform=linux/amd64
+		ref := ""
+		for _, f := range fields[1:] {
+			if !strings.HasPrefix(f, "--") {
+				ref = f
+				break
+			}
+		}
+		if ref == "" || strings.Contains(ref, "$") {
+			continue
+		}
+		ref, _, _ = strings.Cut(ref, "@")
+		name, tag, ok := strings.Cut(ref, ":")
+		match := officialImage.FindStringSubmatch(name)
+		if !ok || match == nil {
+			continue
+		}
+		images = append(images, BaseImage{Line: n, Name: match[1], Tag: tag})
+	}
+	return images
+}
+
+// tagVersion matches the version at the start of a tag like 1.16-alpine.
+var tagVersion = regexp.MustCompile(`^(\d+)(?:\.(\d+))?`)
+
+// imageEOL returns the end of life date of an official golang, node or
+// python image tag. It returns false for other images and for tags
+// without a version, such as latest or alpine.
+func imageEOL(name, tag string) (time.Time, bool) {
+	match := tagVersion.FindStringSubmatch(tag)
+	if match == nil {
+		return time.Time{}, false
+	}
+	major, _ := strconv.Atoi(match[1])
+	minor, hasMinor := -1, match[2] != ""
+	if hasMinor {
+		minor, _ = strconv.Atoi(match[2])
+	}
+
+	switch name {
+	case "golang":
+		// golang:1 always points to the latest release
+		if major != 1 || !hasMinor {
+			return time.Time{}, false
+		}
+		return goEOL(minor), true
+	case "node":
+		return lookupEOL(nodeEOL, major)
+	case "python":
+		if major == 2 {
+			return time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC), true
+		}
+		if major != 3 || !hasMinor {
+			return time.Time{}, false
+		}
+		return lookupEOL(pythonEOL, minor)
+	}
+	return time.Time{}, false
+}
+
+// lookupEOL finds version in table. Versions older than any in the table
+// reached end of life before its oldest; newer ones are unknown.
+func lookupEOL(table map[int]string, version int) (time.Time, bool) {
+	oldest := -1
+	for v := range table {
+		if oldest < 0 || v < oldest {
+			oldest = v
+		}
+	}
+	if version < oldest {
+		version = oldest
+	}
+	date, ok := table[version]
+	if !ok {
+		return time.Time{}, false
+	}
+	eol, err := time.Parse("2006-01-02", date)
+	return eol, err == nil
+}
+
+// supportedImageVersion returns the version to move an official golang,
+// node or python image to at now: the latest Go release, the active
+// Node.js LTS release, or the newest Python release. Versions that reach
+// end of life within eolWarningMonths are passed over.
+func supportedImageVersion(name string, now time.Time) (string, bool) {
+	switch name {
+	case "golang":
+		return fmt.Sprintf("1.%d", latestGo(now)), true
+	case "node":
+		// Even majors become LTS in October of their release year
+		lts := newestSupported(nodeEOL, now, func(major int) (time.Time, bool) {
+			return time.Date(2013+major/2, time.October, 15, 0, 0, 0, 0, time.UTC), major%2 == 0
+		})
+		return strconv.Itoa(lts), lts > 0
+	case "python":
+		// Python releases are supported for five years
+		minor := newestSupported(pythonEOL, now, func(minor int) (time.Time, bool) {
+			eol, _ := lookupEOL(pythonEOL, minor)
+			return eol.AddDate(-5, 0, 0), true
+		})
+		return fmt.Sprintf("3.%d", minor), minor > 0
+	}
+	return "", false
+}
+
+// newestSupported returns the newest version in table released at now and
+// supported for at least eolWarningMonths, or 0.
+func newestSupported(table map[int]string, now time.Time, released func(int) (time.Time, bool)) int {
+	newest := 0
+	for version := range table {
+		release, ok := released(version)
+		eol, known := lookupEOL(table, version)
+		if ok && known && !release.After(now) && now.AddDate(0, eolWarningMonths, 0).Before(eol) && version > newest {
+			newest = version
+		}
+	}
+	return newest
+}
@@ -0,0 +1,262 @@
+package doctor
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"project-starter/internal/setup"
+)
+
+// Change is a file a fix rewrites or creates.
+type Change struct {
+	Rule string
+	// Path is relative to the project with forward slashes.
+	Path string
+	// Old is the current content, nil when the file doesn't exist.
+	Old []byte
+	New []byte
+}
+
+// Unfixed is a finding its rule can usually fix but not in this project,
+// such as a configured license setup has no text for.
+type Unfixed struct {
+	Rule   string
+	Reason string
+}
+
+// skipFix is returned by fixes that leave the finding to the user.
+type skipFix string
+
+func (s skipFix) Error() string { return string(s) }
+
+// Fixes returns the changes that fix the warnings and failures in the
+// report, for the rules that can fix them, and the findings that were left
+// alone. Nothing is written.
+func Fixes(p *Project, report Report) ([]Change, []Unfixed, error) {
+	failing := make(map[string]bool)
+	for _, r := range report.Results {
+		if r.Status == Warn || r.Status == Fail {
+			failing[r.Rule] = true
+		}
+	}
+
+	var changes []Change
+	var skipped []Unfixed
+	changed := make(map[string]string)
+	for _, rule := range Rules(p.Config) {
+		if !failing[rule.ID] || rule.Fix == nil {
+			continue
+		}
+		fixes, err := rule.Fix(p)
+		var skip skipFix
+		if errors.As(err, &skip) {
+			skipped = append(skipped, Unfixed{Rule: rule.ID, Reason: string(skip)})
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to fix %s: %v", rule.ID, err)
+		}
+		for _, c := range fixes {
+			if other, ok := changed[c.Path]; ok {
+				return nil, nil, fmt.Errorf("rules %s and %s both change %s", other, rule.ID, c.Path)
+			}
+			changed[c.Path] = rule.ID
+			c.Rule = rule.ID
+			changes = append(changes, c)
+		}
+	}
+	return changes, skipped, nil
+}
+
+// Apply writes the changes. A file that changed since its fix was made is
+// left alone, so edits made while the diff was reviewed aren't lost.
+func Apply(p *Project, changes []Change) error {
+	for _, c := range changes {
+		path := filepath.Join(p.Path, filepath.FromSlash(c.Path))
+		perm := os.FileMode(0644)
+		current, err := os.ReadFile(path)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			if c.Old != nil {
+				return fmt.Errorf("%s was removed since it was checked", c.Path)
+			}
+		case err != nil:
+			return err
+		case c.Old == nil || !bytes.Equal(current, c.Old):
+			return fmt.Errorf("%s changed since it was checked", c.Path)
+		default:
+			if info, err := os.Stat(path); err == nil {
+				perm = info.Mode().Perm()
+			}
+		}
+
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, c.New, perm); err != nil {
+			return fmt.Errorf("failed to write %s: %v", c.Path, err)
+		}
+	}
+	return nil
+}
+
+// fixPresent writes content to the first of the found files when it is
+// empty, or to name when there are none.
+func fixPresent(p *Project, patterns []string, name string, content string) ([]Change, error) {
+	for _, file := range p.Glob(patterns...) {
+		data, err := p.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		if len(bytes.TrimSpace(data)) > 0 {
+			return nil, nil
+		}
+		return []Change{{Path: file, Old: data, New: []byte(content)}}, nil
+	}
+	return []Change{{Path: name, New: []byte(content)}}, nil
+}
+
+func fixReadme(p *Project) ([]Change, error) {
+	return fixPresent(p, readmeFiles, "README.md", setup.GetReadmeContent(p.Path, filepath.Base(p.Path), p.Type))
+}
+
+func fixLicense(p *Project) ([]Change, error) {
+	license := p.Config.License
+	if license == "" {
+		license = "MIT"
+	}
+	if !slices.Contains(setup.Licenses, license) {
+		return nil, skipFix(fmt.Sprintf("no text for the %s license, add LICENSE by hand or use one of %s", license, strings.Join(setup.Licenses, ", ")))
+	}
+	content, err := setup.GetLicenseContent(license, p.Now.Year(), setup.DefaultCopyrightHolder(p.Path))
+	if err != nil {
+		return nil, err
+	}
+	return fixPresent(p, licenseFiles, "LICENSE", content)
+}
+
+// fixGitignore appends the missing entries to .gitignore. A project without
+// one gets the .gitignore created projects start with.
+func fixGitignore(p *Project) ([]Change, error) {
+	old, err := p.ReadFile(".gitignore")
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	content := string(old)
+	if old == nil {
+		// Types setup has no .gitignore for get the placeholder
+		if template := setup.GetGitignoreContent(p.Type); template != setup.GetGitignoreContent("") {
+			content = template
+		}
+	}
+
+	missing := missingGitignoreEntries(p, []byte(content))
+	if len(missing) > 0 {
+		if content != "" && !strings.HasSuffix(content, "\n") {
+			content += "\n"
+		}
+		if content != "" {
+			content += "\n"
+		}
+		content += "# Build output and dependencies\n"
+		for _, entry := range missing {
+			content += entry.Line + "\n"
+		}
+	}
+	if content == string(old) {
+		return nil, nil
+	}
+	return []Change{{Path: ".gitignore", Old: old, New: []byte(content)}}, nil
+}
+
+// fixActions moves deprecated actions to their oldest supported major.
+func fixActions(p *Project) ([]Change, error) {
+	byFile := make(map[string][]OutdatedAction)
+	for _, a := range OutdatedActions(p) {
+		byFile[a.File] = append(byFile[a.File], a)
+	}
+	return rewriteLines(p, byFile, func(line string, a OutdatedAction) (string, bool) {
+		match := usesPattern.FindStringSubmatchIndex(line)
+		if match == nil {
+			return line, false
+		}
+		return line[:match[4]] + fmt.Sprint(a.Minimum) + line[match[5]:], true
+	})
+}
+
+// imageVersion matches the whole version at the start of a tag, such as
+// 1.16.5 in 1.16.5-alpine.
+var imageVersion = regexp.MustCompile(`^\d+(?:\.\d+)*`)
+
+// fixDockerImages moves base images that are or will soon be end of life
+// to a supported version, keeping the variant such as -alpine. Images
+// pinned by digest are left alone, as the digest would still pin the old
+// image.
+func fixDockerImages(p *Project) ([]Change, error) {
+	byFile := make(map[string][]BaseImage)
+	for _, file := range p.Glob(dockerfiles...) {
+		data, err := p.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		for _, image := range BaseImages(data) {
+			eol, ok := imageEOL(image.Name, image.Tag)
+			if ok && p.Now.AddDate(0, eolWarningMonths, 0).After(eol) {
+				byFile[file] = append(byFile[file], image)
+			}
+		}
+	}
+	return rewriteLines(p, byFile, func(line string, image BaseImage) (string, bool) {
+		version, ok := supportedImageVersion(image.Name, p.Now)
+		if !ok || strings.Contains(line, "@sha256:") {
+			return line, false
+		}
+		tag := imageVersion.ReplaceAllLiteralString(image.Tag, version)
+		return strings.Replace(line, ":"+image.Tag, ":"+tag, 1), true
+	})
+}
+
+// rewriteLines applies fix to the numbered lines of each file, keeping
+// line endings, and returns a change for each file it altered.
+func rewriteLines[T interface{ line() int }](p *Project, byFile map[string][]T, fix func(line string, item T) (string, bool)) ([]Change, error) {
+	files := make([]string, 0, len(byFile))
+	for file := range byFile {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	var changes []Change
+	for _, file := range files {
+		old, err := p.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		lines := strings.SplitAfter(string(old), "\n")
+		altered := false
+		for _, item := range byFile[file] {
+			i := item.line() - 1
+			if i < 0 || i >= len(lines) {
+				continue
+			}
+			if line, ok := fix(lines[i], item); ok && line != lines[i] {
+				lines[i] = line
+				altered = true
+			}
+		}
+		if altered {
+			changes = append(changes, Change{Path: file, Old: old, New: []byte(strings.Join(lines, ""))})
+		}
+	}
+	return changes, nil
+}
+
+func (a OutdatedAction) line() int { return a.Line }
+
+func (b BaseImage) line() int { return b.Line }
@@ -0,0 +1,403 @@
+package doctor
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+
+	"project-starter/internal/ignore"
+)
+
+var builtinRules = []Rule{
+	{ID: "readme", Description: "README present", Severity: Fail, Check: checkReadme, Fix: fixReadme},
+	{ID: "license", Description: "LICENSE present", Severity: Warn, Check: checkLicense, Fix: fixLicense},
+	{ID: "gitignore", Description: ".gitignore covers build output", Severity: Warn, Check: checkGitignore, Fix: fixGitignore},
+	{ID: "ci", Description: "CI workflow exists", Severity: Warn, Check: checkCI},
+	{ID: "ci-actions", Description: "CI workflows use supported action versions", Severity: Fail, Check: checkActions, Fix: fixActions},
+	{ID: "docker-image", Description: "Dockerfile base images are not end of life", Severity: Fail, Check: checkDockerImages, Fix: fixDockerImages},
+	{ID: "go-version", Description: "go.mod declares a supported Go version", Severity: Warn, Check: checkGoVersion},
+	{ID: "tests", Description: "tests present and not empty", Severity: Fail, Check: checkTests},
+}
+
+var (
+	readmeFiles  = []string{"README", "README.*"}
+	licenseFiles = []string{"LICENSE", "LICENSE.*", "LICENCE", "LICENCE.*", "COPYING", "COPYING.*"}
+)
+
+func checkReadme(p *Project) Outcome {
+	return checkPresent(p, readmeFiles, "no README")
+}
+
+func checkLicense(p *Project) Outcome {
+	return checkPresent(p, licenseFiles, "no LICENSE file")
+}
+
+// checkPresent passes when one of the files exists and isn't empty.
+func checkPresent(p *Project, patterns []string, missing string) Outcome {
+	found := p.Glob(patterns...)
+	if len(found) == 0 {
+		return Outcome{Problems: []string{missing}}
+	}
+	for _, file := range found {
+		if data, err := p.ReadFile(file); err == nil && len(bytes.TrimSpace(data)) > 0 {
+			return Outcome{Detail: file}
+		}
+	}
+	return Outcome{Problems: []string{found[0] + " is empty"}}
+}
+
+// GitignoreEntry is a pattern a project's .gitignore should have, with a
+// path it must match to cover what the pattern is for.
+type GitignoreEntry struct {
+	Line   string
+	Sample string
+	Dir    bool
+}
+
+// gitignoreEntries are the dependencies and build output of each project
+// type, which don't belong in a repository.
+var gitignoreEntries = map[string][]GitignoreEntry{
+	"Go":      {{"*.exe", "app.exe", false}, {"*.test", "app.test", false}},
+	"Next.js": {{"/node_modules", "node_modules", true}, {"/.next/", ".next", true}, {"/out/", "out", true}},
+	"Vite":    {{"node_modules", "node_modules", true}, {"dist", "dist", true}},
+	"Vue":     {{"node_modules", "node_modules", true}, {"dist", "dist", true}},
+	"Node.js": {{"node_modules", "node_modules", true}},
+	"Rust":    {{"/target", "target", true}},
+	"Python":  {{"__pycache__/", "__pycache__", true}, {".venv/", ".venv", true}},
+	"Maven":   {{"target/", "target", true}},
+	"Gradle":  {{"build/", "build", true}, {".gradle/", ".gradle", true}},
+	".NET":    {{"bin/", "bin", true}, {"obj/", "obj", true}},
+	"Ruby":    {{"/.bundle", ".bundle", true}},
+	"PHP":     {{"/vendor/", "vendor", true}},
+}
+
+// expectedGitignoreEntries returns the entries for the project's type. Java
+// projects build into target with Maven and build with Gradle.
+func expectedGitignoreEntries(p *Project) ([]GitignoreEntry, bool) {
+	if p.Type != "Java" {
+		entries, ok := gitignoreEntries[p.Type]
+		return entries, ok
+	}
+	var entries []GitignoreEntry
+	if len(p.Glob("pom.xml")) > 0 {
+		entries = append(entries, gitignoreEntries["Maven"]...)
+	}
+	if len(p.Glob("build.gradle", "build.gradle.kts")) > 0 {
+		entries = append(entries, gitignoreEntries["Gradle"]...)
+	}
+	return entries, true
+}
+
+// MissingGitignoreEntries returns the entries the project's .gitignore
+// lacks for its type.
+func MissingGitignoreEntries(p *Project) ([]GitignoreEntry, error) {
+	data, err := os.ReadFile(filepath.Join(p.Path, ".gitignore"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	return missingGitignoreEntries(p, data), nil
+}
+
+// missingGitignoreEntries returns the entries the .gitignore content lacks
+// for the project's type.
+func missingGitignoreEntries(p *Project, gitignore []byte) []GitignoreEntry {
+	m := ignore.New(strings.Split(string(gitignore), "\n"))
+	entries, _ := expectedGitignoreEntries(p)
+	var missing []GitignoreEntry
+	for _, entry := range entries {
+		if !m.Match(entry.Sample, entry.Dir) {
+			missing = append(missing, entry)
+		}
+	}
+	return missing
+}
+
+func checkGitignore(p *Project) Outcome {
+	if _, ok := expectedGitignoreEntries(p); !ok {
+		return Outcome{Skip: "unknown project type"}
+	}
+	if len(p.Glob(".gitignore")) == 0 {
+		return Outcome{Problems: []string{"no .gitignore"}}
+	}
+	missing, err := MissingGitignoreEntries(p)
+	if err != nil {
+		return Outcome{Problems: []string{err.Error()}}
+	}
+	var problems []string
+	for _, entry := range missing {
+		problems = append(problems, fmt.Sprintf("%s is not ignored, add %q", entry.Sample, entry.Line))
+	}
+	return Outcome{Problems: problems}
+}
+
+var (
+	workflowFiles = []string{".github/workflows/*.yml", ".github/workflows/*.yaml"}
+	// otherCIFiles configure CI systems other than GitHub Actions.
+	otherCIFiles = []string{".gitlab-ci.yml", ".circleci/config.yml", "azure-pipelines.yml", "Jenkinsfile", ".travis.yml", "bitbucket-pipelines.yml"}
+)
+
+func checkCI(p *Project) Outcome {
+	if found := p.Glob(append(workflowFiles, otherCIFiles...)...); len(found) > 0 {
+		return Outcome{Detail: strings.Join(found, ", ")}
+	}
+	return Outcome{Problems: []string{"no CI configuration, such as .github/workflows/ci.yml"}}
+}
+
+// ActionMajors are the oldest major versions of common actions that still
+// run on a supported Node.js runtime. Older ones are deprecated and
+// eventually stop working.
+var ActionMajors = map[string]int{
+	"actions/checkout":          4,
+	"actions/setup-go":          5,
+	"actions/setup-node":        4,
+	"actions/setup-python":      5,
+	"actions/setup-java":        4,
+	"actions/cache":             4,
+	"actions/upload-artifact":   4,
+	"actions/download-artifact": 4,
+}
+
+// actionMajors returns the built-in minimum versions with the configured
+// ones applied.
+func actionMajors(p *Project) map[string]int {
+	return mergeMaps(ActionMajors, p.Config.Actions)
+}
+
+// usesPattern matches a step like "uses: actions/checkout@v2", capturing the
+// action, without a subdirectory, and its major version.
+var usesPattern = regexp.MustCompile(`^\s*-?\s*uses:\s*["']?([\w.-]+/[\w.-]+)(?:/[^@\s"']*)?@v(\d+)\b`)
+
+// OutdatedAction is a step using a deprecated action version.
+type OutdatedAction struct {
+	File    string
+	Line    int
+	Action  string
+	Version int
+	Minimum int
+}
+
+// OutdatedActions returns the steps of the project's GitHub workflows that
+// use deprecated action versions.
+func OutdatedActions(p *Project) []OutdatedAction {
+	minimum := actionMajors(p)
+	var outdated []OutdatedAction
+	for _, file := range p.Glob(workflowFiles...) {
+		data, err := p.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		for n := 1; scanner.Scan(); n++ {
+			match := usesPattern.FindStringSubmatch(scanner.Text())
+			if match == nil {
+				continue
+			}
+			version, _ := strconv.Atoi(match[2])
+			if min, ok := minimum[match[1]]; ok && version < min {
+				outdated = append(outdated, OutdatedAction{File: file, Line: n, Action: match[1], Version: version, Minimum: min})
+			}
+		}
+	}
+	return outdated
+}
+
+func checkActions(p *Project) Outcome {
+	if len(p.Glob(workflowFiles...)) == 0 {
+		return Outcome{Skip: "no GitHub Actions workflows"}
+	}
+	var problems []string
+	for _, a := range OutdatedActions(p) {
+		problems = append(problems, fmt.Sprintf("%s:%d: %s@v%d is deprecated, use v%d", a.File, a.Line, a.Action, a.Version, a.Minimum))
+	}
+	return Outcome{Problems: problems}
+}
+
+var dockerfiles = []string{"Dockerfile", "*.Dockerfile", "Dockerfile.*", "*/Dockerfile"}
+
+func checkDockerImages(p *Project) Outcome {
+	files := p.Glob(dockerfiles...)
+	if len(files) == 0 {
+		return Outcome{Skip: "no Dockerfile"}
+	}
+	var outcome Outcome
+	checked := 0
+	for _, file := range files {
+		data, err := p.ReadFile(file)
+		if err != nil {
+			outcome.Problems = append(outcome.Problems, err.Error())
+			continue
+		}
+		for _, image := range BaseImages(data) {
+			eol, ok := imageEOL(image.Name, image.Tag)
+			if !ok {
+				continue
+			}
+			checked++
+			where := fmt.Sprintf("%s:%d: %s:%s", file, image.Line, image.Name, image.Tag)
+			switch {
+			case !p.Now.Before(eol):
+				outcome.Problems = append(outcome.Problems, fmt.Sprintf("%s reached end of life on %s", where, eol.Format("2006-01-02")))
+			case p.Now.AddDate(0, eolWarningMonths, 0).After(eol):
+				outcome.Warnings = append(outcome.Warnings, fmt.Sprintf("%s reaches end of life on %s", where, eol.Format("2006-01-02")))
+			}
+		}
+	}
+	if checked == 0 && len(outcome.Problems) == 0 {
+		return Outcome{Skip: "no base images with known release dates"}
+	}
+	return outcome
+}
+
+// goDirective matches the go line of go.mod.
+var goDirective = regexp.MustCompile(`(?m)^go\s+(\d+\.\d+(?:\.\d+)?)\s*$`)
+
+func checkGoVersion(p *Project) Outcome {
+	data, err := p.ReadFile("go.mod")
+	if err != nil {
+		return Outcome{Skip: "no go.mod"}
+	}
+	match := goDirective.FindSubmatch(data)
+	if match == nil {
+		return Outcome{Problems: []string{"go.mod has no go directive"}}
+	}
+	declared, err := semver.NewVersion(string(match[1]))
+	if err != nil {
+		return Outcome{Problems: []string{fmt.Sprintf("invalid go directive %s", match[1])}}
+	}
+
+	minimum := p.Config.GoVersion
+	if minimum == "" {
+		minimum = oldestSupportedGo(p.Now)
+	}
+	oldest, err := semver.NewVersion(minimum)
+	if err != nil {
+		return Outcome{Problems: []string{fmt.Sprintf("invalid go_version %q in configuration", minimum)}}
+	}
+	if declared.LessThan(oldest) {
+		return Outcome{Problems: []string{fmt.Sprintf("go.mod declares go %s, older than %s", match[1], minimum)}}
+	}
+	return Outcome{Detail: "go " + string(match[1])}
+}
+
+// testSuites recognize test files and whether they test anything, by
+// project type.
+var testSuites = map[string]struct {
+	isTest   func(rel string) bool
+	hasTests func(rel string, data []byte) bool
+}{
+	"Go":      {isGoTest, goHasTests},
+	"Next.js": {isJSTest, containsAssertion},
+	"Vite":    {isJSTest, containsAssertion},
+	"Vue":     {isJSTest, containsAssertion},
+	"Node.js": {isJSTest, containsAssertion},
+	"Rust":    {isRustTest, rustHasTests},
+	"Python":  {isPythonTest, containsAssertion},
+	"Java":    {isJavaTest, containsAssertion},
+	".NET":    {isDotNetTest, containsAssertion},
+	"Ruby":    {isRubyTest, containsAssertion},
+	"PHP":     {isPHPTest, containsAssertion},
+}
+
+func checkTests(p *Project) Outcome {
+	suite, ok := testSuites[p.Type]
+	if !ok {
+		return Outcome{Skip: "unknown project type"}
+	}
+	var files []string
+	for _, file := range p.Files {
+		if suite.isTest(file) {
+			files = append(files, file)
+		}
+	}
+	if len(files) == 0 {
+		return Outcome{Problems: []string{"no test files"}}
+	}
+	for _, file := range files {
+		if data, err := p.ReadFile(file); err == nil && suite.hasTests(file, data) {
+			return Outcome{Detail: fmt.Sprintf("%d test files", len(files))}
+		}
+	}
+	return Outcome{Problems: []string{fmt.Sprintf("no test has a body or assertion, in %s or any other test file", files[0])}}
+}
+
+func isGoTest(rel string) bool {
+	return strings.HasSuffix(rel, "_test.go")
+}
+
+// goHasTests reports whether a Go test file has a test function with a
+// body, so the placeholder TestSample doesn't count.
+func goHasTests(rel string, data []byte) bool {
+	file, err := parser.ParseFile(token.NewFileSet(), rel, data, parser.SkipObjectResolution)
+	if err != nil {
+		return false
+	}
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv != nil || fn.Body == nil || len(fn.Body.List) == 0 {
+			continue
+		}
+		for _, prefix := range []string{"Test", "Benchmark", "Fuzz", "Example"} {
+			if strings.HasPrefix(fn.Name.Name, prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// rustHasTests reports whether a Rust file has a test that asserts
+// something. Unit tests live next to the code, so every file is checked.
+func rustHasTests(rel string, data []byte) bool {
+	return bytes.Contains(data, []byte("#[test]")) && containsAssertion(rel, data)
+}
+
+var jsTestFile = regexp.MustCompile(`(^|/)(__tests__/.*\.[cm]?[jt]sx?|[^/]+\.(test|spec)\.[cm]?[jt]sx?)$`)
+
+func isJSTest(rel string) bool {
+	return jsTestFile.MatchString(rel)
+}
+
+func isRustTest(rel string) bool {
+	return strings.HasSuffix(rel, ".rs")
+}
+
+func isPythonTest(rel string) bool {
+	name := path.Base(rel)
+	return strings.HasSuffix(name, ".py") && (strings.HasPrefix(name, "test_") || strings.HasSuffix(name, "_test.py"))
+}
+
+func isJavaTest(rel string) bool {
+	return strings.Contains(rel, "src/test/") && (strings.HasSuffix(rel, ".java") || strings.HasSuffix(rel, ".kt"))
+}
+
+func isDotNetTest(rel string) bool {
+	return strings.HasSuffix(rel, "Tests.cs") || strings.HasSuffix(rel, "Test.cs")
+}
+
+func isRubyTest(rel string) bool {
+	return strings.HasSuffix(rel, "_spec.rb") || strings.HasSuffix(rel, "_test.rb")
+}
+
+func isPHPTest(rel string) bool {
+	return strings.HasSuffix(rel, "Test.php")
+}
+
+// assertionPattern matches the assertions of common test frameworks, which
+// a test that checks something has.
+var assertionPattern = regexp.MustCompile(`\b(expect|assert\w*|Assert\.\w+|should|refute\w*)\b`)
+
+func containsAssertion(_ string, data []byte) bool {
+	return assertionPattern.Match(data)
+}
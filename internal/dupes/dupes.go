@@ -0,0 +1,269 @@
+package dupes
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"runtime"
+	"sort"
+	"sync"
+
+	"project-starter/internal/walk"
+)
+
+// partialSize is how much of a file the first hashing pass reads. Files of
+// the same size usually differ early on, so most are told apart without
+// reading them fully.
+const partialSize = 4096
+
+// file is a candidate duplicate. path is where it is on disk and name how
+// it is reported.
+type file struct {
+	path string
+	name string
+	info fs.FileInfo
+}
+
+// Set is a group of files with identical contents.
+type Set struct {
+	Size int64 `json:"size"`
+	// Hash is the hex SHA-256 of the contents.
+	Hash  string   `json:"hash"`
+	Files []string `json:"files"`
+}
+
+// Wasted is the space taken by all copies but one.
+func (s Set) Wasted() int64 {
+	return s.Size * int64(len(s.Files)-1)
+}
+
+// Root is a directory to search, with the name its files are reported
+// under, such as the project name.
+type Root struct {
+	Path string
+	Name string
+}
+
+// Options control Find.
+type Options struct {
+	// MinSize leaves out smaller files. Empty files are always left out.
+	MinSize int64
+	// Progress, if set, is called with the number of files hashed so far.
+	Progress func(hashed int)
+	// Skipped, if set, is called for files left out because they can't be
+	// read or were removed during the search. Other errors end it.
+	Skipped func(name string, err error)
+}
+
+// Find returns the sets of duplicate files below roots, with the most
+// wasted space first. Files are grouped by size, then by a hash of their
+// first bytes, and only files still matching are hashed in full. Hard links
+// to the same file and symbolic links are not duplicates, and .git
+// directories are skipped.
+func Find(roots []Root, opts Options) ([]Set, error) {
+	bySize := make(map[int64][]file)
+	for _, root := range roots {
+		err := walk.Walk(root.Path, walk.Options{
+			Skip: func(_ string, d fs.DirEntry) bool { return d.Name() == ".git" },
+		}, func(path, rel string, d fs.DirEntry) error {
+			if !d.Type().IsRegular() {
+				return nil
+			}
+			name := rel
+			if root.Name != "" {
+				name = root.Name + "/" + rel
+			}
+			info, err := d.Info()
+			if skippable(err) {
+				if opts.Skipped != nil {
+					opts.Skipped(name, err)
+				}
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if info.Size() == 0 || info.Size() < opts.MinSize {
+				return nil
+			}
+			bySize[info.Size()] = append(bySize[info.Size()], file{path: path, name: name, info: info})
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var candidates [][]file
+	for _, files := range bySize {
+		if files = withoutHardLinks(files); len(files) > 1 {
+			candidates = append(candidates, files)
+		}
+	}
+
+	h := &hasher{progress: opts.Progress, skipped: opts.Skipped}
+	var sets []Set
+	var large [][]file
+	for _, group := range h.split(candidates, partialSize) {
+		if group[0].info.Size() <= partialSize {
+			// The partial hash already covered the whole file
+			sets = append(sets, newSet(group, h.sums[group[0].path]))
+		} else {
+			large = append(large, group)
+		}
+	}
+	for _, group := range h.split(large, -1) {
+		sets = append(sets, newSet(group, h.sums[group[0].path]))
+	}
+	if h.err != nil {
+		return nil, h.err
+	}
+
+	sort.Slice(sets, func(i, j int) bool {
+		if sets[i].Wasted() != sets[j].Wasted() {
+			return sets[i].Wasted() > sets[j].Wasted()
+		}
+		return sets[i].Files[0] < sets[j].Files[0]
+	})
+	return sets, nil
+}
+
+func newSet(files []file, hash string) Set {
+	set := Set{Size: files[0].info.Size(), Hash: hash}
+	for _, f := range files {
+		set.Files = append(set.Files, f.name)
+	}
+	sort.Strings(set.Files)
+	return set
+}
+
+// withoutHardLinks keeps one name of files that are the same file on disk.
+func withoutHardLinks(files []file) []file {
+	var kept []file
+	for _, f := range files {
+		linked := false
+		for _, k := range kept {
+			if os.SameFile(f.info, k.info) {
+				linked = true
+				break
+			}
+		}
+		if !linked {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
+
+// skippable reports whether err only concerns the file it is about, so the
+// search can go on without it.
+func skippable(err error) bool {
+	return errors.Is(err, fs.ErrPermission) || errors.Is(err, fs.ErrNotExist)
+}
+
+// hasher hashes files with a pool of workers.
+type hasher struct {
+	progress func(hashed int)
+	skipped  func(name string, err error)
+	mu       sync.Mutex
+	// sums holds the latest hash of each file by path. Files that couldn't
+	// be read have none.
+	sums   map[string]string
+	hashed int
+	err    error
+}
+
+// split hashes the first limit bytes of every file in groups, or all of
+// them when limit is negative, and returns the groups of two or more files
+// with equal hashes.
+func (h *hasher) split(groups [][]file, limit int64) [][]file {
+	h.mu.Lock()
+	if h.sums == nil {
+		h.sums = make(map[string]string)
+	}
+	h.mu.Unlock()
+
+	files := make(chan file)
+	var workers sync.WaitGroup
+	for i := 0; i < runtime.NumCPU(); i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for f := range files {
+				sum, err := hashFile(f.path, limit)
+				h.mu.Lock()
+				switch {
+				case skippable(err):
+					delete(h.sums, f.path)
+					if h.skipped != nil {
+						h.skipped(f.name, err)
+					}
+				case err != nil:
+					if h.err == nil {
+						h.err = err
+					}
+				default:
+					h.sums[f.path] = sum
+				}
+				h.hashed++
+				if h.progress != nil {
+					h.progress(h.hashed)
+				}
+				h.mu.Unlock()
+			}
+		}()
+	}
+	for _, group := range groups {
+		for _, f := range group {
+			files <- f
+		}
+	}
+	close(files)
+	workers.Wait()
+	if h.err != nil {
+		return nil
+	}
+
+	var result [][]file
+	for _, group := range groups {
+		byHash := make(map[string][]file)
+		var order []string
+		for _, f := range group {
+			sum, ok := h.sums[f.path]
+			if !ok {
+				continue
+			}
+			if _, ok := byHash[sum]; !ok {
+				order = append(order, sum)
+			}
+			byHash[sum] = append(byHash[sum], f)
+		}
+		for _, sum := range order {
+			if len(byHash[sum]) > 1 {
+				result = append(result, byHash[sum])
+			}
+		}
+	}
+	return result
+}
+
+func hashFile(path string, limit int64) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if limit >= 0 {
+		r = io.LimitReader(f, limit)
+	}
+	hash := sha256.New()
+	if _, err := io.Copy(hash, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
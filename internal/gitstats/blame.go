@@ -0,0 +1,66 @@
+package gitstats
+
+import (
+	"bufio"
+	"bytes"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BlameLine is who last changed a line of a file.
+type BlameLine struct {
+	Author string
+	Email  string
+	Time   time.Time
+	// Committed is false for lines changed in the working tree, which have
+	// no author yet.
+	Committed bool
+}
+
+// InWorkTree reports whether dir is inside a git work tree. Unlike Read,
+// it accepts directories below the top level of a repository.
+func InWorkTree(dir string) bool {
+	out, err := git(dir, "rev-parse", "--is-inside-work-tree")
+	return err == nil && strings.TrimSpace(string(out)) == "true"
+}
+
+// Blame returns who last changed each line of the file at path, relative
+// to dir. Line n of the file is element n-1. Files git doesn't track, and
+// repositories without commits, return an error.
+func Blame(dir, path string) ([]BlameLine, error) {
+	out, err := git(dir, "blame", "--line-porcelain", "--", path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every line is a header "<commit> <orig-line> <line> [<lines>]",
+	// fields like "author <name>" and then the content after a tab
+	var lines []BlameLine
+	var current BlameLine
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	header := true
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "\t"):
+			lines = append(lines, current)
+			current = BlameLine{}
+			header = true
+		case header:
+			commit, _, _ := strings.Cut(line, " ")
+			current.Committed = strings.Trim(commit, "0") != ""
+			header = false
+		case strings.HasPrefix(line, "author "):
+			current.Author = strings.TrimPrefix(line, "author ")
+		case strings.HasPrefix(line, "author-mail "):
+			current.Email = strings.Trim(strings.TrimPrefix(line, "author-mail "), "<>")
+		case strings.HasPrefix(line, "author-time "):
+			if unix, err := strconv.ParseInt(strings.TrimPrefix(line, "author-time "), 10, 64); err == nil {
+				current.Time = time.Unix(unix, 0)
+			}
+		}
+	}
+	return lines, scanner.Err()
+}
@@ -0,0 +1,352 @@
+package gitstats
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Stats summarize the history and state of a git repository.
+type Stats struct {
+	Branch string `json:"branch"`
+	// Detached is set when HEAD is not a branch; Branch is then the
+	// abbreviated commit.
+	Detached bool `json:"detached"`
+	Dirty    bool `json:"dirty"`
+	// ChangedFiles counts modified, staged and untracked files.
+	ChangedFiles int `json:"changed_files"`
+	// Ahead and Behind are relative to the upstream branch, if there is one.
+	Ahead       int       `json:"ahead"`
+	Behind      int       `json:"behind"`
+	Upstream    string    `json:"upstream,omitempty"`
+	Commits     int       `json:"commits"`
+	FirstCommit time.Time `json:"first_commit"`
+	LastCommit  time.Time `json:"last_commit"`
+	// Contributors are sorted by commits, most first.
+	Contributors []Contributor `json:"contributors"`
+	// ActiveContributors have commits in the weekly window.
+	ActiveContributors int `json:"active_contributors"`
+	// WeeklyCommits counts commits per week, oldest first, ending with the
+	// current week.
+	WeeklyCommits []int `json:"weekly_commits"`
+	// TopChurn are the files with the most lines added and deleted.
+	TopChurn []FileChurn `json:"top_churn"`
+}
+
+type Contributor struct {
+	Name    string `json:"name"`
+	Email   string `json:"email"`
+	Commits int    `json:"commits"`
+}
+
+type FileChurn struct {
+	Path    string `json:"path"`
+	Added   int    `json:"added"`
+	Deleted int    `json:"deleted"`
+	Commits int    `json:"commits"`
+}
+
+func (f FileChurn) Churn() int {
+	return f.Added + f.Deleted
+}
+
+// topChurnFiles is how many files TopChurn lists.
+const topChurnFiles = 10
+
+// ErrNotRepository is returned for directories that are not the root of a
+// git repository, or when git is not installed.
+var ErrNotRepository = errors.New("not a git repository")
+
+// Read collects the statistics of the repository at dir, with weekly commit
+// counts for the last weeks weeks. It reads the repository with git itself,
+// so mailmap and other repository settings apply.
+func Read(dir string, weeks int) (*Stats, error) {
+	if weeks < 1 {
+		weeks = 1
+	}
+	if !isRepositoryRoot(dir) {
+		return nil, ErrNotRepository
+	}
+
+	stats := &Stats{}
+	if err := readStatus(dir, stats); err != nil {
+		return nil, err
+	}
+	// A repository without commits has no history to read
+	if _, err := git(dir, "rev-parse", "--verify", "-q", "HEAD"); err != nil {
+		return stats, nil
+	}
+	if err := readHistory(dir, weeks, time.Now(), stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// ReadStatus collects only the branch and working tree state of the
+// repository at dir, which is much cheaper than reading its history.
+func ReadStatus(dir string) (*Stats, error) {
+	if !isRepositoryRoot(dir) {
+		return nil, ErrNotRepository
+	}
+	stats := &Stats{}
+	if err := readStatus(dir, stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+func git(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return nil, fmt.Errorf("git %s: %v", args[0], err)
+	}
+	return out, nil
+}
+
+// isRepositoryRoot reports whether dir is the top level of a work tree. A
+// project inside a larger repository is not, since the history would be
+// that of the whole repository.
+func isRepositoryRoot(dir string) bool {
+	out, err := git(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return false
+	}
+	top, err := filepath.EvalSymlinks(strings.TrimSpace(string(out)))
+	if err != nil {
+		return false
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	if abs, err = filepath.EvalSymlinks(abs); err != nil {
+		return false
+	}
+	return filepath.Clean(top) == filepath.Clean(abs)
+}
+
+func readStatus(dir string, stats *Stats) error {
+	// The first line is "## branch...upstream [ahead 1, behind 2]"
+	out, err := git(dir, "status", "--porcelain", "--branch")
+	if err != nil {
+		return err
+	}
+	lines := strings.Split(strings.TrimRight(string(out), "\n"), "\n")
+	if len(lines) > 0 && strings.HasPrefix(lines[0], "## ") {
+		parseBranchLine(strings.TrimPrefix(lines[0], "## "), stats)
+		lines = lines[1:]
+	}
+	for _, line := range lines {
+		if line != "" {
+			stats.ChangedFiles++
+		}
+	}
+	stats.Dirty = stats.ChangedFiles > 0
+
+	if stats.Detached {
+		if out, err := git(dir, "rev-parse", "--short", "HEAD"); err == nil {
+			stats.Branch = strings.TrimSpace(string(out))
+		}
+	}
+	return nil
+}
+
+func parseBranchLine(line string, stats *Stats) {
+	line, tracking, _ := strings.Cut(line, " [")
+	switch {
+	case strings.HasPrefix(line, "HEAD (no branch)"):
+		stats.Detached = true
+	case strings.HasPrefix(line, "No commits yet on "):
+		stats.Branch = strings.TrimPrefix(line, "No commits yet on ")
+	default:
+		stats.Branch, stats.Upstream, _ = strings.Cut(line, "...")
+	}
+
+	for _, part := range strings.Split(strings.TrimSuffix(tracking, "]"), ", ") {
+		if n, ok := strings.CutPrefix(part, "ahead "); ok {
+			stats.Ahead, _ = strconv.Atoi(n)
+		}
+		if n, ok := strings.CutPrefix(part, "behind "); ok {
+			stats.Behind, _ = strconv.Atoi(n)
+		}
+	}
+}
+
+// commitMarker starts the header line of each commit in the log output, so
+// it can't be confused with the numstat lines that follow. The format string
+// asks git for it with %x00.
+const commitMarker = "\x00"
+
+func readHistory(dir string, weeks int, now time.Time, stats *Stats) error {
+	// The log of a large repository is big, so it is parsed as it streams
+	cmd := exec.Command("git", "log", "--no-renames", "--numstat", "--format=%x00%at%x09%aN%x09%aE", "HEAD")
+	cmd.Dir = dir
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("git log: %v", err)
+	}
+
+	windowStart := startOfWeek(now).AddDate(0, 0, -7*(weeks-1))
+	stats.WeeklyCommits = make([]int, weeks)
+	contributors := make(map[string]*Contributor)
+	active := make(map[string]bool)
+	churn := make(map[string]*FileChurn)
+
+	scanner := bufio.NewScanner(out)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if header, ok := strings.CutPrefix(line, commitMarker); ok {
+			fields := strings.SplitN(header, "\t", 3)
+			if len(fields) != 3 {
+				continue
+			}
+			unix, err := strconv.ParseInt(fields[0], 10, 64)
+			if err != nil {
+				continue
+			}
+			when := time.Unix(unix, 0)
+			stats.Commits++
+			if stats.FirstCommit.IsZero() || when.Before(stats.FirstCommit) {
+				stats.FirstCommit = when
+			}
+			if when.After(stats.LastCommit) {
+				stats.LastCommit = when
+			}
+
+			key := strings.ToLower(fields[2])
+			c, ok := contributors[key]
+			if !ok {
+				c = &Contributor{Name: fields[1], Email: fields[2]}
+				contributors[key] = c
+			}
+			c.Commits++
+
+			if !when.Before(windowStart) && !when.After(now) {
+				week := weeksBetween(windowStart, startOfWeek(when.In(now.Location())))
+				if week < weeks {
+					stats.WeeklyCommits[week]++
+				}
+				active[key] = true
+			}
+			continue
+		}
+
+		// numstat lines are "added<TAB>deleted<TAB>path", with "-" counts
+		// for binary files
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		f, ok := churn[fields[2]]
+		if !ok {
+			f = &FileChurn{Path: fields[2]}
+			churn[fields[2]] = f
+		}
+		added, _ := strconv.Atoi(fields[0])
+		deleted, _ := strconv.Atoi(fields[1])
+		f.Added += added
+		f.Deleted += deleted
+		f.Commits++
+	}
+	if err := scanner.Err(); err != nil {
+		// Git would block writing the rest of the log
+		cmd.Process.Kill()
+		cmd.Wait()
+		return err
+	}
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("git log: %v", err)
+	}
+
+	for _, c := range contributors {
+		stats.Contributors = append(stats.Contributors, *c)
+	}
+	sort.Slice(stats.Contributors, func(i, j int) bool {
+		a, b := stats.Contributors[i], stats.Contributors[j]
+		if a.Commits != b.Commits {
+			return a.Commits > b.Commits
+		}
+		return a.Name < b.Name
+	})
+	stats.ActiveContributors = len(active)
+
+	for _, f := range churn {
+		stats.TopChurn = append(stats.TopChurn, *f)
+	}
+	sort.Slice(stats.TopChurn, func(i, j int) bool {
+		a, b := stats.TopChurn[i], stats.TopChurn[j]
+		if a.Churn() != b.Churn() {
+			return a.Churn() > b.Churn()
+		}
+		return a.Path < b.Path
+	})
+	if len(stats.TopChurn) > topChurnFiles {
+		stats.TopChurn = stats.TopChurn[:topChurnFiles]
+	}
+	return nil
+}
+
+// startOfWeek returns midnight on the Monday of the week containing t.
+func startOfWeek(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, t.Location())
+}
+
+// weeksBetween returns how many weeks the Monday to is after the Monday
+// from. Dates are compared rather than durations, as weeks with a daylight
+// saving change are an hour shorter or longer.
+func weeksBetween(from, to time.Time) int {
+	fromYear, fromMonth, fromDay := from.Date()
+	toYear, toMonth, toDay := to.Date()
+	days := time.Date(toYear, toMonth, toDay, 0, 0, 0, 0, time.UTC).Sub(time.Date(fromYear, fromMonth, fromDay, 0, 0, 0, 0, time.UTC)) / (24 * time.Hour)
+	return int(days) / 7
+}
+
+var sparks = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline renders values as a line of block characters scaled to the
+// largest value.
+func Sparkline(values []int) string {
+	scaled := make([]int64, len(values))
+	var max int64
+	for i, v := range values {
+		scaled[i] = int64(v)
+		if scaled[i] > max {
+			max = scaled[i]
+		}
+	}
+	return SparklineRange(scaled, 0, max)
+}
+
+// SparklineRange renders values as a line of block characters, with low
+// as the lowest block and high as the highest. Values must be within the
+// range.
+func SparklineRange(values []int64, low, high int64) string {
+	var b strings.Builder
+	for _, v := range values {
+		i := 0
+		if high > low {
+			i = int((v - low) * int64(len(sparks)-1) / (high - low))
+		}
+		b.WriteRune(sparks[i])
+	}
+	return b.String()
+}
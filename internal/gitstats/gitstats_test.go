@@ -0,0 +1,34 @@
+package gitstats
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWeeksBetweenAcrossDaylightSaving(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("no time zone data: %v", err)
+	}
+	// Clocks went forward on Sunday 2024-03-31, so the week starting
+	// Monday 2024-03-25 was 167 hours long
+	windowStart := time.Date(2024, 3, 18, 0, 0, 0, 0, berlin)
+	tests := []struct {
+		when time.Time
+		want int
+	}{
+		{time.Date(2024, 3, 18, 0, 0, 0, 0, berlin), 0},
+		{time.Date(2024, 3, 24, 23, 59, 0, 0, berlin), 0},
+		{time.Date(2024, 3, 25, 0, 0, 0, 0, berlin), 1},
+		{time.Date(2024, 3, 31, 23, 59, 0, 0, berlin), 1},
+		{time.Date(2024, 4, 1, 0, 30, 0, 0, berlin), 2},
+		// Clocks went back on Sunday 2024-10-27
+		{time.Date(2024, 10, 28, 0, 30, 0, 0, berlin), 32},
+		{time.Date(2024, 10, 27, 23, 30, 0, 0, berlin), 31},
+	}
+	for _, tt := range tests {
+		if got := weeksBetween(windowStart, startOfWeek(tt.when)); got != tt.want {
+			t.Errorf("week of %s = %d, want %d", tt.when, got, tt.want)
+		}
+	}
+}
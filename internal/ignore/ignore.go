@@ -0,0 +1,210 @@
+package ignore
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// DefaultPatterns skip dependencies and build output, which tools regenerate
+// and which would otherwise dominate anything measured over a project.
+var DefaultPatterns = []string{
+	".git/",
+	"node_modules/",
+	"target/",
+	".next/",
+	"dist/",
+	"build/",
+	"vendor/",
+	"__pycache__/",
+	".venv/",
+}
+
+// Matcher decides which paths to skip using gitignore syntax: globs with *,
+// ? and **, a leading / to anchor a pattern to the root, a trailing / for
+// directories only and ! to re-include. The last matching pattern wins.
+type Matcher struct {
+	patterns []pattern
+}
+
+type pattern struct {
+	re      *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// New returns a matcher for the given pattern lines. Blank lines and
+// comments are ignored.
+func New(lines []string) *Matcher {
+	m := &Matcher{}
+	m.Add(lines...)
+	return m
+}
+
+// Load returns a matcher with DefaultPatterns and the patterns in the named
+// files under root, such as ".gitignore". Missing files are skipped.
+func Load(root string, files ...string) (*Matcher, error) {
+	m := New(DefaultPatterns)
+	for _, name := range files {
+		if err := m.AddFile(filepath.Join(root, name)); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// AddFile appends the patterns in the file at path. A missing file adds
+// nothing.
+func (m *Matcher) AddFile(path string) error {
+	lines, err := readLines(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	m.Add(lines...)
+	return nil
+}
+
+func readLines(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines, scanner.Err()
+}
+
+// Add appends patterns to the matcher.
+func (m *Matcher) Add(lines ...string) {
+	for _, line := range lines {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var p pattern
+		if strings.HasPrefix(line, "!") {
+			p.negate = true
+			line = line[1:]
+		}
+		line = strings.TrimPrefix(line, "\\")
+		if strings.HasSuffix(line, "/") {
+			p.dirOnly = true
+			line = strings.TrimSuffix(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+
+		// Patterns with a slash other than at the end are relative to the
+		// root; the rest match at any depth
+		prefix := "(?:^|.*/)"
+		if strings.Contains(line, "/") {
+			prefix = "^"
+			line = strings.TrimPrefix(line, "/")
+		}
+		re, err := regexp.Compile(prefix + globToRegexp(line) + "$")
+		if err != nil {
+			continue
+		}
+		p.re = re
+		m.patterns = append(m.patterns, p)
+	}
+}
+
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "/**") && i+3 == len(glob):
+			b.WriteString("/.*")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += end
+		case c == '\\' && i+1 < len(glob):
+			i++
+			b.WriteString(regexp.QuoteMeta(string(glob[i])))
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String()
+}
+
+// Match reports whether the slash separated path relative to the root is
+// ignored, either itself or because a parent directory is.
+func (m *Matcher) Match(relPath string, isDir bool) bool {
+	if m == nil {
+		return false
+	}
+	relPath = strings.Trim(filepath.ToSlash(relPath), "/")
+	for i := 0; i < len(relPath); i++ {
+		if relPath[i] == '/' && m.match(relPath[:i], true) {
+			return true
+		}
+	}
+	return m.match(relPath, isDir)
+}
+
+func (m *Matcher) match(relPath string, isDir bool) bool {
+	ignored := false
+	for _, p := range m.patterns {
+		if p.dirOnly && !isDir {
+			continue
+		}
+		if p.re.MatchString(relPath) {
+			ignored = !p.negate
+		}
+	}
+	return ignored
+}
+
+// MatchDir is Match for a directory during a walk, whose parents were
+// already checked.
+func (m *Matcher) MatchDir(relPath string) bool {
+	if m == nil {
+		return false
+	}
+	return m.match(strings.Trim(filepath.ToSlash(relPath), "/"), true)
+}
+
+// MatchFile is Match for a file during a walk, whose parents were already
+// checked.
+func (m *Matcher) MatchFile(relPath string) bool {
+	if m == nil {
+		return false
+	}
+	return m.match(filepath.ToSlash(relPath), false)
+}
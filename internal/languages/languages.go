@@ -0,0 +1,295 @@
+package languages
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Language describes how to recognize a language and its comments.
+type Language struct {
+	Name       string   `json:"name"`
+	Extensions []string `json:"extensions"`
+	Filenames  []string `json:"filenames"`
+	// Shebangs are interpreter names for scripts without an extension.
+	Shebangs []string `json:"shebangs"`
+	// Line are the tokens starting a comment that runs to the end of line.
+	Line []string `json:"line"`
+	// Block are start and end token pairs of block comments.
+	Block [][2]string `json:"block"`
+}
+
+//go:embed languages.json
+var languagesJSON []byte
+
+var (
+	languages   []*Language
+	byExtension = make(map[string]*Language)
+	byFilename  = make(map[string]*Language)
+	byShebang   = make(map[string]*Language)
+)
+
+func init() {
+	if err := json.Unmarshal(languagesJSON, &languages); err != nil {
+		panic("invalid language table: " + err.Error())
+	}
+	for _, lang := range languages {
+		for _, ext := range lang.Extensions {
+			byExtension[ext] = lang
+		}
+		for _, name := range lang.Filenames {
+			byFilename[name] = lang
+		}
+		for _, interpreter := range lang.Shebangs {
+			byShebang[interpreter] = lang
+		}
+	}
+}
+
+// Detect returns the language of the file at path from its name, or for
+// files without a known extension from its shebang line. It returns nil for
+// files that are not source code.
+func Detect(path string) *Language {
+	name := filepath.Base(path)
+	if lang, ok := byFilename[name]; ok {
+		return lang
+	}
+	if ext := filepath.Ext(name); ext != "" {
+		return byExtension[strings.ToLower(ext)]
+	}
+	return detectShebang(path)
+}
+
+func detectShebang(path string) *Language {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	line, err := bufio.NewReader(io.LimitReader(file, 256)).ReadString('\n')
+	if err != nil && line == "" {
+		return nil
+	}
+	if !strings.HasPrefix(line, "#!") {
+		return nil
+	}
+	fields := strings.Fields(line[2:])
+	if len(fields) == 0 {
+		return nil
+	}
+	// "#!/usr/bin/env python3" names the interpreter in the second field
+	interpreter := filepath.Base(fields[0])
+	if interpreter == "env" {
+		for _, f := range fields[1:] {
+			if !strings.HasPrefix(f, "-") {
+				interpreter = f
+				break
+			}
+		}
+	}
+	return byShebang[interpreter]
+}
+
+// LineCounts are the lines of a file or language by kind. Lines with both
+// code and a comment count as code.
+type LineCounts struct {
+	Code     int `json:"code"`
+	Comments int `json:"comments"`
+	Blanks   int `json:"blanks"`
+}
+
+func (c LineCounts) Lines() int {
+	return c.Code + c.Comments + c.Blanks
+}
+
+func (c *LineCounts) add(o LineCounts) {
+	c.Code += o.Code
+	c.Comments += o.Comments
+	c.Blanks += o.Blanks
+}
+
+// CountLines classifies the lines read from r as code, comments or blanks
+// using the comment syntax of lang. Comment tokens inside strings are not
+// recognized, which is rarely visible in the totals.
+func CountLines(r io.Reader, lang *Language) (LineCounts, error) {
+	return Scan(r, lang, nil)
+}
+
+// Comments calls fn with the text of every comment read from r, without
+// the comment tokens, and its line number starting at 1. Block comments
+// spanning several lines are passed one line at a time.
+func Comments(r io.Reader, lang *Language, fn func(line int, text string)) error {
+	_, err := Scan(r, lang, fn)
+	return err
+}
+
+// Scan is CountLines and Comments in one pass over r. comment may be nil.
+func Scan(r io.Reader, lang *Language, comment func(line int, text string)) (LineCounts, error) {
+	var counts LineCounts
+	err := scanLines(r, lang, func(n int, line string, code bool, comments []string) {
+		switch {
+		case line == "":
+			counts.Blanks++
+		case code:
+			counts.Code++
+		default:
+			counts.Comments++
+		}
+		if comment != nil {
+			for _, text := range comments {
+				comment(n, text)
+			}
+		}
+	})
+	return counts, err
+}
+
+// scanLines splits each line read from r into code and comments and calls
+// fn with the line number, the trimmed line, whether it has code and its
+// comments.
+func scanLines(r io.Reader, lang *Language, fn func(n int, line string, code bool, comments []string)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	// blockEnd is the token closing the block comment we are in, if any
+	blockEnd := ""
+	for n := 1; scanner.Scan(); n++ {
+		line := string(bytes.TrimSpace(scanner.Bytes()))
+		if line == "" {
+			fn(n, line, false, nil)
+			continue
+		}
+
+		code := false
+		var comments []string
+		rest := line
+		for rest != "" {
+			if blockEnd != "" {
+				end := strings.Index(rest, blockEnd)
+				if end < 0 {
+					comments = append(comments, rest)
+					rest = ""
+					break
+				}
+				comments = append(comments, rest[:end])
+				rest = rest[end+len(blockEnd):]
+				blockEnd = ""
+				continue
+			}
+
+			start, token, end := nextComment(rest, lang)
+			if start < 0 {
+				if strings.TrimSpace(rest) != "" {
+					code = true
+				}
+				break
+			}
+			if strings.TrimSpace(rest[:start]) != "" {
+				code = true
+			}
+			if end == "" {
+				// Line comment
+				comments = append(comments, rest[start+len(token):])
+				break
+			}
+			blockEnd = end
+			rest = rest[start+len(token):]
+		}
+		fn(n, line, code, comments)
+	}
+	return scanner.Err()
+}
+
+// nextComment finds the first comment token in s. end is the closing token
+// for block comments and empty for line comments.
+func nextComment(s string, lang *Language) (start int, token, end string) {
+	start = -1
+	for _, t := range lang.Line {
+		if i := strings.Index(s, t); i >= 0 && (start < 0 || i < start) {
+			start, token, end = i, t, ""
+		}
+	}
+	for _, pair := range lang.Block {
+		// Prefer block tokens on ties, so "--[[" wins over "--" in Lua
+		if i := strings.Index(s, pair[0]); i >= 0 && (start < 0 || i <= start) {
+			start, token, end = i, pair[0], pair[1]
+		}
+	}
+	return start, token, end
+}
+
+// Stats are the totals for one language in a project.
+type Stats struct {
+	Name  string `json:"name"`
+	Files int    `json:"files"`
+	LineCounts
+}
+
+// Counter accumulates line counts per language. It is safe for concurrent
+// use, so files can be counted in parallel.
+type Counter struct {
+	mu        sync.Mutex
+	languages map[string]*Stats
+}
+
+func NewCounter() *Counter {
+	return &Counter{languages: make(map[string]*Stats)}
+}
+
+// AddFile counts the file at path if it is source code in a known language.
+func (c *Counter) AddFile(path string) error {
+	lang := Detect(path)
+	if lang == nil {
+		return nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	counts, err := CountLines(file, lang)
+	if err != nil {
+		return err
+	}
+	c.Add(lang.Name, counts)
+	return nil
+}
+
+// Add records one file of the named language.
+func (c *Counter) Add(name string, counts LineCounts) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s, ok := c.languages[name]
+	if !ok {
+		s = &Stats{Name: name}
+		c.languages[name] = s
+	}
+	s.Files++
+	s.add(counts)
+}
+
+// Result returns the languages sorted by lines of code, most first.
+func (c *Counter) Result() []Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	result := make([]Stats, 0, len(c.languages))
+	for _, s := range c.languages {
+		result = append(result, *s)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Code != result[j].Code {
+			return result[i].Code > result[j].Code
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
@@ -0,0 +1,189 @@
+package osv
+
+import (
+	"slices"
+	"sort"
+
+	"github.com/Masterminds/semver/v3"
+
+	"project-starter/internal/deps"
+)
+
+// Ecosystems map dependency ecosystems to OSV ecosystem names.
+var Ecosystems = map[string]string{
+	deps.EcosystemGo:    "Go",
+	deps.EcosystemNPM:   "npm",
+	deps.EcosystemCargo: "crates.io",
+}
+
+// Finding is a vulnerability affecting a dependency.
+type Finding struct {
+	Dependency deps.Dependency `json:"dependency"`
+	ID         string          `json:"id"`
+	Aliases    []string        `json:"aliases,omitempty"`
+	Summary    string          `json:"summary,omitempty"`
+	// Severity is one of the Severities.
+	Severity string `json:"severity"`
+	// Score is the CVSS base score, or 0 when the record has no vector.
+	Score float64 `json:"score,omitempty"`
+	// Fixed is the lowest version without the vulnerability, if there is
+	// one.
+	Fixed string `json:"fixed,omitempty"`
+}
+
+// Check returns the vulnerabilities affecting each dependency. Dependencies
+// whose version isn't a resolved version, such as a range declared without
+// a lockfile, can't be checked and are returned as unchecked.
+func (db *Database) Check(dependencies []deps.Dependency) (findings []Finding, unchecked []deps.Dependency) {
+	for _, d := range dependencies {
+		ecosystem, ok := Ecosystems[d.Ecosystem]
+		if !ok {
+			continue
+		}
+		version, err := semver.StrictNewVersion(trimV(d.Version))
+		if err != nil {
+			unchecked = append(unchecked, d)
+			continue
+		}
+
+		reported := make(map[string]bool)
+		for _, vuln := range db.packages[packageKey(ecosystem, d.Name)] {
+			if reported[vuln.ID] || slices.ContainsFunc(vuln.Aliases, func(alias string) bool { return reported[alias] }) {
+				continue
+			}
+			affected, fixed, ok := vuln.affects(ecosystem, d.Name, version)
+			if !ok {
+				continue
+			}
+			severity, score := affected.severity(vuln)
+			findings = append(findings, Finding{
+				Dependency: d,
+				ID:         vuln.ID,
+				Aliases:    vuln.Aliases,
+				Summary:    vuln.Summary,
+				Severity:   severity,
+				Score:      score,
+				Fixed:      fixed,
+			})
+			// The same advisory is often published under several IDs, such
+			// as a GO- record aliasing a GHSA one. Either may list the
+			// other, or both may only share a CVE
+			reported[vuln.ID] = true
+			for _, alias := range vuln.Aliases {
+				reported[alias] = true
+			}
+		}
+	}
+	return findings, unchecked
+}
+
+// trimV removes the v of Go versions, which OSV records leave out.
+func trimV(version string) string {
+	if len(version) > 1 && version[0] == 'v' {
+		return version[1:]
+	}
+	return version
+}
+
+// affects reports whether version of the package is affected, returning the
+// matching affected entry and the lowest fixed version above version.
+func (vuln *Vulnerability) affects(ecosystem, name string, version *semver.Version) (*Affected, string, bool) {
+	for i := range vuln.Affected {
+		a := &vuln.Affected[i]
+		if a.Package.Ecosystem != ecosystem || a.Package.Name != name {
+			continue
+		}
+		affected := false
+		for _, v := range a.Versions {
+			if listed, err := semver.NewVersion(trimV(v)); err == nil && listed.Equal(version) {
+				affected = true
+			}
+		}
+		var fixed *semver.Version
+		for _, r := range a.Ranges {
+			if r.Type != "SEMVER" && r.Type != "ECOSYSTEM" {
+				continue
+			}
+			inRange, rangeFixed := r.contains(version)
+			if !inRange {
+				continue
+			}
+			affected = true
+			if rangeFixed != nil && (fixed == nil || rangeFixed.LessThan(fixed)) {
+				fixed = rangeFixed
+			}
+		}
+		if affected {
+			if fixed == nil {
+				return a, "", true
+			}
+			return a, fixed.Original(), true
+		}
+	}
+	return nil, "", false
+}
+
+// event is a range event with its version parsed.
+type event struct {
+	kind    string
+	version *semver.Version
+}
+
+// contains evaluates the range's events in version order, as the OSV
+// schema describes: each introduced event starts an affected range and
+// the next fixed or last_affected event ends it. It also returns the fixed
+// version ending the range containing version.
+func (r Range) contains(version *semver.Version) (bool, *semver.Version) {
+	events := make([]event, 0, len(r.Events))
+	for _, e := range r.Events {
+		kind, value := "introduced", e.Introduced
+		switch {
+		case e.Fixed != "":
+			kind, value = "fixed", e.Fixed
+		case e.LastAffected != "":
+			kind, value = "last_affected", e.LastAffected
+		case e.Introduced == "":
+			// limit events only apply to GIT ranges
+			continue
+		}
+		// introduced "0" means every version
+		if value == "0" {
+			value = "0.0.0-0"
+		}
+		v, err := semver.NewVersion(trimV(value))
+		if err != nil {
+			continue
+		}
+		events = append(events, event{kind, v})
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].version.LessThan(events[j].version)
+	})
+
+	affected := false
+	for i, e := range events {
+		switch {
+		case e.kind == "introduced" && !version.LessThan(e.version):
+			affected = true
+		case e.kind == "fixed" && !version.LessThan(e.version):
+			affected = false
+		case e.kind == "last_affected" && version.GreaterThan(e.version):
+			affected = false
+		case version.LessThan(e.version):
+			// Events are sorted, so the rest are above version. The next
+			// fixed event ends the range version is in.
+			if affected {
+				for _, next := range events[i:] {
+					if next.kind == "fixed" {
+						return true, next.version
+					}
+					if next.kind == "last_affected" {
+						break
+					}
+				}
+			}
+			return affected, nil
+		}
+	}
+	return affected, nil
+}
@@ -0,0 +1,139 @@
+package osv
+
+import (
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+
+	"project-starter/internal/deps"
+)
+
+func loadTestDatabase(t *testing.T) *Database {
+	t.Helper()
+	db, err := Load("testdata", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestCheck(t *testing.T) {
+	db := loadTestDatabase(t)
+	if db.Records != 6 {
+		t.Errorf("Records = %d, want 6", db.Records)
+	}
+
+	tests := []struct {
+		dep  deps.Dependency
+		want []Finding
+	}{
+		// GO-2022-1059 and GHSA-69ch-w2m2-3vjp are the same advisory, and
+		// only the GitHub one has a severity
+		{
+			deps.Dependency{Name: "golang.org/x/text", Version: "v0.3.7", Ecosystem: deps.EcosystemGo},
+			[]Finding{{ID: "GHSA-69ch-w2m2-3vjp", Severity: "HIGH", Score: 7.5, Fixed: "0.3.8"}},
+		},
+		{deps.Dependency{Name: "golang.org/x/text", Version: "v0.3.8", Ecosystem: deps.EcosystemGo}, nil},
+		{deps.Dependency{Name: "golang.org/x/text", Version: "v0.14.0", Ecosystem: deps.EcosystemGo}, nil},
+		// json5 was fixed in both its 1.x and 2.x lines
+		{
+			deps.Dependency{Name: "json5", Version: "1.0.1", Ecosystem: deps.EcosystemNPM},
+			[]Finding{{ID: "GHSA-9c47-m6qq-7p4h", Severity: "HIGH", Score: 7.1, Fixed: "1.0.2"}},
+		},
+		{deps.Dependency{Name: "json5", Version: "1.0.2", Ecosystem: deps.EcosystemNPM}, nil},
+		{
+			deps.Dependency{Name: "json5", Version: "2.2.1", Ecosystem: deps.EcosystemNPM},
+			[]Finding{{ID: "GHSA-9c47-m6qq-7p4h", Severity: "HIGH", Score: 7.1, Fixed: "2.2.2"}},
+		},
+		{deps.Dependency{Name: "json5", Version: "2.2.2", Ecosystem: deps.EcosystemNPM}, nil},
+		// last_affected versions are still affected, and there is no fix
+		{
+			deps.Dependency{Name: "marked-lite", Version: "1.4.2", Ecosystem: deps.EcosystemNPM},
+			[]Finding{{ID: "TEST-last-affected", Severity: "MEDIUM", Score: 6.1}},
+		},
+		{deps.Dependency{Name: "marked-lite", Version: "1.4.3", Ecosystem: deps.EcosystemNPM}, nil},
+		{deps.Dependency{Name: "marked-lite", Version: "1.0.0", Ecosystem: deps.EcosystemNPM}, nil},
+		// Listed versions are affected outside of the ranges
+		{
+			deps.Dependency{Name: "marked-lite", Version: "0.9.0", Ecosystem: deps.EcosystemNPM},
+			[]Finding{{ID: "TEST-last-affected", Severity: "MEDIUM", Score: 6.1}},
+		},
+		// Withdrawn records don't affect anything
+		{deps.Dependency{Name: "react", Version: "18.2.0", Ecosystem: deps.EcosystemNPM}, nil},
+		{
+			deps.Dependency{Name: "tiny-http", Version: "0.11.3", Ecosystem: deps.EcosystemCargo},
+			[]Finding{{ID: "TEST-unordered", Severity: "UNKNOWN", Fixed: "0.11.4"}},
+		},
+		{deps.Dependency{Name: "tiny-http", Version: "0.11.4", Ecosystem: deps.EcosystemCargo}, nil},
+		{
+			deps.Dependency{Name: "tiny-http", Version: "0.12.0", Ecosystem: deps.EcosystemCargo},
+			[]Finding{{ID: "TEST-unordered", Severity: "UNKNOWN", Fixed: "0.12.1"}},
+		},
+	}
+	for _, tt := range tests {
+		findings, unchecked := db.Check([]deps.Dependency{tt.dep})
+		if len(unchecked) > 0 {
+			t.Errorf("%s@%s wasn't checked", tt.dep.Name, tt.dep.Version)
+			continue
+		}
+		if len(findings) != len(tt.want) {
+			t.Errorf("%s@%s: got %d findings %+v, want %d", tt.dep.Name, tt.dep.Version, len(findings), findings, len(tt.want))
+			continue
+		}
+		for i, f := range findings {
+			want := tt.want[i]
+			if f.ID != want.ID || f.Severity != want.Severity || f.Score != want.Score || f.Fixed != want.Fixed {
+				t.Errorf("%s@%s: got %s %s %.1f fixed %q, want %s %s %.1f fixed %q", tt.dep.Name, tt.dep.Version,
+					f.ID, f.Severity, f.Score, f.Fixed, want.ID, want.Severity, want.Score, want.Fixed)
+			}
+		}
+	}
+}
+
+func TestCheckUnresolvedVersion(t *testing.T) {
+	dep := deps.Dependency{Name: "json5", Version: "^1.0.0", Ecosystem: deps.EcosystemNPM}
+	findings, unchecked := loadTestDatabase(t).Check([]deps.Dependency{dep})
+	if len(findings) != 0 || len(unchecked) != 1 {
+		t.Errorf("Check of a range = %v findings, %v unchecked, want it unchecked", findings, unchecked)
+	}
+}
+
+func TestRangeContains(t *testing.T) {
+	introduced := func(v string) Event { return Event{Introduced: v} }
+	fixed := func(v string) Event { return Event{Fixed: v} }
+	lastAffected := func(v string) Event { return Event{LastAffected: v} }
+
+	tests := []struct {
+		name    string
+		events  []Event
+		version string
+		want    bool
+		fixed   string
+	}{
+		{"every version", []Event{introduced("0")}, "0.0.1", true, ""},
+		{"before introduced", []Event{introduced("1.2.0"), fixed("1.3.0")}, "1.1.9", false, ""},
+		{"at introduced", []Event{introduced("1.2.0"), fixed("1.3.0")}, "1.2.0", true, "1.3.0"},
+		{"at fixed", []Event{introduced("1.2.0"), fixed("1.3.0")}, "1.3.0", false, ""},
+		{"prerelease of fixed", []Event{introduced("0"), fixed("1.3.0")}, "1.3.0-rc.1", true, "1.3.0"},
+		{"at last_affected", []Event{introduced("0"), lastAffected("2.0.0")}, "2.0.0", true, ""},
+		{"after last_affected", []Event{introduced("0"), lastAffected("2.0.0")}, "2.0.1", false, ""},
+		{"second range", []Event{introduced("0"), fixed("1.0.2"), introduced("2.0.0"), fixed("2.2.2")}, "2.1.0", true, "2.2.2"},
+		{"between ranges", []Event{introduced("0"), fixed("1.0.2"), introduced("2.0.0"), fixed("2.2.2")}, "1.5.0", false, ""},
+		{"unordered", []Event{fixed("2.2.2"), introduced("2.0.0"), fixed("1.0.2"), introduced("0")}, "1.0.0", true, "1.0.2"},
+		// The fix of a later range doesn't fix a range ended by
+		// last_affected
+		{"last_affected then fixed", []Event{introduced("0"), lastAffected("1.0.0"), introduced("2.0.0"), fixed("2.1.0")}, "0.5.0", true, ""},
+		{"limit ignored", []Event{introduced("1.0.0"), {Limit: "1.1.0"}}, "1.5.0", true, ""},
+	}
+	for _, tt := range tests {
+		version := semver.MustParse(tt.version)
+		got, gotFixed := Range{Type: "SEMVER", Events: tt.events}.contains(version)
+		fixedVersion := ""
+		if gotFixed != nil {
+			fixedVersion = gotFixed.Original()
+		}
+		if got != tt.want || fixedVersion != tt.fixed {
+			t.Errorf("%s: contains(%s) = %v, %q, want %v, %q", tt.name, tt.version, got, fixedVersion, tt.want, tt.fixed)
+		}
+	}
+}
@@ -0,0 +1,244 @@
+package osv
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"project-starter/internal/walk"
+)
+
+// Vulnerability is an OSV record, limited to the fields audits use.
+// https://ossf.github.io/osv-schema/
+type Vulnerability struct {
+	ID               string           `json:"id"`
+	Aliases          []string         `json:"aliases,omitempty"`
+	Summary          string           `json:"summary,omitempty"`
+	Details          string           `json:"details,omitempty"`
+	Withdrawn        *time.Time       `json:"withdrawn,omitempty"`
+	Severity         []Severity       `json:"severity,omitempty"`
+	Affected         []Affected       `json:"affected"`
+	DatabaseSpecific DatabaseSpecific `json:"database_specific"`
+}
+
+type Severity struct {
+	// Type is CVSS_V2, CVSS_V3 or CVSS_V4, with a vector as Score.
+	Type  string `json:"type"`
+	Score string `json:"score"`
+}
+
+type Affected struct {
+	Package  Package  `json:"package"`
+	Ranges   []Range  `json:"ranges,omitempty"`
+	Versions []string `json:"versions,omitempty"`
+	// DatabaseSpecific holds the severity in GitHub advisories, which set
+	// it per package.
+	DatabaseSpecific DatabaseSpecific `json:"database_specific"`
+}
+
+type Package struct {
+	Ecosystem string `json:"ecosystem"`
+	Name      string `json:"name"`
+}
+
+type Range struct {
+	// Type is SEMVER, ECOSYSTEM or GIT. GIT ranges are commit hashes and
+	// can't be evaluated against versions.
+	Type   string  `json:"type"`
+	Events []Event `json:"events"`
+}
+
+// Event is one of introduced, fixed, last_affected or limit.
+type Event struct {
+	Introduced   string `json:"introduced,omitempty"`
+	Fixed        string `json:"fixed,omitempty"`
+	LastAffected string `json:"last_affected,omitempty"`
+	Limit        string `json:"limit,omitempty"`
+}
+
+type DatabaseSpecific struct {
+	// Severity is a rating such as LOW, MODERATE, HIGH or CRITICAL.
+	Severity string `json:"severity,omitempty"`
+}
+
+// Database indexes vulnerabilities by ecosystem and package name.
+type Database struct {
+	packages map[string][]*Vulnerability
+	// Records is the number of records read.
+	Records int
+}
+
+// ErrNoDatabase is returned when the database directory doesn't exist or
+// has no records.
+var ErrNoDatabase = errors.New("no vulnerability database")
+
+func packageKey(ecosystem, name string) string {
+	return ecosystem + "/" + name
+}
+
+// Load reads every JSON record below dir. When wanted is not nil, only
+// records affecting one of the packages it returns true for are kept, which
+// saves memory with large databases.
+func Load(dir string, wanted func(ecosystem, name string) bool) (*Database, error) {
+	if _, err := os.Stat(dir); errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNoDatabase
+	}
+
+	db := &Database{packages: make(map[string][]*Vulnerability)}
+	paths := make(chan string)
+	var (
+		mu       sync.Mutex
+		firstErr error
+		workers  sync.WaitGroup
+	)
+	for i := 0; i < runtime.NumCPU(); i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for path := range paths {
+				vuln, err := readRecord(path)
+				mu.Lock()
+				if err != nil && firstErr == nil {
+					firstErr = fmt.Errorf("%s: %v", path, err)
+				}
+				if err == nil {
+					db.add(vuln, wanted)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	err := walk.Walk(dir, walk.Options{}, func(path, _ string, d fs.DirEntry) error {
+		if !d.IsDir() && strings.HasSuffix(d.Name(), ".json") {
+			paths <- path
+		}
+		return nil
+	})
+	close(paths)
+	workers.Wait()
+	if err == nil {
+		err = firstErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	if db.Records == 0 {
+		return nil, ErrNoDatabase
+	}
+	// Records are read concurrently; sorting keeps reports stable
+	for _, vulns := range db.packages {
+		sort.Slice(vulns, func(i, j int) bool { return vulns[i].ID < vulns[j].ID })
+	}
+	return db, nil
+}
+
+func readRecord(path string) (*Vulnerability, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var vuln Vulnerability
+	if err := json.Unmarshal(data, &vuln); err != nil {
+		return nil, err
+	}
+	return &vuln, nil
+}
+
+func (db *Database) add(vuln *Vulnerability, wanted func(ecosystem, name string) bool) {
+	db.Records++
+	if vuln.Withdrawn != nil {
+		return
+	}
+	seen := make(map[string]bool)
+	for _, a := range vuln.Affected {
+		key := packageKey(a.Package.Ecosystem, a.Package.Name)
+		if seen[key] || (wanted != nil && !wanted(a.Package.Ecosystem, a.Package.Name)) {
+			continue
+		}
+		seen[key] = true
+		db.packages[key] = append(db.packages[key], vuln)
+	}
+}
+
+// Import extracts the JSON records of an OSV export, such as the all.zip
+// osv.dev publishes per ecosystem, into dir/<ecosystem>. It returns the
+// number of records imported.
+func Import(zipPath, dir string) (int, error) {
+	reader, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return 0, err
+	}
+	defer reader.Close()
+
+	imported := 0
+	for _, file := range reader.File {
+		if file.FileInfo().IsDir() || !strings.HasSuffix(file.Name, ".json") {
+			continue
+		}
+		data, err := readZipFile(file)
+		if err != nil {
+			return imported, fmt.Errorf("%s: %v", file.Name, err)
+		}
+		var vuln Vulnerability
+		if err := json.Unmarshal(data, &vuln); err != nil {
+			return imported, fmt.Errorf("%s: %v", file.Name, err)
+		}
+		if vuln.ID == "" || len(vuln.Affected) == 0 {
+			continue
+		}
+
+		// Names come from the record rather than the archive, and are made
+		// safe so a crafted archive can't write outside dir
+		ecosystem := safeName(vuln.Affected[0].Package.Ecosystem)
+		id := safeName(vuln.ID)
+		if ecosystem == "" || id == "" {
+			continue
+		}
+		target := filepath.Join(dir, ecosystem, id+".json")
+		if err := os.MkdirAll(filepath.Dir(target), os.ModePerm); err != nil {
+			return imported, err
+		}
+		if err := os.WriteFile(target, data, 0644); err != nil {
+			return imported, err
+		}
+		imported++
+	}
+	return imported, nil
+}
+
+func readZipFile(file *zip.File) ([]byte, error) {
+	r, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+// safeName keeps letters, digits and -._ and replaces anything else, such
+// as the colon of "Alpine:v3.16", with an underscore.
+func safeName(s string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '.', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, s)
+	if strings.Trim(name, ".") == "" {
+		return ""
+	}
+	return name
+}
@@ -0,0 +1,124 @@
+package osv
+
+import (
+	"math"
+	"strings"
+)
+
+// Severities from most to least severe.
+var Severities = []string{"CRITICAL", "HIGH", "MEDIUM", "LOW", "UNKNOWN"}
+
+// SeverityRank returns the position of severity in Severities, so lower
+// ranks are more severe.
+func SeverityRank(severity string) int {
+	for i, s := range Severities {
+		if s == severity {
+			return i
+		}
+	}
+	return len(Severities) - 1
+}
+
+// severity rates the vulnerability. A rating in the database, which GitHub
+// advisories carry, takes precedence; otherwise the CVSS v3 vector is
+// scored. CVSS v2 and v4 vectors aren't scored.
+func (a *Affected) severity(vuln *Vulnerability) (string, float64) {
+	score := 0.0
+	for _, s := range vuln.Severity {
+		if s.Type == "CVSS_V3" {
+			if parsed, ok := cvss3Score(s.Score); ok {
+				score = parsed
+			}
+		}
+	}
+	for _, rating := range []string{a.DatabaseSpecific.Severity, vuln.DatabaseSpecific.Severity} {
+		rating = strings.ToUpper(rating)
+		if rating == "MODERATE" {
+			rating = "MEDIUM"
+		}
+		if SeverityRank(rating) < len(Severities)-1 {
+			return rating, score
+		}
+	}
+	switch {
+	case score >= 9:
+		return "CRITICAL", score
+	case score >= 7:
+		return "HIGH", score
+	case score >= 4:
+		return "MEDIUM", score
+	case score > 0:
+		return "LOW", score
+	}
+	return "UNKNOWN", score
+}
+
+// cvss3Weights are the CVSS v3.1 metric values by metric and value letter.
+// Privileges required has other values when the scope changes, see
+// cvss3Score. https://www.first.org/cvss/v3.1/specification-document
+var cvss3Weights = map[string]map[string]float64{
+	"AV": {"N": 0.85, "A": 0.62, "L": 0.55, "P": 0.2},
+	"AC": {"L": 0.77, "H": 0.44},
+	"PR": {"N": 0.85, "L": 0.62, "H": 0.27},
+	"UI": {"N": 0.85, "R": 0.62},
+	"C":  {"H": 0.56, "L": 0.22, "N": 0},
+	"I":  {"H": 0.56, "L": 0.22, "N": 0},
+	"A":  {"H": 0.56, "L": 0.22, "N": 0},
+}
+
+// cvss3Score computes the base score of a vector such as
+// CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H.
+func cvss3Score(vector string) (float64, bool) {
+	parts := strings.Split(vector, "/")
+	if len(parts) == 0 || !strings.HasPrefix(parts[0], "CVSS:3.") {
+		return 0, false
+	}
+	values := make(map[string]string)
+	for _, part := range parts[1:] {
+		if metric, value, ok := strings.Cut(part, ":"); ok {
+			values[metric] = value
+		}
+	}
+
+	changed := values["S"] == "C"
+	if !changed && values["S"] != "U" {
+		return 0, false
+	}
+	w := make(map[string]float64)
+	for metric, weights := range cvss3Weights {
+		weight, ok := weights[values[metric]]
+		if !ok {
+			return 0, false
+		}
+		w[metric] = weight
+	}
+	if changed && values["PR"] == "L" {
+		w["PR"] = 0.68
+	} else if changed && values["PR"] == "H" {
+		w["PR"] = 0.5
+	}
+
+	iss := 1 - (1-w["C"])*(1-w["I"])*(1-w["A"])
+	impact := 6.42 * iss
+	if changed {
+		impact = 7.52*(iss-0.029) - 3.25*math.Pow(iss-0.02, 15)
+	}
+	if impact <= 0 {
+		return 0, true
+	}
+	exploitability := 8.22 * w["AV"] * w["AC"] * w["PR"] * w["UI"]
+	if changed {
+		return roundUp(math.Min(1.08*(impact+exploitability), 10)), true
+	}
+	return roundUp(math.Min(impact+exploitability, 10)), true
+}
+
+// roundUp rounds up to one decimal the way CVSS v3.1 defines it, avoiding
+// floating point errors like 4.000000001 becoming 4.1.
+func roundUp(x float64) float64 {
+	i := int(math.Round(x * 100000))
+	if i%10000 == 0 {
+		return float64(i) / 100000
+	}
+	return float64(i/10000+1) / 10
+}
@@ -0,0 +1,86 @@
+package osv
+
+import "testing"
+
+func TestCVSS3Score(t *testing.T) {
+	tests := []struct {
+		vector string
+		want   float64
+	}{
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", 9.8},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H", 10.0},
+		{"CVSS:3.1/AV:N/AC:L/PR:L/UI:N/S:C/C:H/I:H/A:H", 9.9},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:H", 7.5},
+		{"CVSS:3.1/AV:N/AC:L/PR:L/UI:N/S:U/C:H/I:H/A:H", 8.8},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:R/S:U/C:H/I:H/A:H", 8.8},
+		{"CVSS:3.1/AV:L/AC:L/PR:L/UI:N/S:U/C:H/I:H/A:H", 7.8},
+		{"CVSS:3.1/AV:N/AC:H/PR:L/UI:N/S:U/C:H/I:L/A:H", 7.1},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:R/S:C/C:L/I:L/A:N", 6.1},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:L/I:L/A:N", 6.5},
+		{"CVSS:3.1/AV:N/AC:H/PR:N/UI:N/S:U/C:H/I:N/A:N", 5.9},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:L/I:N/A:N", 5.3},
+		{"CVSS:3.1/AV:L/AC:L/PR:L/UI:N/S:U/C:N/I:N/A:H", 5.5},
+		{"CVSS:3.1/AV:N/AC:L/PR:H/UI:R/S:C/C:L/I:L/A:N", 4.8},
+		{"CVSS:3.1/AV:P/AC:H/PR:H/UI:R/S:U/C:L/I:N/A:N", 1.6},
+		{"CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", 9.8},
+		// Metrics may come in any order
+		{"CVSS:3.1/S:U/AV:N/AC:L/PR:N/UI:N/A:H/I:H/C:H", 9.8},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:N", 0},
+	}
+	for _, tt := range tests {
+		got, ok := cvss3Score(tt.vector)
+		if !ok || got != tt.want {
+			t.Errorf("cvss3Score(%s) = %v, %v, want %v", tt.vector, got, ok, tt.want)
+		}
+	}
+
+	for _, vector := range []string{
+		"",
+		"AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
+		"CVSS:2.0/AV:N/AC:L/Au:N/C:P/I:P/A:P",
+		"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N",
+		"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/C:H/I:H/A:H",
+		"CVSS:3.1/AV:X/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
+	} {
+		if score, ok := cvss3Score(vector); ok {
+			t.Errorf("cvss3Score(%q) = %v, want it rejected", vector, score)
+		}
+	}
+}
+
+func TestRoundUp(t *testing.T) {
+	tests := []struct{ in, want float64 }{
+		{4.0, 4.0},
+		{4.000000001, 4.0},
+		{4.02, 4.1},
+		{4.1, 4.1},
+		{9.96, 10.0},
+	}
+	for _, tt := range tests {
+		if got := roundUp(tt.in); got != tt.want {
+			t.Errorf("roundUp(%v) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSeverity(t *testing.T) {
+	critical := Severity{Type: "CVSS_V3", Score: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"}
+	tests := []struct {
+		name      string
+		vuln      Vulnerability
+		affected  Affected
+		want      string
+		wantScore float64
+	}{
+		{"vector only", Vulnerability{Severity: []Severity{critical}}, Affected{}, "CRITICAL", 9.8},
+		{"rating wins", Vulnerability{Severity: []Severity{critical}, DatabaseSpecific: DatabaseSpecific{Severity: "MODERATE"}}, Affected{}, "MEDIUM", 9.8},
+		{"package rating first", Vulnerability{DatabaseSpecific: DatabaseSpecific{Severity: "LOW"}}, Affected{DatabaseSpecific: DatabaseSpecific{Severity: "high"}}, "HIGH", 0},
+		{"v2 vector ignored", Vulnerability{Severity: []Severity{{Type: "CVSS_V2", Score: "AV:N/AC:L/Au:N/C:P/I:P/A:P"}}}, Affected{}, "UNKNOWN", 0},
+	}
+	for _, tt := range tests {
+		got, score := tt.affected.severity(&tt.vuln)
+		if got != tt.want || score != tt.wantScore {
+			t.Errorf("%s: severity = %s %v, want %s %v", tt.name, got, score, tt.want, tt.wantScore)
+		}
+	}
+}
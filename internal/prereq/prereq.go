@@ -0,0 +1,257 @@
+// Package prereq checks that the tools templates need are installed and
+// recent enough, and tells how to install them when they aren't.
+package prereq
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// versionTimeout bounds how long a tool may take to print its version.
+const versionTimeout = 10 * time.Second
+
+// Tool is a program templates run.
+type Tool struct {
+	// Command is the executable, looked up in PATH.
+	Command string
+	// VersionArgs print the version, such as --version.
+	VersionArgs []string
+	// Hints tell how to install the tool by GOOS. The "" entry is for
+	// other systems.
+	Hints map[string]string
+}
+
+// Tools are the tools requirements can name.
+var Tools = map[string]Tool{
+	"go": {Command: "go", VersionArgs: []string{"version"}, Hints: map[string]string{
+		"darwin":  "brew install go",
+		"windows": "winget install GoLang.Go",
+		"":        "see https://go.dev/doc/install",
+	}},
+	"node": {Command: "node", VersionArgs: []string{"--version"}, Hints: map[string]string{
+		"darwin":  "brew install node",
+		"windows": "winget install OpenJS.NodeJS.LTS",
+		"":        "see https://nodejs.org/en/download/package-manager",
+	}},
+	"npm": {Command: "npm", VersionArgs: []string{"--version"}, Hints: map[string]string{
+		"": "comes with Node.js, see https://nodejs.org/en/download/package-manager",
+	}},
+	"pnpm": {Command: "pnpm", VersionArgs: []string{"--version"}, Hints: map[string]string{
+		"": "npm install -g pnpm",
+	}},
+	"bun": {Command: "bun", VersionArgs: []string{"--version"}, Hints: map[string]string{
+		"windows": `powershell -c "irm bun.sh/install.ps1 | iex"`,
+		"":        "curl -fsSL https://bun.sh/install | bash",
+	}},
+	"deno": {Command: "deno", VersionArgs: []string{"--version"}, Hints: map[string]string{
+		"windows": "winget install DenoLand.Deno",
+		"":        "curl -fsSL https://deno.land/install.sh | sh",
+	}},
+	"cargo": {Command: "cargo", VersionArgs: []string{"--version"}, Hints: map[string]string{
+		"windows": "winget install Rustlang.Rustup",
+		"":        "curl --proto '=https' --tlsv1.2 -sSf https://sh.rustup.rs | sh",
+	}},
+	"python": {Command: pythonCommand(), VersionArgs: []string{"--version"}, Hints: map[string]string{
+		"darwin":  "brew install python",
+		"linux":   "install python3 and python3-venv with your package manager",
+		"windows": "winget install Python.Python.3.13",
+		"":        "see https://www.python.org/downloads/",
+	}},
+	"uv": {Command: "uv", VersionArgs: []string{"--version"}, Hints: map[string]string{
+		"darwin":  "brew install uv",
+		"windows": `powershell -ExecutionPolicy ByPass -c "irm https://astral.sh/uv/install.ps1 | iex"`,
+		"":        "curl -LsSf https://astral.sh/uv/install.sh | sh",
+	}},
+	"poetry": {Command: "poetry", VersionArgs: []string{"--version"}, Hints: map[string]string{
+		"": "pipx install poetry",
+	}},
+	"buf": {Command: "buf", VersionArgs: []string{"--version"}, Hints: map[string]string{
+		"darwin":  "brew install bufbuild/buf/buf",
+		"windows": "winget install bufbuild.buf",
+		"":        "go install github.com/bufbuild/buf/cmd/buf@latest",
+	}},
+	"git": {Command: "git", VersionArgs: []string{"--version"}, Hints: map[string]string{
+		"darwin":  "xcode-select --install",
+		"linux":   "install git with your package manager, such as sudo apt install git",
+		"windows": "winget install Git.Git",
+		"":        "see https://git-scm.com/downloads",
+	}},
+	"docker": {Command: "docker", VersionArgs: []string{"--version"}, Hints: map[string]string{
+		"darwin":  "brew install --cask docker",
+		"linux":   "see https://docs.docker.com/engine/install/",
+		"windows": "winget install Docker.DockerDesktop",
+		"":        "see https://docs.docker.com/get-docker/",
+	}},
+}
+
+// pythonCommand is the name Python 3 is installed as, which is python on
+// Windows.
+func pythonCommand() string {
+	if runtime.GOOS == "windows" {
+		return "python"
+	}
+	return "python3"
+}
+
+// Hint tells how to install the named tool on this system.
+func Hint(name string) string {
+	hints := Tools[name].Hints
+	if hint, ok := hints[runtime.GOOS]; ok {
+		return hint
+	}
+	return hints[""]
+}
+
+// Requirement is a tool a template needs.
+type Requirement struct {
+	Tool string
+	// Minimum is the oldest version that works, such as "1.22", or empty
+	// for any.
+	Minimum string
+	// Optional tools are only needed for some setup options or variants,
+	// such as docker for building the image. Generation goes ahead without
+	// them.
+	Optional bool
+}
+
+// Status is what Check found for a requirement.
+type Status struct {
+	Requirement
+	// Installed is false when the tool isn't in PATH.
+	Installed bool
+	// Version is what the tool reported, empty when it couldn't be read.
+	Version string
+	// OK is set when the tool is installed at the minimum version or newer.
+	OK bool
+	// Problem describes why the requirement isn't met.
+	Problem string
+	// Hint tells how to install the tool when it isn't OK.
+	Hint string
+}
+
+// version matches the first version in a tool's output, like 1.22.3 in
+// "go version go1.22.3 linux/amd64" or 20.11.0 in "v20.11.0".
+var version = regexp.MustCompile(`\d+\.\d+(?:\.\d+)?`)
+
+// ParseVersion returns the first version in a tool's output.
+func ParseVersion(output string) (string, bool) {
+	v := version.FindString(output)
+	return v, v != ""
+}
+
+// Check looks up each requirement's tool and its version. Tools are run
+// concurrently; the statuses keep the order of reqs.
+func Check(reqs []Requirement) []Status {
+	statuses := make([]Status, len(reqs))
+	var wg sync.WaitGroup
+	for i, req := range reqs {
+		wg.Add(1)
+		go func(i int, req Requirement) {
+			defer wg.Done()
+			status := check(req)
+			if !status.OK {
+				status.Hint = Hint(req.Tool)
+			}
+			statuses[i] = status
+		}(i, req)
+	}
+	wg.Wait()
+	return statuses
+}
+
+func check(req Requirement) Status {
+	status := Status{Requirement: req}
+	tool, ok := Tools[req.Tool]
+	if !ok {
+		status.Problem = "unknown tool"
+		return status
+	}
+	path, err := exec.LookPath(tool.Command)
+	if err != nil {
+		status.Problem = "not installed"
+		return status
+	}
+	status.Installed = true
+
+	ctx, cancel := context.WithTimeout(context.Background(), versionTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, path, tool.VersionArgs...).CombinedOutput()
+	if err != nil {
+		status.Problem = fmt.Sprintf("%s %s failed: %v", tool.Command, strings.Join(tool.VersionArgs, " "), err)
+		return status
+	}
+	v, found := ParseVersion(string(out))
+	status.Version = v
+	if req.Minimum == "" {
+		status.OK = true
+		return status
+	}
+	if !found {
+		status.Problem = "version unknown"
+		return status
+	}
+
+	installed, err := semver.NewVersion(v)
+	if err != nil {
+		status.Problem = fmt.Sprintf("version %s unknown", v)
+		return status
+	}
+	minimum, err := semver.NewVersion(req.Minimum)
+	if err != nil {
+		status.Problem = fmt.Sprintf("invalid minimum version %s", req.Minimum)
+		return status
+	}
+	if installed.LessThan(minimum) {
+		status.Problem = fmt.Sprintf("%s is older than %s", v, req.Minimum)
+		return status
+	}
+	status.OK = true
+	return status
+}
+
+// Merge combines requirement lists, keeping the highest minimum of each
+// tool. A tool is optional only when every list has it as optional.
+// Tools are sorted by name.
+func Merge(lists ...[]Requirement) []Requirement {
+	merged := make(map[string]Requirement)
+	for _, list := range lists {
+		for _, req := range list {
+			existing, ok := merged[req.Tool]
+			if !ok {
+				merged[req.Tool] = req
+				continue
+			}
+			existing.Optional = existing.Optional && req.Optional
+			if newer(req.Minimum, existing.Minimum) {
+				existing.Minimum = req.Minimum
+			}
+			merged[req.Tool] = existing
+		}
+	}
+
+	reqs := make([]Requirement, 0, len(merged))
+	for _, req := range merged {
+		reqs = append(reqs, req)
+	}
+	sort.Slice(reqs, func(i, j int) bool { return reqs[i].Tool < reqs[j].Tool })
+	return reqs
+}
+
+// newer reports whether minimum a is stricter than b.
+func newer(a, b string) bool {
+	if a == "" || b == "" {
+		return b == ""
+	}
+	va, errA := semver.NewVersion(a)
+	vb, errB := semver.NewVersion(b)
+	return errA == nil && errB == nil && va.GreaterThan(vb)
+}
@@ -0,0 +1,168 @@
+package project
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/briandowns/spinner"
+	"github.com/fatih/color"
+
+	"project-starter/internal/deps"
+	"project-starter/internal/osv"
+)
+
+// AuditReport is the machine readable result of a dependency audit.
+type AuditReport struct {
+	Name     string        `json:"name"`
+	Path     string        `json:"path"`
+	Database string        `json:"database"`
+	Findings []osv.Finding `json:"findings"`
+	// Unchecked are dependencies without a resolved version.
+	Unchecked []deps.Dependency `json:"unchecked"`
+}
+
+// AuditDependencies checks the dependencies of the project at projectPath
+// against the OSV database in dbDir and writes the vulnerabilities found to
+// w as a table or json. Like a secret scan, it returns an error when
+// anything is found so scripts can fail on it.
+func AuditDependencies(w io.Writer, projectPath, dbDir, format string) error {
+	if format != "table" && format != "json" {
+		return fmt.Errorf("unknown format %q, expected table or json", format)
+	}
+	abs, err := filepath.Abs(projectPath)
+	if err != nil {
+		return err
+	}
+	all, err := deps.Read(abs)
+	if err != nil {
+		return fmt.Errorf("error reading dependencies: %v", err)
+	}
+
+	wanted := make(map[string]bool)
+	for _, d := range all {
+		wanted[osv.Ecosystems[d.Ecosystem]+"/"+d.Name] = true
+	}
+	s := spinner.New(spinner.CharSets[9], 100*time.Millisecond)
+	s.Suffix = " Reading vulnerability database..."
+	if format == "table" {
+		s.Start()
+	}
+	db, err := osv.Load(dbDir, func(ecosystem, name string) bool {
+		return wanted[ecosystem+"/"+name]
+	})
+	s.Stop()
+	if errors.Is(err, osv.ErrNoDatabase) {
+		return fmt.Errorf("no vulnerability database in %s, download one from https://osv.dev and run project-starter audit import", dbDir)
+	}
+	if err != nil {
+		return fmt.Errorf("error reading vulnerability database: %v", err)
+	}
+
+	findings, unchecked := db.Check(all)
+	sort.SliceStable(findings, func(i, j int) bool {
+		if a, b := osv.SeverityRank(findings[i].Severity), osv.SeverityRank(findings[j].Severity); a != b {
+			return a < b
+		}
+		return findings[i].Score > findings[j].Score
+	})
+	packages := make(map[string]bool)
+	for _, f := range findings {
+		packages[f.Dependency.Ecosystem+"/"+f.Dependency.Name+"@"+f.Dependency.Version] = true
+	}
+
+	if format == "json" {
+		report := AuditReport{Name: filepath.Base(abs), Path: abs, Database: dbDir, Findings: findings, Unchecked: unchecked}
+		if report.Findings == nil {
+			report.Findings = []osv.Finding{}
+		}
+		if report.Unchecked == nil {
+			report.Unchecked = []deps.Dependency{}
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		color.Cyan("Checked %d dependencies against %d vulnerability records", len(all)-len(unchecked), db.Records)
+		if len(unchecked) > 0 {
+			color.Yellow("%d dependencies have no resolved version and were not checked, add a lockfile to audit them", len(unchecked))
+		}
+		if len(findings) == 0 {
+			color.Green("No known vulnerabilities found in %s", abs)
+			return nil
+		}
+		displayAuditFindings(w, findings)
+	}
+
+	if len(findings) > 0 {
+		return fmt.Errorf("found %d vulnerabilities in %d packages", len(findings), len(packages))
+	}
+	return nil
+}
+
+var severityColors = map[string]*color.Color{
+	"CRITICAL": color.New(color.FgRed, color.Bold),
+	"HIGH":     color.New(color.FgRed),
+	"MEDIUM":   color.New(color.FgYellow),
+	"LOW":      color.New(color.FgCyan),
+	"UNKNOWN":  color.New(color.FgWhite),
+}
+
+func displayAuditFindings(w io.Writer, findings []osv.Finding) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "\nPACKAGE\tVERSION\tECOSYSTEM\tID\tSEVERITY\tFIXED\tSUMMARY")
+	for _, f := range findings {
+		severity := f.Severity
+		if f.Score > 0 {
+			severity = fmt.Sprintf("%s %.1f", severity, f.Score)
+		}
+		fixed := f.Fixed
+		if fixed == "" {
+			fixed = "-"
+		}
+		summary := []rune(f.Summary)
+		if len(summary) > 60 {
+			summary = append(summary[:57], []rune("...")...)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", f.Dependency.Name, f.Dependency.Version, f.Dependency.Ecosystem, f.ID, severity, fixed, string(summary))
+	}
+	tw.Flush()
+
+	counts := make(map[string]int)
+	for _, f := range findings {
+		counts[f.Severity]++
+	}
+	var parts []string
+	for _, severity := range osv.Severities {
+		if counts[severity] > 0 {
+			parts = append(parts, severityColors[severity].Sprintf("%d %s", counts[severity], strings.ToLower(severity)))
+		}
+	}
+	fmt.Fprintln(w, "\n"+strings.Join(parts, ", "))
+}
+
+// ImportVulnerabilityDatabase extracts OSV exports, such as
+// https://osv-vulnerabilities.storage.googleapis.com/Go/all.zip, into
+// dbDir.
+func ImportVulnerabilityDatabase(zipPaths []string, dbDir string) error {
+	for _, zipPath := range zipPaths {
+		s := spinner.New(spinner.CharSets[9], 100*time.Millisecond)
+		s.Suffix = fmt.Sprintf(" Importing %s...", filepath.Base(zipPath))
+		s.Start()
+		imported, err := osv.Import(zipPath, dbDir)
+		s.Stop()
+		if err != nil {
+			return fmt.Errorf("error importing %s: %v", zipPath, err)
+		}
+		color.Green("Imported %d vulnerability records from %s", imported, zipPath)
+	}
+	return nil
+}
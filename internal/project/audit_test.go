@@ -0,0 +1,64 @@
+package project
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeAuditProject creates a Go module requiring golang.org/x/text at
+// version.
+func writeAuditProject(t *testing.T, version string) string {
+	t.Helper()
+	dir := t.TempDir()
+	goMod := "module example.com/app\n\ngo 1.22.0\n\nrequire golang.org/x/text " + version + "\n"
+	goSum := "golang.org/x/text " + version + " h1:test=\n"
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(goMod), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "go.sum"), []byte(goSum), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestAuditDependencies(t *testing.T) {
+	dbDir := filepath.Join("..", "osv", "testdata")
+
+	tests := []struct {
+		version  string
+		findings int
+		wantErr  string
+	}{
+		{"v0.3.7", 1, "found 1 vulnerabilities in 1 packages"},
+		{"v0.3.8", 0, ""},
+	}
+	for _, tt := range tests {
+		var out bytes.Buffer
+		err := AuditDependencies(&out, writeAuditProject(t, tt.version), dbDir, "json")
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("%s: AuditDependencies = %v, want no error", tt.version, err)
+		case tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr):
+			t.Errorf("%s: AuditDependencies = %v, want %q so the command fails", tt.version, err, tt.wantErr)
+		}
+
+		var report AuditReport
+		if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+			t.Fatalf("%s: report isn't JSON: %v\n%s", tt.version, err, out.String())
+		}
+		if len(report.Findings) != tt.findings {
+			t.Errorf("%s: report has %d findings, want %d", tt.version, len(report.Findings), tt.findings)
+		}
+	}
+}
+
+func TestAuditDependenciesWithoutDatabase(t *testing.T) {
+	err := AuditDependencies(&bytes.Buffer{}, writeAuditProject(t, "v0.3.7"), filepath.Join(t.TempDir(), "missing"), "json")
+	if err == nil || !strings.Contains(err.Error(), "no vulnerability database") {
+		t.Errorf("AuditDependencies without a database = %v, want it to fail", err)
+	}
+}
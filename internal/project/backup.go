@@ -1,20 +1,36 @@
 package project
 
 import (
-	"archive/zip"
 	"fmt"
-	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"time"
 
 	"github.com/AlecAivazis/survey/v2"
-	"github.com/briandowns/spinner"
+	"github.com/dustin/go-humanize"
 	"github.com/fatih/color"
 	"github.com/schollz/progressbar/v3"
+
+	"project-starter/internal/archive"
+	"project-starter/internal/secrets"
+	"project-starter/internal/walk"
 )
 
-func BackupProject(dirPath string) error {
+type BackupOptions struct {
+	Format archive.Format
+	Level  int
+	// Store names a configured backup store that overrides the project's
+	// configured destination.
+	Store string
+	// Quiet disables progress output, for backups running side by side.
+	Quiet bool
+	// Secrets is what to do with files containing secrets. When empty the
+	// user is asked, or quiet backups use the configured default.
+	Secrets secrets.Action
+}
+
+func BackupProject(dirPath string, opts BackupOptions) error {
 	projects, err := GetDirectories(dirPath)
 	if err != nil {
 		return fmt.Errorf("error getting projects: %v", err)
@@ -32,26 +48,84 @@ func BackupProject(dirPath string) error {
 		return fmt.Errorf("project selection failed: %v", err)
 	}
 
-	projectPath := filepath.Join(dirPath, selectedProject)
-	backupPath := filepath.Join(dirPath, selectedProject+"_backup_"+time.Now().Format("20060102_150405")+".zip")
+	if opts.Format == "" {
+		formats := make([]string, len(archive.Formats))
+		for i, f := range archive.Formats {
+			formats[i] = string(f)
+		}
+
+		var selectedFormat string
+		err = survey.AskOne(&survey.Select{
+			Message: "Select archive format:",
+			Options: formats,
+			Default: string(archive.FormatZip),
+		}, &selectedFormat)
+		if err != nil {
+			return fmt.Errorf("format selection failed: %v", err)
+		}
+		opts.Format = archive.Format(selectedFormat)
+	}
 
-	// Create and start a new spinner
-	s := spinner.New(spinner.CharSets[9], 100*time.Millisecond)
-	s.Suffix = " Preparing to backup project..."
-	s.Start()
+	_, err = CreateBackup(filepath.Join(dirPath, selectedProject), dirPath, opts)
+	return err
+}
+
+// BackupResult describes a finished backup.
+type BackupResult struct {
+	// Location is the archive path, or its URL in a backup store.
+	Location    string
+	Files       int
+	Bytes       int64
+	ArchiveSize int64
+	Duration    time.Duration
+	// Secrets is the number of files left out or redacted because they
+	// contain secrets.
+	Secrets int
+}
+
+// CreateBackup archives projectPath into a timestamped file in destDir.
+// Projects with a configured backup store are archived into the staging
+// directory and uploaded instead.
+func CreateBackup(projectPath, destDir string, opts BackupOptions) (*BackupResult, error) {
+	if opts.Format == "" {
+		opts.Format = archive.FormatZip
+	}
+	if err := opts.Format.ValidateLevel(opts.Level); err != nil {
+		return nil, err
+	}
 
-	// Count files for progress bar
-	fileCount, err := CountFiles(projectPath)
-	s.Stop()
+	ignored, err := loadBackupIgnore(projectPath)
 	if err != nil {
-		return fmt.Errorf("error counting files: %v", err)
+		return nil, err
+	}
+	filter, err := checkSecrets(projectPath, opts, ignored)
+	if err != nil {
+		return nil, err
+	}
+
+	projectName := filepath.Base(projectPath)
+	store, err := resolveStore(projectName, opts.Store)
+	if err != nil {
+		return nil, err
+	}
+	if store != nil {
+		if destDir, err = stagingDir(); err != nil {
+			return nil, err
+		}
 	}
 
-	// Create progress bar
-	bar := progressbar.NewOptions(fileCount,
+	backupPath := filepath.Join(destDir, projectName+"_backup_"+time.Now().Format("20060102_150405")+opts.Format.Extension())
+
+	// The file count is unknown until the walk finishes, so the bar shows
+	// bytes archived and throughput instead of a percentage
+	bar := progressbar.NewOptions64(-1,
 		progressbar.OptionEnableColorCodes(true),
 		progressbar.OptionSetWidth(15),
 		progressbar.OptionSetDescription("[cyan]Backing up project...[reset]"),
+		progressbar.OptionShowBytes(true),
+		progressbar.OptionShowIts(),
+		progressbar.OptionThrottle(100*time.Millisecond),
+		progressbar.OptionSetVisibility(!opts.Quiet),
 		progressbar.OptionSetTheme(progressbar.Theme{
 			Saucer:        "[green]=[reset]",
 			SaucerHead:    "[green]>[reset]",
@@ -60,79 +134,74 @@ func BackupProject(dirPath string) error {
 			BarEnd:        "]",
 		}))
 
-	// Create zip file
-	zipFile, err := os.Create(backupPath)
+	// Create archive file
+	backupFile, err := os.Create(backupPath)
 	if err != nil {
-		return fmt.Errorf("failed to create zip file: %v", err)
+		return nil, fmt.Errorf("failed to create backup file: %v", err)
 	}
-	defer zipFile.Close()
 
-	zipWriter := zip.NewWriter(zipFile)
-	defer zipWriter.Close()
-
-	// Walk through the project directory
-	err = filepath.Walk(projectPath, func(filePath string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		// Create zip header
-		header, err := zip.FileInfoHeader(info)
-		if err != nil {
-			return err
-		}
-
-		// Set compression
-		header.Method = zip.Deflate
-
-		// Set relative path
-		relPath, err := filepath.Rel(projectPath, filePath)
-		if err != nil {
-			return err
-		}
-		header.Name = relPath
+	start := time.Now()
+	progress, err := writeArchive(backupFile, projectPath, opts, ignored, filter, bar)
+	if closeErr := backupFile.Close(); err == nil {
+		err = closeErr
+	}
+	bar.Finish()
+	if err != nil {
+		os.Remove(backupPath)
+		return nil, fmt.Errorf("error creating backup: %v", err)
+	}
 
-		if info.IsDir() {
-			header.Name += "/"
-		}
+	elapsed := time.Since(start)
+	info, err := os.Stat(backupPath)
+	if err != nil {
+		return nil, err
+	}
+	result := &BackupResult{
+		Location:    backupPath,
+		Files:       progress.files,
+		Bytes:       progress.bytes,
+		ArchiveSize: info.Size(),
+		Secrets:     filter.files(),
+	}
 
-		// Create file in zip
-		writer, err := zipWriter.CreateHeader(header)
-		if err != nil {
-			return err
+	if !opts.Quiet {
+		if store == nil {
+			color.Green("\nBackup created successfully: %s", backupPath)
 		}
-
-		if !info.IsDir() {
-			file, err := os.Open(filePath)
-			if err != nil {
-				return err
+		color.Cyan("Archived %d files (%s) in %s, %s/s", progress.files, humanize.Bytes(uint64(progress.bytes)),
+			elapsed.Round(time.Millisecond), humanize.Bytes(uint64(float64(progress.bytes)/elapsed.Seconds())))
+		if filter != nil {
+			if len(filter.redact) > 0 {
+				color.Yellow("Redacted secrets in %d files", len(filter.redact))
 			}
-			defer file.Close()
-			_, err = io.Copy(writer, file)
-			if err != nil {
-				return err
+			if len(filter.exclude) > 0 {
+				color.Yellow("Left out %d files containing secrets", len(filter.exclude))
 			}
 		}
-
-		bar.Add(1)
-		return nil
-	})
-
-	if err != nil {
-		return fmt.Errorf("error creating backup: %v", err)
 	}
 
-	color.Green("\nBackup created successfully: %s", backupPath)
-	return nil
+	if store != nil {
+		result.Location, err = uploadBackup(store, backupPath, projectName, opts.Quiet)
+		if err != nil {
+			return nil, err
+		}
+	}
+	result.Duration = time.Since(start)
+
+	if err := recordBackup(projectPath, BackupRecord{
+		Time:     start,
+		Location: result.Location,
+		Size:     result.ArchiveSize,
+	}); err != nil {
+		return nil, fmt.Errorf("backup succeeded but recording it failed: %v", err)
+	}
+	return result, nil
 }
 
 func CountFiles(dir string) (int, error) {
 	count := 0
-	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		if !info.IsDir() {
+	err := walk.Walk(dir, walk.Options{}, func(_, _ string, d fs.DirEntry) error {
+		if !d.IsDir() {
 			count++
 		}
 		return nil
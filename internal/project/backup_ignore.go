@@ -0,0 +1,55 @@
+package project
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"project-starter/internal/ignore"
+)
+
+// BackupIgnoreFile lists paths, in gitignore syntax, that backups of a
+// project leave out.
+const BackupIgnoreFile = ".backupignore"
+
+// loadBackupIgnore returns the patterns of the project's .backupignore. A
+// project without one has an empty matcher.
+func loadBackupIgnore(projectPath string) (*ignore.Matcher, error) {
+	m := ignore.New(nil)
+	if err := m.AddFile(filepath.Join(projectPath, BackupIgnoreFile)); err != nil {
+		return nil, fmt.Errorf("error reading %s: %v", BackupIgnoreFile, err)
+	}
+	return m, nil
+}
+
+// addBackupIgnore appends relPath to the project's .backupignore, anchored
+// to the project root so only that path is left out.
+func addBackupIgnore(projectPath, relPath string, isDir bool) error {
+	line := "/" + strings.Trim(filepath.ToSlash(relPath), "/")
+	if isDir {
+		line += "/"
+	}
+
+	path := filepath.Join(projectPath, BackupIgnoreFile)
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for _, l := range strings.Split(string(existing), "\n") {
+		if strings.TrimSpace(l) == line {
+			return nil
+		}
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if len(existing) > 0 && !strings.HasSuffix(string(existing), "\n") {
+		line = "\n" + line
+	}
+	_, err = file.WriteString(line + "\n")
+	return err
+}
@@ -0,0 +1,135 @@
+package project
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"runtime"
+	"sync"
+
+	"github.com/schollz/progressbar/v3"
+
+	"project-starter/internal/archive"
+	"project-starter/internal/ignore"
+	"project-starter/internal/walk"
+)
+
+// backupWorkers is the number of files read and compressed in parallel.
+var backupWorkers = runtime.NumCPU()
+
+var errBackupAborted = errors.New("backup aborted")
+
+type backupJob struct {
+	path   string
+	name   string
+	info   fs.FileInfo
+	redact bool
+	result chan backupResult
+}
+
+type backupResult struct {
+	prepared *archive.Prepared
+	err      error
+}
+
+type backupProgress struct {
+	files int
+	bytes int64
+}
+
+// writeArchive walks projectPath once. A pool of workers reads and compresses
+// the files it finds while the calling goroutine appends the prepared entries
+// to the archive in walk order, so the archive layout is deterministic. Paths
+// matching ignored are left out, and files are left out or redacted as the
+// filter says.
+func writeArchive(w io.Writer, projectPath string, opts BackupOptions, ignored *ignore.Matcher, filter *secretFilter, bar *progressbar.ProgressBar) (backupProgress, error) {
+	var progress backupProgress
+	archiveWriter, err := archive.NewWriter(w, opts.Format, opts.Level)
+	if err != nil {
+		return progress, err
+	}
+
+	done := make(chan struct{})
+	jobs := make(chan backupJob)
+	// pending holds one result channel per entry in walk order and bounds how
+	// many prepared entries are buffered in memory
+	pending := make(chan chan backupResult, backupWorkers*4)
+	walkErr := make(chan error, 1)
+
+	go func() {
+		defer close(pending)
+		defer close(jobs)
+		skip := func(rel string, _ fs.DirEntry) bool { return ignored.MatchDir(rel) }
+		walkErr <- walk.Walk(projectPath, walk.Options{Skip: skip}, func(filePath, name string, d fs.DirEntry) error {
+			if filter.excluded(name) || (!d.IsDir() && ignored.MatchFile(name)) {
+				return nil
+			}
+
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+
+			result := make(chan backupResult, 1)
+			select {
+			case pending <- result:
+			case <-done:
+				return errBackupAborted
+			}
+			select {
+			case jobs <- backupJob{path: filePath, name: name, info: info, redact: filter.redacted(name), result: result}:
+			case <-done:
+				return errBackupAborted
+			}
+			return nil
+		})
+	}()
+
+	var workers sync.WaitGroup
+	for i := 0; i < backupWorkers; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for job := range jobs {
+				var prepared *archive.Prepared
+				var err error
+				if job.redact {
+					var data []byte
+					if data, err = filter.redactFile(job.path, job.name); err == nil {
+						prepared, err = archiveWriter.PrepareData(job.name, job.info, data)
+					}
+				} else {
+					prepared, err = archiveWriter.Prepare(job.path, job.name, job.info)
+				}
+				job.result <- backupResult{prepared: prepared, err: err}
+			}
+		}()
+	}
+
+	for result := range pending {
+		r := <-result
+		if r.err == nil && r.prepared != nil {
+			r.err = archiveWriter.Write(r.prepared)
+		}
+		if r.err != nil {
+			err = r.err
+			break
+		}
+		if r.prepared != nil && !r.prepared.Info.IsDir() {
+			progress.files++
+			progress.bytes += r.prepared.Size
+			bar.Add64(r.prepared.Size)
+		}
+	}
+	close(done)
+	workers.Wait()
+
+	if walkErr := <-walkErr; err == nil && walkErr != nil {
+		err = walkErr
+	}
+	if err != nil {
+		archiveWriter.Close()
+		return progress, err
+	}
+	return progress, archiveWriter.Close()
+}
@@ -0,0 +1,158 @@
+package project
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/briandowns/spinner"
+	"github.com/fatih/color"
+
+	"project-starter/internal/config"
+	"project-starter/internal/ignore"
+	"project-starter/internal/secrets"
+)
+
+// secretFilter lists the files a backup leaves out or archives redacted.
+// A nil filter archives everything as it is.
+type secretFilter struct {
+	scanner *secrets.Scanner
+	exclude map[string]bool
+	redact  map[string]bool
+}
+
+func (f *secretFilter) excluded(name string) bool {
+	return f != nil && f.exclude[name]
+}
+
+func (f *secretFilter) redacted(name string) bool {
+	return f != nil && f.redact[name]
+}
+
+// redactFile returns the content of the file at filePath with its secrets
+// replaced. The file is scanned again in case it changed since the check.
+func (f *secretFilter) redactFile(filePath, name string) ([]byte, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return secrets.Redact(data, f.scanner.ScanContent(name, data)), nil
+}
+
+// files is the number of files the filter changes.
+func (f *secretFilter) files() int {
+	if f == nil {
+		return 0
+	}
+	return len(f.exclude) + len(f.redact)
+}
+
+var secretActionLabels = map[string]secrets.Action{
+	"Exclude these files from the backup": secrets.ActionExclude,
+	"Redact the secrets in the backup":    secrets.ActionRedact,
+	"Abort the backup":                    secrets.ActionAbort,
+	"Back up everything anyway":           secrets.ActionIgnore,
+}
+
+// checkSecrets scans the project before it is archived and decides what to
+// do with files containing secrets: opts.Secrets if set, otherwise the user's
+// choice, or the configured default for quiet backups. Files the backup
+// leaves out anyway are not reported.
+func checkSecrets(projectPath string, opts BackupOptions, ignored *ignore.Matcher) (*secretFilter, error) {
+	if opts.Secrets == secrets.ActionIgnore {
+		return nil, nil
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	scanner, err := secrets.NewScanner(cfg.Secrets)
+	if err != nil {
+		return nil, err
+	}
+
+	s := spinner.New(spinner.CharSets[9], 100*time.Millisecond)
+	s.Suffix = " Scanning for secrets..."
+	if !opts.Quiet {
+		s.Start()
+	}
+	found, err := scanner.ScanDir(projectPath)
+	s.Stop()
+	if err != nil {
+		return nil, fmt.Errorf("error scanning for secrets: %v", err)
+	}
+	var findings []secrets.Finding
+	for _, f := range found {
+		if !ignored.Match(f.Path, false) {
+			findings = append(findings, f)
+		}
+	}
+	if len(findings) == 0 {
+		return nil, nil
+	}
+
+	action := opts.Secrets
+	if action == "" && opts.Quiet {
+		action = secrets.ActionExclude
+		if cfg.Secrets.OnBackup != "" {
+			if action, err = secrets.ParseAction(cfg.Secrets.OnBackup); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if !opts.Quiet {
+		color.Yellow("\nFound %d possible secrets:", len(findings))
+		displayFindings(findings)
+	}
+	if action == "" {
+		var selected string
+		err := survey.AskOne(&survey.Select{
+			Message: "What should the backup do with these files?",
+			Options: []string{
+				"Exclude these files from the backup",
+				"Redact the secrets in the backup",
+				"Abort the backup",
+				"Back up everything anyway",
+			},
+		}, &selected)
+		if err != nil {
+			return nil, fmt.Errorf("prompt failed: %v", err)
+		}
+		action = secretActionLabels[selected]
+	}
+
+	filter := &secretFilter{scanner: scanner, exclude: make(map[string]bool), redact: make(map[string]bool)}
+	switch action {
+	case secrets.ActionAbort:
+		return nil, fmt.Errorf("backup aborted: %d possible secrets found, run project-starter scan secrets %s for details", len(findings), projectPath)
+	case secrets.ActionIgnore:
+		return nil, nil
+	case secrets.ActionExclude:
+		for _, f := range findings {
+			filter.exclude[f.Path] = true
+		}
+	case secrets.ActionRedact:
+		for path, fileFindings := range secrets.ByPath(findings) {
+			redactable := false
+			for _, f := range fileFindings {
+				if f.InContent() {
+					redactable = true
+				}
+			}
+			if redactable {
+				filter.redact[path] = true
+			} else {
+				filter.exclude[path] = true
+			}
+		}
+	}
+	return filter, nil
+}
+
+func displayFindings(findings []secrets.Finding) {
+	for _, f := range findings {
+		color.Yellow("  %s", f)
+	}
+}
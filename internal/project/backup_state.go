@@ -0,0 +1,153 @@
+package project
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"project-starter/internal/config"
+)
+
+// BackupRecord is the last successful backup of a project.
+type BackupRecord struct {
+	Time     time.Time `json:"time"`
+	Location string    `json:"location"`
+	Size     int64     `json:"size"`
+}
+
+// backupStateMu serializes updates of the state file between concurrent
+// backups in this process.
+var backupStateMu sync.Mutex
+
+func backupStatePath() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "backups.json"), nil
+}
+
+// LoadBackupState returns the last backup of every project, keyed by
+// absolute project path.
+func LoadBackupState() (map[string]BackupRecord, error) {
+	path, err := backupStatePath()
+	if err != nil {
+		return nil, err
+	}
+
+	state := make(map[string]BackupRecord)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", path, err)
+	}
+	return state, nil
+}
+
+// LastBackup returns the last recorded backup of the project at projectPath.
+func LastBackup(projectPath string) (BackupRecord, bool, error) {
+	absPath, err := filepath.Abs(projectPath)
+	if err != nil {
+		return BackupRecord{}, false, err
+	}
+	state, err := LoadBackupState()
+	if err != nil {
+		return BackupRecord{}, false, err
+	}
+	record, ok := state[absPath]
+	return record, ok, nil
+}
+
+// NeedsBackup reports whether the project at projectPath changed since its
+// last recorded backup, along with the time it was last modified.
+func NeedsBackup(projectPath string) (bool, time.Time, error) {
+	lastModified, err := getLastModifiedTime(projectPath)
+	if err != nil {
+		return false, lastModified, err
+	}
+	record, ok, err := LastBackup(projectPath)
+	if err != nil {
+		return false, lastModified, err
+	}
+	return !ok || lastModified.After(record.Time), lastModified, nil
+}
+
+// lockBackupState takes the lock on the state file that the agent and CLI
+// backups share, and returns a function releasing it.
+func lockBackupState(path string) (func(), error) {
+	file, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFile(file); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to lock %s: %v", file.Name(), err)
+	}
+	return func() {
+		unlockFile(file)
+		file.Close()
+	}, nil
+}
+
+func recordBackup(projectPath string, record BackupRecord) error {
+	absPath, err := filepath.Abs(projectPath)
+	if err != nil {
+		return err
+	}
+	path, err := backupStatePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+
+	backupStateMu.Lock()
+	defer backupStateMu.Unlock()
+	// The agent and the CLI may record backups at the same time, so the
+	// state is read and replaced under a lock held across processes
+	unlock, err := lockBackupState(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	state, err := LoadBackupState()
+	if err != nil {
+		return err
+	}
+	state[absPath] = record
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so readers never see a partial file
+	tmp, err := os.CreateTemp(filepath.Dir(path), "backups-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,117 @@
+package project
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/schollz/progressbar/v3"
+
+	"project-starter/internal/config"
+	"project-starter/internal/storage"
+)
+
+// resolveStore returns the backup store for a project, or nil when its
+// backups are kept next to the project.
+func resolveStore(projectName, override string) (storage.BackupStore, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	var storeConfig config.StoreConfig
+	if override != "" {
+		storeConfig, err = cfg.Store(override)
+	} else {
+		var ok bool
+		_, storeConfig, ok, err = cfg.StoreFor(projectName)
+		if err == nil && !ok {
+			return nil, nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return storage.New(storeConfig)
+}
+
+// stagingDir holds archives waiting to be uploaded to a backup store.
+func stagingDir() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	dir = filepath.Join(dir, "staging")
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return "", fmt.Errorf("failed to create staging directory: %v", err)
+	}
+	return dir, nil
+}
+
+// UploadBackup uploads an existing archive to a backup store, resuming an
+// earlier interrupted upload of the same archive. An empty storeName uses the
+// store configured for the archive's project.
+func UploadBackup(archivePath, storeName string) error {
+	projectName := backupProjectName(archivePath)
+	store, err := resolveStore(projectName, storeName)
+	if err != nil {
+		return err
+	}
+	if store == nil {
+		return fmt.Errorf("no backup store configured for %s, use --store", projectName)
+	}
+	_, err = uploadBackup(store, archivePath, projectName, false)
+	return err
+}
+
+// backupProjectName recovers the project name from an archive file name.
+func backupProjectName(archivePath string) string {
+	name := filepath.Base(archivePath)
+	if i := strings.LastIndex(name, "_backup_"); i > 0 {
+		return name[:i]
+	}
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
+func uploadBackup(store storage.BackupStore, archivePath, projectName string, quiet bool) (string, error) {
+	info, err := os.Stat(archivePath)
+	if err != nil {
+		return "", err
+	}
+
+	bar := progressbar.NewOptions64(info.Size(),
+		progressbar.OptionEnableColorCodes(true),
+		progressbar.OptionSetWidth(15),
+		progressbar.OptionSetDescription(fmt.Sprintf("[cyan]Uploading to %s...[reset]", store)),
+		progressbar.OptionShowBytes(true),
+		progressbar.OptionThrottle(100*time.Millisecond),
+		progressbar.OptionSetVisibility(!quiet),
+		progressbar.OptionSetTheme(progressbar.Theme{
+			Saucer:        "[green]=[reset]",
+			SaucerHead:    "[green]>[reset]",
+			SaucerPadding: " ",
+			BarStart:      "[",
+			BarEnd:        "]",
+		}))
+
+	key := projectName + "/" + filepath.Base(archivePath)
+	err = store.Upload(context.Background(), archivePath, key, func(n int64) {
+		bar.Add64(n)
+	})
+	bar.Finish()
+	if err != nil {
+		return "", fmt.Errorf("upload to %s failed, the archive was kept at %s; resume with 'project-starter backup upload %s': %v",
+			store, archivePath, archivePath, err)
+	}
+
+	os.Remove(archivePath)
+	location := strings.TrimSuffix(store.String(), "/") + "/" + key
+	if !quiet {
+		color.Green("\nBackup uploaded successfully: %s", location)
+	}
+	return location, nil
+}
@@ -0,0 +1,164 @@
+package project
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/fatih/color"
+)
+
+type BatchOptions struct {
+	// ChangedSince limits the batch to projects modified within this window.
+	// Zero means no limit.
+	ChangedSince time.Duration
+	// Jobs is the number of projects backed up at the same time.
+	Jobs int
+}
+
+type batchResult struct {
+	project string
+	status  string
+	result  *BackupResult
+	err     error
+}
+
+// BackupWorkspace backs up every project directory under root that changed
+// since its last backup, several at a time, and prints a summary table.
+func BackupWorkspace(root string, opts BackupOptions, batch BatchOptions) error {
+	projects, err := GetDirectories(root)
+	if err != nil {
+		return fmt.Errorf("error getting projects: %v", err)
+	}
+	state, err := LoadBackupState()
+	if err != nil {
+		return err
+	}
+
+	jobs := batch.Jobs
+	if jobs < 1 {
+		jobs = 1
+	}
+	opts.Quiet = true
+
+	var names []string
+	for _, name := range projects {
+		if !strings.HasPrefix(name, ".") {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		color.Yellow("No projects found in %s", root)
+		return nil
+	}
+	color.Cyan("Backing up %d projects in %s with %d jobs...", len(names), root, jobs)
+
+	results := make([]batchResult, len(names))
+	sem := make(chan struct{}, jobs)
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, name string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = backupIfChanged(filepath.Join(root, name), root, opts, batch, state)
+			printBatchProgress(results[i])
+		}(i, name)
+	}
+	wg.Wait()
+
+	return displayBatchSummary(results)
+}
+
+func backupIfChanged(projectPath, destDir string, opts BackupOptions, batch BatchOptions, state map[string]BackupRecord) batchResult {
+	r := batchResult{project: filepath.Base(projectPath)}
+
+	lastModified, err := getLastModifiedTime(projectPath)
+	if err != nil {
+		r.status, r.err = "failed", err
+		return r
+	}
+	if batch.ChangedSince > 0 && time.Since(lastModified) > batch.ChangedSince {
+		r.status = "skipped, not changed in " + formatAge(batch.ChangedSince)
+		return r
+	}
+	if absPath, err := filepath.Abs(projectPath); err == nil {
+		if record, ok := state[absPath]; ok && !lastModified.After(record.Time) {
+			r.status = "skipped, unchanged since last backup"
+			return r
+		}
+	}
+
+	r.result, r.err = CreateBackup(projectPath, destDir, opts)
+	if r.err != nil {
+		r.status = "failed"
+	} else {
+		r.status = "backed up"
+	}
+	return r
+}
+
+var batchOutputMu sync.Mutex
+
+func printBatchProgress(r batchResult) {
+	batchOutputMu.Lock()
+	defer batchOutputMu.Unlock()
+
+	switch {
+	case r.err != nil:
+		color.Red("  %s: %v", r.project, r.err)
+	case r.result != nil:
+		color.Green("  %s: %s", r.project, r.result.Location)
+	}
+}
+
+func displayBatchSummary(results []batchResult) error {
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROJECT\tSTATUS\tFILES\tDATA\tARCHIVE\tDURATION")
+
+	var backedUp, skipped, failed int
+	var totalBytes, totalArchive int64
+	var totalDuration time.Duration
+	for _, r := range results {
+		if r.result == nil {
+			fmt.Fprintf(w, "%s\t%s\t\t\t\t\n", r.project, r.status)
+			if r.err != nil {
+				failed++
+			} else {
+				skipped++
+			}
+			continue
+		}
+
+		backedUp++
+		totalBytes += r.result.Bytes
+		totalArchive += r.result.ArchiveSize
+		totalDuration += r.result.Duration
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%s\n", r.project, r.status, r.result.Files,
+			humanize.Bytes(uint64(r.result.Bytes)), humanize.Bytes(uint64(r.result.ArchiveSize)),
+			r.result.Duration.Round(time.Millisecond))
+	}
+	fmt.Fprintf(w, "TOTAL\t%d backed up, %d skipped, %d failed\t\t%s\t%s\t%s\n", backedUp, skipped, failed,
+		humanize.Bytes(uint64(totalBytes)), humanize.Bytes(uint64(totalArchive)), totalDuration.Round(time.Millisecond))
+	w.Flush()
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d projects failed to back up", failed, len(results))
+	}
+	return nil
+}
+
+// formatAge prints whole days as "7d" and anything else as a duration.
+func formatAge(d time.Duration) string {
+	if d >= 24*time.Hour && d%(24*time.Hour) == 0 {
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	}
+	return d.String()
+}
@@ -0,0 +1,278 @@
+package project
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/briandowns/spinner"
+	"github.com/dustin/go-humanize"
+	"github.com/fatih/color"
+
+	"project-starter/internal/archive"
+)
+
+// archiveTree indexes archive entries by directory for browsing.
+type archiveTree struct {
+	entries  map[string]archive.Entry
+	children map[string][]string
+}
+
+func newArchiveTree(entries []archive.Entry) *archiveTree {
+	t := &archiveTree{
+		entries:  make(map[string]archive.Entry),
+		children: make(map[string][]string),
+	}
+	for _, entry := range entries {
+		t.add(entry)
+	}
+	for dir := range t.children {
+		sort.Slice(t.children[dir], func(i, j int) bool {
+			a, b := t.entries[t.children[dir][i]], t.entries[t.children[dir][j]]
+			if a.IsDir() != b.IsDir() {
+				return a.IsDir()
+			}
+			return a.Name < b.Name
+		})
+	}
+	return t
+}
+
+func (t *archiveTree) add(entry archive.Entry) {
+	if _, ok := t.entries[entry.Name]; ok {
+		return
+	}
+	t.entries[entry.Name] = entry
+
+	// Archives from other tools may leave out directory entries
+	parent := path.Dir(entry.Name)
+	if parent == "." {
+		parent = ""
+	} else {
+		t.add(archive.Entry{Name: parent, Mode: fs.ModeDir | 0755, ModTime: entry.ModTime})
+	}
+	t.children[parent] = append(t.children[parent], entry.Name)
+}
+
+func loadArchiveTree(archivePath string) (*archiveTree, error) {
+	s := spinner.New(spinner.CharSets[9], 100*time.Millisecond)
+	s.Suffix = " Reading backup..."
+	s.Start()
+	entries, err := archive.ReadEntries(archivePath)
+	s.Stop()
+	if err != nil {
+		return nil, fmt.Errorf("error reading backup: %v", err)
+	}
+	return newArchiveTree(entries), nil
+}
+
+// ListBackup prints the entries of dir inside a backup archive like ls -l.
+func ListBackup(archivePath, dir string) error {
+	entries, err := archive.ReadEntries(archivePath)
+	if err != nil {
+		return fmt.Errorf("error reading backup: %v", err)
+	}
+	tree := newArchiveTree(entries)
+
+	dir = strings.Trim(path.Clean("/"+dir), "/")
+	if entry, ok := tree.entries[dir]; ok && !entry.IsDir() {
+		fmt.Println(formatArchiveEntry(entry))
+		return nil
+	}
+	if _, ok := tree.children[dir]; !ok && dir != "" {
+		return fmt.Errorf("%s not found in backup", dir)
+	}
+	for _, name := range tree.children[dir] {
+		fmt.Println(formatArchiveEntry(tree.entries[name]))
+	}
+	return nil
+}
+
+func formatArchiveEntry(entry archive.Entry) string {
+	name := path.Base(entry.Name)
+	size := humanize.Bytes(uint64(entry.Size))
+	switch {
+	case entry.IsDir():
+		name += "/"
+		size = "-"
+	case entry.Link != "":
+		name += " -> " + entry.Link
+	}
+	return fmt.Sprintf("%s  %8s  %s  %s", entry.Mode, size, entry.ModTime.Local().Format("2006-01-02 15:04"), name)
+}
+
+// BrowseBackup lets the user walk the directory tree of a backup archive and
+// extract individual files without restoring the whole project.
+func BrowseBackup(archivePath string) error {
+	tree, err := loadArchiveTree(archivePath)
+	if err != nil {
+		return err
+	}
+
+	currentDir := ""
+	for {
+		options := []string{
+			"[Extract from this directory]",
+			"[Go back]",
+			"[Done]",
+		}
+		names := make(map[string]string)
+		for _, name := range tree.children[currentDir] {
+			label := browseLabel(tree.entries[name])
+			names[label] = name
+			options = append(options, label)
+		}
+
+		var selected string
+		err := survey.AskOne(&survey.Select{
+			Message:  fmt.Sprintf("Backup: %s\nCurrent directory: /%s\nSelect directory, file or action:", archivePath, currentDir),
+			Options:  options,
+			PageSize: 15,
+		}, &selected)
+		if err != nil {
+			return fmt.Errorf("prompt failed: %v", err)
+		}
+
+		switch selected {
+		case "[Extract from this directory]":
+			if err := extractFromDirectory(archivePath, tree, currentDir); err != nil {
+				color.Red("Error extracting files: %v", err)
+			}
+		case "[Go back]":
+			if currentDir != "" {
+				currentDir = path.Dir(currentDir)
+				if currentDir == "." {
+					currentDir = ""
+				}
+			}
+		case "[Done]":
+			return nil
+		default:
+			entry := tree.entries[names[selected]]
+			if entry.IsDir() {
+				currentDir = entry.Name
+			} else if err := fileActions(archivePath, entry, currentDir); err != nil {
+				color.Red("Error: %v", err)
+			}
+		}
+	}
+}
+
+func browseLabel(entry archive.Entry) string {
+	name := path.Base(entry.Name)
+	switch {
+	case entry.IsDir():
+		return name + "/"
+	case entry.Link != "":
+		return name + " -> " + entry.Link
+	default:
+		return fmt.Sprintf("%s (%s)", name, humanize.Bytes(uint64(entry.Size)))
+	}
+}
+
+func fileActions(archivePath string, entry archive.Entry, currentDir string) error {
+	var action string
+	err := survey.AskOne(&survey.Select{
+		Message: fmt.Sprintf("%s:", entry.Name),
+		Options: []string{"Extract", "Print contents", "Cancel"},
+	}, &action)
+	if err != nil {
+		return err
+	}
+
+	switch action {
+	case "Extract":
+		return extractEntries(archivePath, []string{entry.Name}, currentDir)
+	case "Print contents":
+		fmt.Println()
+		if err := archive.Cat(archivePath, entry.Name, os.Stdout); err != nil {
+			return err
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+func extractFromDirectory(archivePath string, tree *archiveTree, currentDir string) error {
+	var options []string
+	names := make(map[string]string)
+	for _, name := range tree.children[currentDir] {
+		label := browseLabel(tree.entries[name])
+		names[label] = name
+		options = append(options, label)
+	}
+	if len(options) == 0 {
+		color.Yellow("This directory is empty.")
+		return nil
+	}
+
+	var selected []string
+	err := survey.AskOne(&survey.MultiSelect{
+		Message:  "Select files and directories to extract:",
+		Options:  options,
+		PageSize: 15,
+	}, &selected)
+	if err != nil {
+		return err
+	}
+	if len(selected) == 0 {
+		return nil
+	}
+
+	entryNames := make([]string, len(selected))
+	for i, label := range selected {
+		entryNames[i] = names[label]
+	}
+	return extractEntries(archivePath, entryNames, currentDir)
+}
+
+func extractEntries(archivePath string, names []string, base string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	var dest string
+	err = survey.AskOne(&survey.Input{
+		Message: "Extract to:",
+		Default: cwd,
+	}, &dest)
+	if err != nil {
+		return err
+	}
+
+	existing, err := archive.Conflicts(archivePath, names, base, dest)
+	if err != nil {
+		return err
+	}
+	if len(existing) > 0 {
+		overwrite := false
+		err = survey.AskOne(&survey.Confirm{
+			Message: fmt.Sprintf("%s already exists in %s. Overwrite?", summarizePaths(existing), dest),
+		}, &overwrite)
+		if err != nil || !overwrite {
+			return err
+		}
+	}
+
+	written, err := archive.Extract(archivePath, names, base, dest)
+	if err != nil {
+		return err
+	}
+	color.Green("Extracted %d entries to %s", len(written), dest)
+	return nil
+}
+
+// summarizePaths lists the first few paths and how many more there are.
+func summarizePaths(paths []string) string {
+	const shown = 5
+	if len(paths) <= shown {
+		return strings.Join(paths, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(paths[:shown], ", "), len(paths)-shown)
+}
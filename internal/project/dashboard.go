@@ -0,0 +1,234 @@
+package project
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/briandowns/spinner"
+	"github.com/dustin/go-humanize"
+	"github.com/fatih/color"
+
+	"project-starter/internal/gitstats"
+)
+
+// DashboardSorts are the columns the dashboard can be sorted by.
+var DashboardSorts = []string{"name", "type", "size", "files", "modified", "backup"}
+
+type DashboardOptions struct {
+	// Sort is one of DashboardSorts. Sizes and file counts sort largest
+	// first, modification and backup times oldest first.
+	Sort string
+	// Stale limits the dashboard to projects not modified within this
+	// window. Zero shows every project.
+	Stale time.Duration
+}
+
+// dashboardWorkers is the number of projects scanned at the same time.
+const dashboardWorkers = 4
+
+type dashboardEntry struct {
+	name  string
+	path  string
+	kind  string
+	usage diskUsage
+	// git is nil for projects that are not a git repository
+	git        *gitstats.Stats
+	lastBackup time.Time
+	err        error
+}
+
+// ShowDashboard prints one row for every project under root with its type,
+// size, last change, git state and last backup.
+func ShowDashboard(root string, opts DashboardOptions) error {
+	if opts.Sort == "" {
+		opts.Sort = "name"
+	}
+	if !validDashboardSort(opts.Sort) {
+		return fmt.Errorf("unknown sort %q, expected one of %s", opts.Sort, strings.Join(DashboardSorts, ", "))
+	}
+
+	projects, err := GetDirectories(root)
+	if err != nil {
+		return fmt.Errorf("error getting projects: %v", err)
+	}
+	state, err := LoadBackupState()
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	for _, name := range projects {
+		if !strings.HasPrefix(name, ".") {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		color.Yellow("No projects found in %s", root)
+		return nil
+	}
+
+	s := spinner.New(spinner.CharSets[9], 100*time.Millisecond)
+	s.Suffix = fmt.Sprintf(" Scanning %d projects in %s...", len(names), root)
+	s.Start()
+
+	entries := make([]dashboardEntry, len(names))
+	sem := make(chan struct{}, dashboardWorkers)
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, name string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			entries[i] = scanDashboardEntry(filepath.Join(root, name), state)
+		}(i, name)
+	}
+	wg.Wait()
+	s.Stop()
+
+	if opts.Stale > 0 {
+		var stale []dashboardEntry
+		for _, e := range entries {
+			if e.err != nil || time.Since(e.usage.lastModified) > opts.Stale {
+				stale = append(stale, e)
+			}
+		}
+		entries = stale
+		if len(entries) == 0 {
+			color.Green("No projects in %s are older than %s", root, formatAge(opts.Stale))
+			return nil
+		}
+	}
+
+	sortDashboard(entries, opts.Sort)
+	displayDashboard(entries)
+	return nil
+}
+
+func validDashboardSort(column string) bool {
+	for _, s := range DashboardSorts {
+		if s == column {
+			return true
+		}
+	}
+	return false
+}
+
+func scanDashboardEntry(projectPath string, state map[string]BackupRecord) dashboardEntry {
+	e := dashboardEntry{name: filepath.Base(projectPath), path: projectPath}
+	e.usage, e.err = getDiskUsage(projectPath)
+	if e.err != nil {
+		return e
+	}
+	e.kind = DetectType(projectPath)
+
+	git, err := gitstats.ReadStatus(projectPath)
+	if err != nil && !errors.Is(err, gitstats.ErrNotRepository) {
+		e.err = err
+		return e
+	}
+	e.git = git
+
+	if absPath, err := filepath.Abs(projectPath); err == nil {
+		if record, ok := state[absPath]; ok {
+			e.lastBackup = record.Time
+		}
+	}
+	return e
+}
+
+func sortDashboard(entries []dashboardEntry, column string) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		switch column {
+		case "type":
+			if a.kind != b.kind {
+				return a.kind < b.kind
+			}
+		case "size":
+			if a.usage.size != b.usage.size {
+				return a.usage.size > b.usage.size
+			}
+		case "files":
+			if a.usage.files != b.usage.files {
+				return a.usage.files > b.usage.files
+			}
+		case "modified":
+			if !a.usage.lastModified.Equal(b.usage.lastModified) {
+				return a.usage.lastModified.Before(b.usage.lastModified)
+			}
+		case "backup":
+			// Projects that were never backed up have a zero time, so
+			// they come first
+			if !a.lastBackup.Equal(b.lastBackup) {
+				return a.lastBackup.Before(b.lastBackup)
+			}
+		}
+		return strings.ToLower(a.name) < strings.ToLower(b.name)
+	})
+}
+
+func displayDashboard(entries []dashboardEntry) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROJECT\tTYPE\tSIZE\tFILES\tMODIFIED\tGIT\tLAST BACKUP")
+
+	var totalSize int64
+	var totalFiles, failed int
+	for _, e := range entries {
+		if e.err != nil {
+			fmt.Fprintf(w, "%s\t-\t-\t-\t-\t-\t-\n", e.name)
+			failed++
+			continue
+		}
+		totalSize += e.usage.size
+		totalFiles += e.usage.files
+
+		kind := e.kind
+		if kind == "" {
+			kind = "-"
+		}
+		backup := "never"
+		if !e.lastBackup.IsZero() {
+			backup = humanize.Time(e.lastBackup)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\t%s\n", e.name, kind, humanize.Bytes(uint64(e.usage.size)),
+			e.usage.files, humanize.Time(e.usage.lastModified), formatGitState(e.git), backup)
+	}
+	fmt.Fprintf(w, "TOTAL\t%d projects\t%s\t%d\t\t\t\n", len(entries), humanize.Bytes(uint64(totalSize)), totalFiles)
+	w.Flush()
+
+	if failed > 0 {
+		fmt.Println()
+		for _, e := range entries {
+			if e.err != nil {
+				color.Red("%s: %v", e.name, e.err)
+			}
+		}
+	}
+}
+
+// formatGitState summarizes the working tree and upstream state, such as
+// "dirty, 2 ahead".
+func formatGitState(git *gitstats.Stats) string {
+	if git == nil {
+		return "-"
+	}
+	parts := []string{"clean"}
+	if git.Dirty {
+		parts[0] = "dirty"
+	}
+	if git.Ahead > 0 {
+		parts = append(parts, fmt.Sprintf("%d ahead", git.Ahead))
+	}
+	if git.Behind > 0 {
+		parts = append(parts, fmt.Sprintf("%d behind", git.Behind))
+	}
+	return strings.Join(parts, ", ")
+}
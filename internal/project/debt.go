@@ -0,0 +1,203 @@
+package project
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/briandowns/spinner"
+	"github.com/dustin/go-humanize"
+	"github.com/fatih/color"
+
+	"project-starter/internal/debt"
+	"project-starter/internal/gitstats"
+	"project-starter/internal/ignore"
+	"project-starter/internal/walk"
+)
+
+// DebtReport is the machine readable tech-debt report of a project.
+type DebtReport struct {
+	Name    string        `json:"name"`
+	Path    string        `json:"path"`
+	Markers []debt.Marker `json:"markers"`
+	Summary debt.Summary  `json:"summary"`
+}
+
+// PrintDebt writes the TODO, FIXME, HACK and XXX markers of the project at
+// projectPath to w as json, or as a table grouped by directory with the
+// author and age of each marker.
+func PrintDebt(w io.Writer, projectPath, format string) error {
+	if format != "table" && format != "json" {
+		return fmt.Errorf("unknown format %q, expected table or json", format)
+	}
+	abs, err := filepath.Abs(projectPath)
+	if err != nil {
+		return err
+	}
+
+	s := spinner.New(spinner.CharSets[9], 100*time.Millisecond)
+	s.Suffix = " Scanning for TODO and FIXME markers..."
+	if format == "table" {
+		s.Start()
+	}
+	markers, err := findDebt(abs)
+	s.Stop()
+	if err != nil {
+		return fmt.Errorf("error scanning for markers: %v", err)
+	}
+
+	report := DebtReport{Name: filepath.Base(abs), Path: abs, Markers: markers, Summary: debt.Summarize(markers)}
+	if format == "json" {
+		if report.Markers == nil {
+			report.Markers = []debt.Marker{}
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+
+	if len(markers) == 0 {
+		color.Green("No TODO or FIXME markers found in %s", abs)
+		return nil
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for i, m := range markers {
+		if i == 0 || m.Dir() != markers[i-1].Dir() {
+			tw.Flush()
+			color.Cyan("\n%s/ (%d)", m.Dir(), report.Summary.Directories[m.Dir()])
+		}
+		author := m.Author
+		if author == "" {
+			author = "-"
+		}
+		fmt.Fprintf(tw, "  %s:%d\t%s\t%s\t%s\t%s\n", path.Base(m.Path), m.Line, m.Kind, author, humanize.Time(m.Date), m.Text)
+	}
+	tw.Flush()
+
+	color.Cyan("\n%s", formatDebtSummary(report.Summary))
+	return nil
+}
+
+// formatDebtSummary describes a summary like "12 markers in 5 files (TODO 8,
+// FIXME 4), oldest 2 years ago".
+func formatDebtSummary(s debt.Summary) string {
+	return fmt.Sprintf("%d markers in %d files (%s), oldest %s", s.Total, s.Files, formatCounts(s.Kinds), humanize.Time(s.Oldest))
+}
+
+// findDebt returns the markers in the source files of the project at
+// projectPath, skipping files its .gitignore excludes, sorted by directory.
+func findDebt(projectPath string) ([]debt.Marker, error) {
+	ignored, err := ignore.Load(projectPath, ".gitignore")
+	if err != nil {
+		return nil, err
+	}
+
+	type source struct{ path, rel string }
+	sources := make(chan source)
+	var (
+		workers  sync.WaitGroup
+		mu       sync.Mutex
+		markers  []debt.Marker
+		firstErr error
+	)
+	for i := 0; i < runtime.NumCPU(); i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for s := range sources {
+				found, err := debt.ScanFile(s.path, s.rel)
+				mu.Lock()
+				if err != nil && firstErr == nil {
+					firstErr = err
+				}
+				markers = append(markers, found...)
+				mu.Unlock()
+			}
+		}()
+	}
+
+	err = walk.Walk(projectPath, walk.Options{
+		Skip: func(rel string, d fs.DirEntry) bool { return ignored.MatchDir(rel) },
+	}, func(filePath, rel string, d fs.DirEntry) error {
+		if d.Type().IsRegular() && !ignored.MatchFile(rel) {
+			sources <- source{filePath, rel}
+		}
+		return nil
+	})
+	close(sources)
+	workers.Wait()
+	if err == nil {
+		err = firstErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	blameMarkers(projectPath, markers)
+	debt.Sort(markers)
+	return markers, nil
+}
+
+// blameMarkers sets the author and date of markers from git blame when the
+// project is in a git repository. Markers in files git doesn't track, and
+// on uncommitted lines, keep the modification time of their file.
+func blameMarkers(projectPath string, markers []debt.Marker) {
+	if len(markers) == 0 || !gitstats.InWorkTree(projectPath) {
+		return
+	}
+	byFile := make(map[string][]*debt.Marker)
+	for i := range markers {
+		byFile[markers[i].Path] = append(byFile[markers[i].Path], &markers[i])
+	}
+
+	files := make(chan string)
+	var workers sync.WaitGroup
+	for i := 0; i < runtime.NumCPU(); i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for file := range files {
+				lines, err := gitstats.Blame(projectPath, filepath.FromSlash(file))
+				if err != nil {
+					continue
+				}
+				// Each file is handled by one worker, so its markers can be
+				// updated without locking
+				for _, m := range byFile[file] {
+					if m.Line <= len(lines) && lines[m.Line-1].Committed {
+						m.Author = lines[m.Line-1].Author
+						m.Date = lines[m.Line-1].Time
+					}
+				}
+			}
+		}()
+	}
+	for file := range byFile {
+		files <- file
+	}
+	close(files)
+	workers.Wait()
+}
+
+// displayDebt shows the tech-debt section of the project statistics.
+func displayDebt(w io.Writer, s debt.Summary) {
+	yellow := color.New(color.FgYellow)
+	color.New(color.FgCyan).Fprintln(w, "\nTech Debt:")
+	yellow.Fprintln(w, formatDebtSummary(s))
+	dirs := formatCounts(s.Directories)
+	if parts := strings.SplitN(dirs, ", ", debtDirectories+1); len(parts) > debtDirectories {
+		dirs = strings.Join(parts[:debtDirectories], ", ") + ", ..."
+	}
+	yellow.Fprintf(w, "By directory: %s\n", dirs)
+}
+
+// debtDirectories is how many directories the statistics list.
+const debtDirectories = 5
@@ -0,0 +1,101 @@
+package project
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/fatih/color"
+
+	"project-starter/internal/deps"
+)
+
+// DependencyReport is the machine readable dependency inventory of a
+// project.
+type DependencyReport struct {
+	Name         string            `json:"name"`
+	Path         string            `json:"path"`
+	Dependencies []deps.Dependency `json:"dependencies"`
+	Summary      deps.Summary      `json:"summary"`
+}
+
+// PrintDependencies writes the dependencies of the project at projectPath
+// to w as a table or json. With directOnly, transitive dependencies are left
+// out of the list but still counted in the summary.
+func PrintDependencies(w io.Writer, projectPath, format string, directOnly bool) error {
+	if format != "table" && format != "json" {
+		return fmt.Errorf("unknown format %q, expected table or json", format)
+	}
+	abs, err := filepath.Abs(projectPath)
+	if err != nil {
+		return err
+	}
+	all, err := deps.Read(abs)
+	if err != nil {
+		return fmt.Errorf("error reading dependencies: %v", err)
+	}
+
+	report := DependencyReport{Name: filepath.Base(abs), Path: abs, Dependencies: []deps.Dependency{}, Summary: deps.Summarize(all)}
+	for _, d := range all {
+		if d.Direct || !directOnly {
+			report.Dependencies = append(report.Dependencies, d)
+		}
+	}
+
+	if format == "json" {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+
+	if len(all) == 0 {
+		color.Yellow("No dependencies found in %s", abs)
+		return nil
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tVERSION\tECOSYSTEM\tTYPE\tLICENSE")
+	for _, d := range report.Dependencies {
+		kind := "transitive"
+		if d.Direct {
+			kind = "direct"
+		}
+		if d.Dev {
+			kind += ", dev"
+		}
+		license := d.License
+		if license == "" {
+			license = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", d.Name, d.Version, d.Ecosystem, kind, license)
+	}
+	tw.Flush()
+
+	s := report.Summary
+	color.Cyan("\n%d direct (%d for development), %d transitive", s.Direct, s.Dev, s.Transitive)
+	color.Cyan("Licenses: %s", formatCounts(s.Licenses))
+	return nil
+}
+
+// formatCounts lists names by their count, most first, such as
+// "MIT 40, Apache-2.0 12, unknown 3".
+func formatCounts(counts map[string]int) string {
+	licenses := make([]string, 0, len(counts))
+	for license := range counts {
+		licenses = append(licenses, license)
+	}
+	sort.Slice(licenses, func(i, j int) bool {
+		if counts[licenses[i]] != counts[licenses[j]] {
+			return counts[licenses[i]] > counts[licenses[j]]
+		}
+		return licenses[i] < licenses[j]
+	})
+	parts := make([]string, len(licenses))
+	for i, license := range licenses {
+		parts[i] = fmt.Sprintf("%s %d", license, counts[license])
+	}
+	return strings.Join(parts, ", ")
+}
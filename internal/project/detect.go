@@ -0,0 +1,64 @@
+package project
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// projectMarkers map files at the root of a project to its type, most
+// specific first. The names match the templates so created projects are
+// recognized as what they were created as.
+var projectMarkers = []struct {
+	projectType string
+	files       []string
+}{
+	{"Next.js", []string{"next.config.js", "next.config.mjs", "next.config.ts"}},
+	{"Vite", []string{"vite.config.js", "vite.config.mjs", "vite.config.ts"}},
+	{"Node.js", []string{"package.json"}},
+	{"Go", []string{"go.mod"}},
+	{"Rust", []string{"Cargo.toml"}},
+	{"Python", []string{"pyproject.toml", "requirements.txt", "setup.py", "Pipfile"}},
+	{"Java", []string{"pom.xml", "build.gradle", "build.gradle.kts"}},
+	{".NET", []string{"*.sln", "*.csproj"}},
+	{"Ruby", []string{"Gemfile"}},
+	{"PHP", []string{"composer.json"}},
+}
+
+// DetectType guesses the kind of project at projectPath from the files at
+// its root. It returns an empty string when nothing is recognized.
+func DetectType(projectPath string) string {
+	for _, marker := range projectMarkers {
+		for _, pattern := range marker.files {
+			matches, _ := filepath.Glob(filepath.Join(projectPath, pattern))
+			if len(matches) == 0 {
+				continue
+			}
+			// Vue projects are built with Vite
+			if marker.projectType == "Vite" && hasNPMDependency(projectPath, "vue") {
+				return "Vue"
+			}
+			return marker.projectType
+		}
+	}
+	return ""
+}
+
+func hasNPMDependency(projectPath, name string) bool {
+	data, err := os.ReadFile(filepath.Join(projectPath, "package.json"))
+	if err != nil {
+		return false
+	}
+	var pkg struct {
+		Dependencies    map[string]string `json:"dependencies"`
+		DevDependencies map[string]string `json:"devDependencies"`
+	}
+	if json.Unmarshal(data, &pkg) != nil {
+		return false
+	}
+	_, ok := pkg.Dependencies[name]
+	if !ok {
+		_, ok = pkg.DevDependencies[name]
+	}
+	return ok
+}
@@ -0,0 +1,267 @@
+package project
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/briandowns/spinner"
+	"github.com/dustin/go-humanize"
+	"github.com/fatih/color"
+
+	"project-starter/internal/walk"
+)
+
+// buildArtifactDirs are directories that builds and package managers
+// recreate, so the disk usage browser offers to delete them.
+var buildArtifactDirs = map[string]bool{
+	"target":        true,
+	".next":         true,
+	".nuxt":         true,
+	"dist":          true,
+	"build":         true,
+	"out":           true,
+	"node_modules":  true,
+	"__pycache__":   true,
+	".pytest_cache": true,
+	".turbo":        true,
+}
+
+// usageNode is a file or directory with the total size of everything in it.
+type usageNode struct {
+	name     string
+	rel      string
+	dir      bool
+	size     int64
+	files    int
+	parent   *usageNode
+	children []*usageNode
+}
+
+// measureDiskUsage walks projectPath into a tree of sizes. Files removed
+// or unreadable during the walk, as happens while a build is running, are
+// left out and counted in skipped.
+func measureDiskUsage(projectPath string) (root *usageNode, skipped int, err error) {
+	root = &usageNode{name: filepath.Base(projectPath), dir: true}
+	dirs := map[string]*usageNode{".": root}
+	err = walk.Walk(projectPath, walk.Options{}, func(_, rel string, d fs.DirEntry) error {
+		parent := dirs[path.Dir(rel)]
+		if d.IsDir() {
+			node := &usageNode{name: d.Name(), rel: rel, dir: true, parent: parent}
+			parent.children = append(parent.children, node)
+			dirs[rel] = node
+			return nil
+		}
+
+		info, err := d.Info()
+		if errors.Is(err, fs.ErrNotExist) || errors.Is(err, fs.ErrPermission) {
+			skipped++
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		node := &usageNode{name: d.Name(), rel: rel, parent: parent}
+		parent.children = append(parent.children, node)
+		for n := node; n != nil; n = n.parent {
+			n.size += info.Size()
+			n.files++
+		}
+		return nil
+	})
+	return root, skipped, err
+}
+
+// remove takes n out of the tree and its size out of its parents.
+func (n *usageNode) remove() {
+	for p := n.parent; p != nil; p = p.parent {
+		p.size -= n.size
+		p.files -= n.files
+	}
+	siblings := n.parent.children
+	for i, c := range siblings {
+		if c == n {
+			n.parent.children = append(siblings[:i], siblings[i+1:]...)
+			break
+		}
+	}
+}
+
+// ExploreDiskUsage asks for a project under dirPath and browses its disk
+// usage.
+func ExploreDiskUsage(dirPath string) error {
+	projects, err := GetDirectories(dirPath)
+	if err != nil {
+		return fmt.Errorf("error getting projects: %v", err)
+	}
+
+	var selectedProject string
+	err = survey.AskOne(&survey.Select{
+		Message:  "Select a project to explore:",
+		Options:  projects,
+		PageSize: 15,
+	}, &selectedProject)
+	if err != nil {
+		return fmt.Errorf("project selection failed: %v", err)
+	}
+	return BrowseDiskUsage(filepath.Join(dirPath, selectedProject))
+}
+
+// BrowseDiskUsage shows the directories and files of a project largest
+// first, like ncdu. The user can drill down, leave paths out of backups or
+// delete build output.
+func BrowseDiskUsage(projectPath string) error {
+	projectPath, err := filepath.Abs(projectPath)
+	if err != nil {
+		return err
+	}
+
+	s := spinner.New(spinner.CharSets[9], 100*time.Millisecond)
+	s.Suffix = " Measuring disk usage..."
+	s.Start()
+	root, skipped, err := measureDiskUsage(projectPath)
+	s.Stop()
+	if err != nil {
+		return fmt.Errorf("error measuring disk usage: %v", err)
+	}
+	if skipped > 0 {
+		color.Yellow("Skipped %d files that were removed or couldn't be read while measuring", skipped)
+	}
+
+	current := root
+	for {
+		ignored, err := loadBackupIgnore(projectPath)
+		if err != nil {
+			return err
+		}
+
+		options := []string{"[Go back]", "[Done]"}
+		if current != root {
+			if !ignored.Match(current.rel, true) {
+				options = append(options, "[Leave this directory out of backups]")
+			}
+			if buildArtifactDirs[current.name] {
+				options = append(options, "[Delete this directory]")
+			}
+		}
+
+		sort.SliceStable(current.children, func(i, j int) bool {
+			return current.children[i].size > current.children[j].size
+		})
+		nodes := make(map[string]*usageNode)
+		for _, child := range current.children {
+			label := usageLabel(child, current.size, ignored.Match(child.rel, child.dir))
+			nodes[label] = child
+			options = append(options, label)
+		}
+
+		location := "/" + current.rel
+		var selected string
+		err = survey.AskOne(&survey.Select{
+			Message: fmt.Sprintf("Project: %s\nCurrent directory: %s (%s in %d files)\nSelect directory, file or action:",
+				projectPath, location, humanize.Bytes(uint64(current.size)), current.files),
+			Options:  options,
+			PageSize: 15,
+		}, &selected)
+		if err != nil {
+			return fmt.Errorf("prompt failed: %v", err)
+		}
+
+		switch selected {
+		case "[Go back]":
+			if current.parent != nil {
+				current = current.parent
+			}
+		case "[Done]":
+			return nil
+		case "[Leave this directory out of backups]":
+			if err := ignoreInBackups(projectPath, current); err != nil {
+				color.Red("Error updating %s: %v", BackupIgnoreFile, err)
+			}
+		case "[Delete this directory]":
+			deleted, err := deleteUsageNode(projectPath, current)
+			if err != nil {
+				color.Red("Error deleting %s: %v", current.rel, err)
+			}
+			if deleted {
+				current = current.parent
+			}
+		default:
+			node := nodes[selected]
+			if node.dir {
+				current = node
+			} else if err := usageFileActions(projectPath, node); err != nil {
+				color.Red("Error: %v", err)
+			}
+		}
+	}
+}
+
+// usageLabel shows a node's size, its share of the directory it is in and
+// whether backups leave it out.
+func usageLabel(n *usageNode, total int64, ignored bool) string {
+	percent := 0.0
+	if total > 0 {
+		percent = float64(n.size) / float64(total) * 100
+	}
+	name := n.name
+	if n.dir {
+		name += "/"
+	}
+	label := fmt.Sprintf("%9s %5.1f%%  %s", humanize.Bytes(uint64(n.size)), percent, name)
+	if ignored {
+		label += " (not backed up)"
+	}
+	return label
+}
+
+func usageFileActions(projectPath string, node *usageNode) error {
+	var action string
+	err := survey.AskOne(&survey.Select{
+		Message: fmt.Sprintf("%s (%s):", node.rel, humanize.Bytes(uint64(node.size))),
+		Options: []string{"Leave out of backups", "Cancel"},
+	}, &action)
+	if err != nil {
+		return err
+	}
+	if action == "Leave out of backups" {
+		if err := ignoreInBackups(projectPath, node); err != nil {
+			return fmt.Errorf("error updating %s: %v", BackupIgnoreFile, err)
+		}
+	}
+	return nil
+}
+
+func ignoreInBackups(projectPath string, node *usageNode) error {
+	if err := addBackupIgnore(projectPath, node.rel, node.dir); err != nil {
+		return err
+	}
+	color.Green("Added %s to %s, saving %s per backup", node.rel, BackupIgnoreFile, humanize.Bytes(uint64(node.size)))
+	return nil
+}
+
+// deleteUsageNode deletes a build output directory after confirmation and
+// reports whether it did.
+func deleteUsageNode(projectPath string, node *usageNode) (bool, error) {
+	confirm := false
+	err := survey.AskOne(&survey.Confirm{
+		Message: fmt.Sprintf("Delete %s/ (%s in %d files)? The next build recreates it.", node.rel, humanize.Bytes(uint64(node.size)), node.files),
+		Default: false,
+	}, &confirm)
+	if err != nil || !confirm {
+		return false, err
+	}
+
+	if err := os.RemoveAll(filepath.Join(projectPath, filepath.FromSlash(node.rel))); err != nil {
+		return false, err
+	}
+	node.remove()
+	color.Green("Deleted %s/, freed %s", node.rel, humanize.Bytes(uint64(node.size)))
+	return true, nil
+}
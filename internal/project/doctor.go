@@ -0,0 +1,244 @@
+package project
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/fatih/color"
+
+	"project-starter/internal/config"
+	"project-starter/internal/doctor"
+)
+
+// DoctorOptions control RunDoctor.
+type DoctorOptions struct {
+	Format string
+	// Strict fails on warnings too.
+	Strict bool
+	// Fix offers to fix what rules found, showing a diff of the changes
+	// before writing them.
+	Fix bool
+	// Yes writes fixes without asking.
+	Yes bool
+}
+
+// doctorSymbols mark each status in the table.
+var doctorSymbols = map[doctor.Status]string{
+	doctor.Pass: color.GreenString("✓"),
+	doctor.Warn: color.YellowString("!"),
+	doctor.Fail: color.RedString("✗"),
+	doctor.Skip: color.New(color.Faint).Sprint("-"),
+}
+
+// RunDoctor checks the health of the project at projectPath and writes
+// the results to w as a table or json. It returns an error when a rule
+// fails, or with Strict when one warns, so CI can gate on it.
+func RunDoctor(w io.Writer, projectPath string, opts DoctorOptions) error {
+	if opts.Format == "" {
+		opts.Format = "table"
+	}
+	if opts.Format != "table" && opts.Format != "json" {
+		return fmt.Errorf("unknown format %q, expected table or json", opts.Format)
+	}
+	if opts.Fix && opts.Format != "table" {
+		return fmt.Errorf("--fix shows a diff to review and needs the table format")
+	}
+	p, err := loadDoctorProject(projectPath)
+	if err != nil {
+		return err
+	}
+	report := doctor.Run(p)
+
+	if opts.Format == "json" {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		displayDoctorReport(w, report)
+	}
+
+	if opts.Fix {
+		fixed, err := fixDoctorFindings(w, p, report, opts.Yes)
+		if err != nil {
+			return err
+		}
+		if fixed {
+			// Check again with the files as written
+			if p, err = loadDoctorProject(projectPath); err != nil {
+				return err
+			}
+			report = doctor.Run(p)
+			fmt.Fprintln(w)
+			displayDoctorReport(w, report)
+		}
+	}
+
+	s := report.Summary
+	switch {
+	case s.Failed > 0:
+		return fmt.Errorf("%d of %d checks failed", s.Failed, len(report.Results))
+	case opts.Strict && s.Warnings > 0:
+		return fmt.Errorf("%d checks have warnings", s.Warnings)
+	}
+	return nil
+}
+
+// fixDoctorFindings shows the changes that fix what the report found and
+// writes them once confirmed. It returns whether anything was written.
+func fixDoctorFindings(w io.Writer, p *doctor.Project, report doctor.Report, yes bool) (bool, error) {
+	changes, skipped, err := doctor.Fixes(p, report)
+	if err != nil {
+		return false, err
+	}
+	for _, s := range skipped {
+		color.Yellow("Not fixing %s: %s", s.Rule, s.Reason)
+	}
+	if len(changes) == 0 {
+		color.Yellow("\nNothing to fix automatically.")
+		return false, nil
+	}
+
+	fmt.Fprintln(w)
+	for _, c := range changes {
+		displayDiff(w, c.Diff())
+	}
+
+	if !yes {
+		var confirm bool
+		err := survey.AskOne(&survey.Confirm{
+			Message: "Write the changes above?",
+			Default: false,
+		}, &confirm)
+		if err != nil {
+			return false, fmt.Errorf("confirmation failed: %v", err)
+		}
+		if !confirm {
+			color.Yellow("No changes written.")
+			return false, nil
+		}
+	}
+
+	if err := doctor.Apply(p, changes); err != nil {
+		return false, err
+	}
+	for _, c := range changes {
+		verb := "Updated"
+		if c.Old == nil {
+			verb = "Created"
+		}
+		color.Green("%s %s (%s)", verb, c.Path, c.Rule)
+	}
+	return true, nil
+}
+
+// displayDiff colors a unified diff the way git does.
+func displayDiff(w io.Writer, diff string) {
+	bold, cyan := color.New(color.Bold), color.New(color.FgCyan)
+	red, green := color.New(color.FgRed), color.New(color.FgGreen)
+	for _, line := range strings.Split(strings.TrimSuffix(diff, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "--- "), strings.HasPrefix(line, "+++ "):
+			bold.Fprintln(w, line)
+		case strings.HasPrefix(line, "@@"):
+			cyan.Fprintln(w, line)
+		case strings.HasPrefix(line, "-"):
+			red.Fprintln(w, line)
+		case strings.HasPrefix(line, "+"):
+			green.Fprintln(w, line)
+		default:
+			fmt.Fprintln(w, line)
+		}
+	}
+}
+
+// loadDoctorProject prepares the project at projectPath for checks with the
+// global configuration and the project's own.
+func loadDoctorProject(projectPath string) (*doctor.Project, error) {
+	abs, err := filepath.Abs(projectPath)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	doctorConfig, err := doctor.LoadConfig(abs, cfg.Doctor)
+	if err != nil {
+		return nil, err
+	}
+	p, err := doctor.NewProject(abs, DetectType(abs), doctorConfig)
+	if err != nil {
+		return nil, fmt.Errorf("error reading project: %v", err)
+	}
+	return p, nil
+}
+
+func displayDoctorReport(w io.Writer, report doctor.Report) {
+	projectType := report.Type
+	if projectType == "" {
+		projectType = "unknown type"
+	}
+	color.Cyan("Checking %s (%s)\n", report.Name, projectType)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, r := range report.Results {
+		description := r.Description
+		if len(r.Messages) > 0 && (r.Status == doctor.Pass || r.Status == doctor.Skip) {
+			description += color.New(color.Faint).Sprintf(" (%s)", r.Messages[0])
+		}
+		fmt.Fprintf(tw, "%s %s\t%s\n", doctorSymbols[r.Status], r.Rule, description)
+		if r.Status == doctor.Warn || r.Status == doctor.Fail {
+			for _, m := range r.Messages {
+				fmt.Fprintf(tw, "\t  %s\n", m)
+			}
+		}
+	}
+	tw.Flush()
+
+	s := report.Summary
+	parts := []string{color.GreenString("%d passed", s.Passed)}
+	if s.Warnings > 0 {
+		parts = append(parts, color.YellowString("%d warnings", s.Warnings))
+	}
+	if s.Failed > 0 {
+		parts = append(parts, color.RedString("%d failed", s.Failed))
+	}
+	if s.Skipped > 0 {
+		parts = append(parts, fmt.Sprintf("%d skipped", s.Skipped))
+	}
+	fmt.Fprintln(w, "\n"+strings.Join(parts, ", "))
+}
+
+// PrintDoctorRules lists the rules checks run for the project at
+// projectPath, including those its configuration adds or disables.
+func PrintDoctorRules(w io.Writer, projectPath string) error {
+	p, err := loadDoctorProject(projectPath)
+	if err != nil {
+		return err
+	}
+	disabled := make(map[string]bool)
+	for _, id := range p.Config.DisabledRules {
+		disabled[id] = true
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "RULE\tSEVERITY\tDESCRIPTION")
+	for _, rule := range doctor.Rules(p.Config) {
+		severity := string(rule.Severity)
+		if s, ok := p.Config.Severity[rule.ID]; ok {
+			severity = s
+		}
+		if disabled[rule.ID] {
+			severity = "disabled"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", rule.ID, severity, rule.Description)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,139 @@
+package project
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/briandowns/spinner"
+	"github.com/dustin/go-humanize"
+	"github.com/fatih/color"
+
+	"project-starter/internal/dupes"
+)
+
+// DupesOptions control FindDuplicates.
+type DupesOptions struct {
+	// Project limits the search to one project of the workspace.
+	Project string
+	// MinSize leaves out smaller files.
+	MinSize int64
+	// Limit is how many duplicate sets the table shows, 0 for all.
+	Limit  int
+	Format string
+}
+
+// DupesReport is the machine readable result of a duplicate search.
+type DupesReport struct {
+	Root   string      `json:"root"`
+	Sets   []dupes.Set `json:"sets"`
+	Wasted int64       `json:"wasted"`
+	Files  int         `json:"files"`
+	// Skipped are the files that couldn't be read.
+	Skipped []string `json:"skipped,omitempty"`
+}
+
+// FindDuplicates finds files with the same contents in the projects under
+// root, or in one of them, and writes the duplicate sets with the space
+// they waste to w as a table or json.
+func FindDuplicates(w io.Writer, root string, opts DupesOptions) error {
+	if opts.Format == "" {
+		opts.Format = "table"
+	}
+	if opts.Format != "table" && opts.Format != "json" {
+		return fmt.Errorf("unknown format %q, expected table or json", opts.Format)
+	}
+
+	var roots []dupes.Root
+	if opts.Project != "" {
+		if opts.Project == "." || opts.Project == ".." || strings.ContainsAny(opts.Project, `/\`) {
+			return fmt.Errorf("invalid project name %q, expected the name of a directory in %s", opts.Project, root)
+		}
+		projectPath := filepath.Join(root, opts.Project)
+		if info, err := os.Stat(projectPath); err != nil {
+			return fmt.Errorf("project %s not found in %s", opts.Project, root)
+		} else if !info.IsDir() {
+			return fmt.Errorf("%s is not a directory", projectPath)
+		}
+		roots = append(roots, dupes.Root{Path: projectPath, Name: opts.Project})
+	} else {
+		projects, err := GetDirectories(root)
+		if err != nil {
+			return fmt.Errorf("error getting projects: %v", err)
+		}
+		for _, name := range projects {
+			if !strings.HasPrefix(name, ".") {
+				roots = append(roots, dupes.Root{Path: filepath.Join(root, name), Name: name})
+			}
+		}
+	}
+
+	s := spinner.New(spinner.CharSets[9], 100*time.Millisecond)
+	s.Suffix = fmt.Sprintf(" Looking for duplicates in %d projects...", len(roots))
+	if opts.Format == "table" {
+		s.Start()
+	}
+	var skipped []string
+	sets, err := dupes.Find(roots, dupes.Options{
+		MinSize: opts.MinSize,
+		Progress: func(hashed int) {
+			s.Lock()
+			s.Suffix = fmt.Sprintf(" Comparing files of the same size (%d hashed)...", hashed)
+			s.Unlock()
+		},
+		Skipped: func(name string, _ error) {
+			skipped = append(skipped, name)
+		},
+	})
+	s.Stop()
+	if err != nil {
+		return fmt.Errorf("error looking for duplicates: %v", err)
+	}
+	sort.Strings(skipped)
+
+	report := DupesReport{Root: root, Sets: sets, Skipped: skipped}
+	for _, set := range sets {
+		report.Wasted += set.Wasted()
+		report.Files += len(set.Files)
+	}
+	if opts.Format == "json" {
+		if report.Sets == nil {
+			report.Sets = []dupes.Set{}
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+
+	if len(skipped) > 0 {
+		color.Yellow("Skipped %d files that couldn't be read:", len(skipped))
+		for _, name := range skipped {
+			fmt.Fprintf(w, "  %s\n", name)
+		}
+	}
+	if len(sets) == 0 {
+		color.Green("No duplicate files found")
+		return nil
+	}
+	shown := sets
+	if opts.Limit > 0 && len(shown) > opts.Limit {
+		shown = shown[:opts.Limit]
+	}
+	for _, set := range shown {
+		color.Cyan("\n%d copies of %s, %s wasted (sha256 %s)", len(set.Files), humanize.Bytes(uint64(set.Size)),
+			humanize.Bytes(uint64(set.Wasted())), set.Hash[:12])
+		for _, name := range set.Files {
+			fmt.Fprintf(w, "  %s\n", name)
+		}
+	}
+	if len(shown) < len(sets) {
+		color.Yellow("\n%d more sets not shown, use --limit 0 to list all", len(sets)-len(shown))
+	}
+	color.Cyan("\n%d duplicate sets with %d files, %s wasted", len(sets), report.Files, humanize.Bytes(uint64(report.Wasted)))
+	return nil
+}
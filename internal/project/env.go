@@ -0,0 +1,174 @@
+package project
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/fatih/color"
+
+	"project-starter/internal/prereq"
+)
+
+// setupRequirements are the tools the setup options offered after
+// generation use. The files are written without them, so they are optional.
+var setupRequirements = []prereq.Requirement{
+	{Tool: "git", Optional: true},
+	{Tool: "docker", Optional: true},
+}
+
+// EnvReport is the json output of CheckEnvironment.
+type EnvReport struct {
+	Tools []EnvTool `json:"tools"`
+}
+
+type EnvTool struct {
+	Tool      string   `json:"tool"`
+	Minimum   string   `json:"minimum,omitempty"`
+	Optional  bool     `json:"optional"`
+	Installed bool     `json:"installed"`
+	Version   string   `json:"version,omitempty"`
+	OK        bool     `json:"ok"`
+	Problem   string   `json:"problem,omitempty"`
+	Hint      string   `json:"hint,omitempty"`
+	Templates []string `json:"templates"`
+}
+
+// CheckEnvironment reports the tools every template needs, or only those
+// of templateName when set, as a table or json. With a template it
+// returns an error when a required tool is missing or too old.
+func CheckEnvironment(w io.Writer, templateName, format string) error {
+	if format == "" {
+		format = "table"
+	}
+	if format != "table" && format != "json" {
+		return fmt.Errorf("unknown format %q, expected table or json", format)
+	}
+
+	selected := templates
+	if templateName != "" {
+		selected = nil
+		for _, t := range templates {
+			if strings.EqualFold(t.name, templateName) {
+				selected = append(selected, t)
+			}
+		}
+		if selected == nil {
+			return fmt.Errorf("unknown template %q, expected one of %s", templateName, strings.Join(getTemplateNames(), ", "))
+		}
+	}
+
+	lists := [][]prereq.Requirement{setupRequirements}
+	neededBy := make(map[string][]string)
+	for _, t := range selected {
+		lists = append(lists, t.requires)
+		for _, req := range t.requires {
+			neededBy[req.Tool] = append(neededBy[req.Tool], t.name)
+		}
+	}
+	statuses := prereq.Check(prereq.Merge(lists...))
+
+	if format == "json" {
+		report := EnvReport{Tools: []EnvTool{}}
+		for _, s := range statuses {
+			templates := neededBy[s.Tool]
+			if templates == nil {
+				templates = []string{}
+			}
+			report.Tools = append(report.Tools, EnvTool{
+				Tool: s.Tool, Minimum: s.Minimum, Optional: s.Optional,
+				Installed: s.Installed, Version: s.Version, OK: s.OK,
+				Problem: s.Problem, Hint: s.Hint, Templates: templates,
+			})
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		displayPrerequisites(w, statuses, neededBy)
+	}
+
+	if templateName != "" {
+		return unmetPrerequisites(selected[0].name, statuses)
+	}
+	return nil
+}
+
+// checkPrerequisites reports the tools the template needs before it is
+// generated, and returns an error when a required one is missing or too
+// old.
+func checkPrerequisites(w io.Writer, t projectTemplate) error {
+	statuses := prereq.Check(prereq.Merge(t.requires, setupRequirements))
+	color.Cyan("Checking prerequisites for %s...", t.name)
+	displayPrerequisites(w, statuses, nil)
+	fmt.Fprintln(w)
+	return unmetPrerequisites(t.name, statuses)
+}
+
+func unmetPrerequisites(templateName string, statuses []prereq.Status) error {
+	var unmet []string
+	for _, s := range statuses {
+		if !s.OK && !s.Optional {
+			unmet = append(unmet, fmt.Sprintf("%s (%s)", s.Tool, s.Problem))
+		}
+	}
+	if len(unmet) > 0 {
+		return fmt.Errorf("the %s template needs %s", templateName, strings.Join(unmet, ", "))
+	}
+	return nil
+}
+
+// displayPrerequisites prints a table of the tools with install hints for
+// those that aren't usable. neededBy, when set, adds a column of the
+// templates that use each tool.
+func displayPrerequisites(w io.Writer, statuses []prereq.Status, neededBy map[string][]string) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	header := "TOOL\tREQUIRED\tINSTALLED\tSTATUS"
+	if neededBy != nil {
+		header += "\tTEMPLATES"
+	}
+	fmt.Fprintln(tw, header)
+
+	for _, s := range statuses {
+		required := "any"
+		if s.Minimum != "" {
+			required = ">= " + s.Minimum
+		}
+		if s.Optional {
+			required += " (optional)"
+		}
+		installed := s.Version
+		if installed == "" {
+			installed = "-"
+		}
+		status := color.GreenString("ok")
+		switch {
+		case s.OK:
+		case s.Optional:
+			status = color.YellowString(s.Problem)
+		default:
+			status = color.RedString(s.Problem)
+		}
+
+		line := fmt.Sprintf("%s\t%s\t%s\t%s", s.Tool, required, installed, status)
+		if neededBy != nil {
+			templates := strings.Join(neededBy[s.Tool], ", ")
+			if templates == "" {
+				templates = "setup options"
+			}
+			line += "\t" + templates
+		}
+		fmt.Fprintln(tw, line)
+	}
+	tw.Flush()
+
+	for _, s := range statuses {
+		if !s.OK && s.Hint != "" {
+			fmt.Fprintf(w, "  %s: %s\n", s.Tool, s.Hint)
+		}
+	}
+}
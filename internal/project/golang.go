@@ -0,0 +1,918 @@
+package project
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/manifoldco/promptui"
+
+	"project-starter/internal/prereq"
+)
+
+// goVariant is a kind of Go project. Its files are templates of their path
+// and content, filled with goTemplateData.
+type goVariant struct {
+	name  string
+	files map[string]string
+	// requires are the tools generate needs besides go.
+	requires []prereq.Requirement
+	// generate runs after go mod init and before go mod tidy, for code
+	// generated from the variant's files.
+	generate []string
+}
+
+type goTemplateData struct {
+	Module string
+	// Name is the project directory, used for commands.
+	Name string
+	// Package is the library's package name, derived from Module.
+	Package string
+}
+
+// goVariants each compile and pass go vet and go test as generated.
+var goVariants = []goVariant{
+	{name: "Library", files: goLibraryFiles},
+	{name: "CLI", files: goCLIFiles},
+	{name: "HTTP service", files: goHTTPServiceFiles},
+	{name: "gRPC service", files: goGRPCServiceFiles, requires: []prereq.Requirement{{Tool: "buf"}}, generate: []string{"buf", "generate"}},
+	{name: "Worker", files: goWorkerFiles},
+}
+
+func initGoProject(path, projectName string) (*exec.Cmd, error) {
+	modulePrompt := promptui.Prompt{
+		Label: "Enter Go module name (e.g., github.com/username/project)",
+		Validate: func(input string) error {
+			if input == "" {
+				return fmt.Errorf("module name cannot be empty")
+			}
+			return nil
+		},
+	}
+
+	moduleName, err := modulePrompt.Run()
+	if err != nil {
+		fmt.Printf("Prompt failed: %v\n", err)
+		return nil, err
+	}
+
+	names := make([]string, len(goVariants))
+	for i, v := range goVariants {
+		names[i] = v.name
+	}
+	variantPrompt := promptui.Select{
+		Label: "Select the kind of Go project",
+		Items: names,
+	}
+	i, _, err := variantPrompt.Run()
+	if err != nil {
+		return nil, fmt.Errorf("project kind selection failed: %v", err)
+	}
+	variant := goVariants[i]
+	for _, status := range prereq.Check(variant.requires) {
+		if !status.OK {
+			return nil, fmt.Errorf("%s is %s, install it with: %s", status.Tool, status.Problem, status.Hint)
+		}
+	}
+
+	err = createGoProjectStructure(path, moduleName, variant)
+	if err != nil {
+		fmt.Printf("Error creating Go project structure: %v\n", err)
+		return nil, err
+	}
+
+	for _, args := range [][]string{{"go", "mod", "init", moduleName}, variant.generate} {
+		if len(args) == 0 {
+			continue
+		}
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Dir = path
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return nil, fmt.Errorf("%s failed: %v\n%s", strings.Join(args, " "), err, out)
+		}
+	}
+
+	// Add the dependencies the variant imports
+	return exec.Command("go", "mod", "tidy"), nil
+}
+
+func createGoProjectStructure(projectPath, moduleName string, variant goVariant) error {
+	data := goTemplateData{
+		Module:  moduleName,
+		Name:    filepath.Base(projectPath),
+		Package: goPackageName(moduleName),
+	}
+
+	// Sort for a stable order of errors
+	paths := make([]string, 0, len(variant.files))
+	for p := range variant.files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	for _, pathTemplate := range paths {
+		rel, err := renderGoTemplate(pathTemplate, pathTemplate, data)
+		if err != nil {
+			return err
+		}
+		content, err := renderGoTemplate(rel, variant.files[pathTemplate], data)
+		if err != nil {
+			return err
+		}
+
+		path := filepath.Join(projectPath, filepath.FromSlash(rel))
+		err = os.MkdirAll(filepath.Dir(path), os.ModePerm)
+		if err != nil {
+			return fmt.Errorf("error creating directory for %s: %v", rel, err)
+		}
+		err = os.WriteFile(path, []byte(content), 0644)
+		if err != nil {
+			return fmt.Errorf("error creating file %s: %v", rel, err)
+		}
+	}
+	return nil
+}
+
+func renderGoTemplate(name, text string, data goTemplateData) (string, error) {
+	t, err := template.New(name).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("error parsing template %s: %v", name, err)
+	}
+	var b strings.Builder
+	if err := t.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("error rendering template %s: %v", name, err)
+	}
+	return b.String(), nil
+}
+
+var (
+	majorVersionSuffix = regexp.MustCompile(`^v[0-9]+$`)
+	nonIdentifierChars = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// goPackageName derives a package name from the last element of the
+// module path, skipping a major version suffix like /v2.
+func goPackageName(module string) string {
+	base := path.Base(module)
+	if majorVersionSuffix.MatchString(base) {
+		base = path.Base(path.Dir(module))
+	}
+	name := nonIdentifierChars.ReplaceAllString(strings.ToLower(base), "")
+	if name == "" || name[0] >= '0' && name[0] <= '9' {
+		name = "lib" + name
+	}
+	return name
+}
+
+var goLibraryFiles = map[string]string{
+	"{{.Package}}.go": `// Package {{.Package}} is the start of a library. Replace Greet with the
+// package's API.
+package {{.Package}}
+
+import "fmt"
+
+// Greet returns a greeting for name, or for the world when name is empty.
+func Greet(name string) string {
+	if name == "" {
+		name = "world"
+	}
+	return fmt.Sprintf("Hello, %s!", name)
+}
+`,
+	"{{.Package}}_test.go": `package {{.Package}}
+
+import "testing"
+
+func TestGreet(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Gopher", "Hello, Gopher!"},
+		{"", "Hello, world!"},
+	}
+	for _, tt := range tests {
+		if got := Greet(tt.name); got != tt.want {
+			t.Errorf("Greet(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+`,
+	"example_test.go": `package {{.Package}}_test
+
+import (
+	"fmt"
+
+	{{.Package}} "{{.Module}}"
+)
+
+func ExampleGreet() {
+	fmt.Println({{.Package}}.Greet("Gopher"))
+	// Output: Hello, Gopher!
+}
+`,
+}
+
+var goCLIFiles = map[string]string{
+	"main.go": `// Command {{.Name}} is the start of a command line tool with subcommands.
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "{{.Name}}: %v\n", err)
+		os.Exit(1)
+	}
+}
+`,
+	"commands.go": `package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// version is set when building with -ldflags "-X main.version=1.0.0".
+var version = "dev"
+
+type command struct {
+	summary string
+	run     func(args []string, stdout io.Writer) error
+}
+
+// commands maps subcommand names to their handlers.
+var commands = map[string]command{
+	"greet":   {"print a greeting", runGreet},
+	"version": {"print the version", runVersion},
+}
+
+// run runs the subcommand named by the first argument.
+func run(args []string, stdout io.Writer) error {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		usage(stdout)
+		return nil
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		return fmt.Errorf("unknown command %q, run '{{.Name}} help' for usage", args[0])
+	}
+	err := cmd.run(args[1:], stdout)
+	if errors.Is(err, flag.ErrHelp) {
+		return nil
+	}
+	return err
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: {{.Name}} <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-10s %s\n", name, commands[name].summary)
+	}
+}
+
+func runGreet(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("greet", flag.ContinueOnError)
+	name := flags.String("name", "world", "who to greet")
+	shout := flags.Bool("shout", false, "print the greeting in capitals")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	greeting := fmt.Sprintf("Hello, %s!", *name)
+	if *shout {
+		greeting = strings.ToUpper(greeting)
+	}
+	fmt.Fprintln(stdout, greeting)
+	return nil
+}
+
+func runVersion(_ []string, stdout io.Writer) error {
+	fmt.Fprintln(stdout, version)
+	return nil
+}
+`,
+	"commands_test.go": `package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"greet"}, "Hello, world!\n"},
+		{[]string{"greet", "-name", "Gopher", "-shout"}, "HELLO, GOPHER!\n"},
+		{[]string{"version"}, "dev\n"},
+	}
+	for _, tt := range tests {
+		var stdout bytes.Buffer
+		if err := run(tt.args, &stdout); err != nil {
+			t.Fatalf("run(%q): %v", tt.args, err)
+		}
+		if got := stdout.String(); got != tt.want {
+			t.Errorf("run(%q) printed %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestRunUsage(t *testing.T) {
+	var stdout bytes.Buffer
+	if err := run(nil, &stdout); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(stdout.String(), "greet") {
+		t.Errorf("usage does not list the greet command:\n%s", stdout.String())
+	}
+}
+
+func TestRunUnknownCommand(t *testing.T) {
+	err := run([]string{"nope"}, &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "unknown command") {
+		t.Errorf("run(nope) = %v, want an unknown command error", err)
+	}
+}
+`,
+}
+
+var goHTTPServiceFiles = map[string]string{
+	"cmd/{{.Name}}/main.go": `// Command {{.Name}} serves the HTTP API until it is interrupted.
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"{{.Module}}/internal/server"
+)
+
+// shutdownTimeout is how long requests in flight get to finish on shutdown.
+const shutdownTimeout = 10 * time.Second
+
+func main() {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	if err := run(logger); err != nil {
+		logger.Error("server failed", "error", err)
+		os.Exit(1)
+	}
+}
+
+func run(logger *slog.Logger) error {
+	addr := os.Getenv("ADDR")
+	if addr == "" {
+		addr = ":8080"
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	srv := server.New(logger)
+	httpServer := &http.Server{Handler: srv, ReadHeaderTimeout: 5 * time.Second}
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- httpServer.Serve(listener)
+	}()
+	srv.SetReady(true)
+	logger.Info("listening", "addr", listener.Addr().String())
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	logger.Info("shutting down")
+	srv.SetReady(false)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-errs; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+`,
+	"internal/server/server.go": `// Package server implements the HTTP API.
+package server
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// Server routes requests and logs each one.
+type Server struct {
+	logger *slog.Logger
+	mux    *http.ServeMux
+	ready  atomic.Bool
+}
+
+// New returns a server that reports itself not ready until SetReady.
+func New(logger *slog.Logger) *Server {
+	s := &Server{logger: logger, mux: http.NewServeMux()}
+	s.mux.HandleFunc("GET /healthz", s.handleHealth)
+	s.mux.HandleFunc("GET /readyz", s.handleReady)
+	s.mux.HandleFunc("GET /v1/hello", s.handleHello)
+	return s
+}
+
+// SetReady sets whether the server takes traffic. It is set once listening
+// and cleared on shutdown, so load balancers stop sending requests.
+func (s *Server) SetReady(ready bool) {
+	s.ready.Store(ready)
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	s.mux.ServeHTTP(rec, r)
+	s.logger.Info("request", "method", r.Method, "path", r.URL.Path, "status", rec.status, "duration", time.Since(start))
+}
+
+// handleHealth reports that the process is alive.
+func (s *Server) handleHealth(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// handleReady reports whether the server takes traffic.
+func (s *Server) handleReady(w http.ResponseWriter, _ *http.Request) {
+	if !s.ready.Load() {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"status": "not ready"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ready"})
+}
+
+func (s *Server) handleHello(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		name = "world"
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"message": "Hello, " + name + "!"})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// statusRecorder remembers the status of a response for the log.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+`,
+	"internal/server/server_test.go": `package server
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newTestServer() *Server {
+	return New(slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func get(t *testing.T, h http.Handler, target string) (int, map[string]string) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	var body map[string]string
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("GET %s: decoding body: %v", target, err)
+	}
+	return rec.Code, body
+}
+
+func TestHealth(t *testing.T) {
+	code, body := get(t, newTestServer(), "/healthz")
+	if code != http.StatusOK || body["status"] != "ok" {
+		t.Errorf("GET /healthz = %d %v, want 200 ok", code, body)
+	}
+}
+
+func TestReady(t *testing.T) {
+	s := newTestServer()
+	if code, _ := get(t, s, "/readyz"); code != http.StatusServiceUnavailable {
+		t.Errorf("GET /readyz before SetReady = %d, want 503", code)
+	}
+	s.SetReady(true)
+	if code, _ := get(t, s, "/readyz"); code != http.StatusOK {
+		t.Errorf("GET /readyz after SetReady = %d, want 200", code)
+	}
+}
+
+func TestHello(t *testing.T) {
+	_, body := get(t, newTestServer(), "/v1/hello?name=Gopher")
+	if got := body["message"]; got != "Hello, Gopher!" {
+		t.Errorf("GET /v1/hello = %q, want %q", got, "Hello, Gopher!")
+	}
+}
+`,
+}
+
+var goGRPCServiceFiles = map[string]string{
+	"buf.yaml": `version: v2
+modules:
+  - path: proto
+lint:
+  use:
+    - STANDARD
+breaking:
+  use:
+    - FILE
+`,
+	"buf.gen.yaml": `# Regenerate the stubs in gen after changing the protos with: buf generate
+version: v2
+plugins:
+  - remote: buf.build/protocolbuffers/go
+    out: gen
+    opt: paths=source_relative
+  - remote: buf.build/grpc/go
+    out: gen
+    opt: paths=source_relative
+`,
+	"proto/greeter/v1/greeter.proto": `syntax = "proto3";
+
+package greeter.v1;
+
+option go_package = "{{.Module}}/gen/greeter/v1;greeterv1";
+
+// GreeterService greets callers.
+service GreeterService {
+  // SayHello returns a greeting for the name in the request.
+  rpc SayHello(SayHelloRequest) returns (SayHelloResponse);
+}
+
+message SayHelloRequest {
+  string name = 1;
+}
+
+message SayHelloResponse {
+  string message = 1;
+}
+`,
+	"cmd/{{.Name}}/main.go": `// Command {{.Name}} serves the gRPC API until it is interrupted.
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+
+	greeterv1 "{{.Module}}/gen/greeter/v1"
+	"{{.Module}}/internal/greeter"
+)
+
+func main() {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	if err := run(logger); err != nil {
+		logger.Error("server failed", "error", err)
+		os.Exit(1)
+	}
+}
+
+func run(logger *slog.Logger) error {
+	addr := os.Getenv("ADDR")
+	if addr == "" {
+		addr = ":8080"
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	srv := grpc.NewServer(grpc.ChainUnaryInterceptor(logRequests(logger)))
+	greeterv1.RegisterGreeterServiceServer(srv, &greeter.Server{})
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(srv, healthServer)
+	// Let tools like grpcurl list the services
+	reflection.Register(srv)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		logger.Info("shutting down")
+		// Report not serving so clients move on, then let calls in flight
+		// finish
+		healthServer.Shutdown()
+		srv.GracefulStop()
+	}()
+
+	logger.Info("listening", "addr", listener.Addr().String())
+	return srv.Serve(listener)
+}
+
+// logRequests logs each call with its status code and duration.
+func logRequests(logger *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		logger.Info("request", "method", info.FullMethod, "code", status.Code(err).String(), "duration", time.Since(start))
+		return resp, err
+	}
+}
+`,
+	"internal/greeter/greeter.go": `// Package greeter implements the GreeterService.
+package greeter
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	greeterv1 "{{.Module}}/gen/greeter/v1"
+)
+
+// Server implements greeterv1.GreeterServiceServer.
+type Server struct {
+	greeterv1.UnimplementedGreeterServiceServer
+}
+
+// SayHello greets the name in the request, which is required.
+func (s *Server) SayHello(_ context.Context, req *greeterv1.SayHelloRequest) (*greeterv1.SayHelloResponse, error) {
+	if req.GetName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "name is required")
+	}
+	return &greeterv1.SayHelloResponse{Message: "Hello, " + req.GetName() + "!"}, nil
+}
+`,
+	"internal/greeter/greeter_test.go": `package greeter
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	greeterv1 "{{.Module}}/gen/greeter/v1"
+)
+
+// newClient serves the greeter over an in-memory connection.
+func newClient(t *testing.T) greeterv1.GreeterServiceClient {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	greeterv1.RegisterGreeterServiceServer(srv, &Server{})
+	go func() {
+		_ = srv.Serve(listener)
+	}()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return greeterv1.NewGreeterServiceClient(conn)
+}
+
+func TestSayHello(t *testing.T) {
+	resp, err := newClient(t).SayHello(context.Background(), &greeterv1.SayHelloRequest{Name: "Gopher"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := resp.GetMessage(); got != "Hello, Gopher!" {
+		t.Errorf("SayHello = %q, want %q", got, "Hello, Gopher!")
+	}
+}
+
+func TestSayHelloRequiresName(t *testing.T) {
+	_, err := newClient(t).SayHello(context.Background(), &greeterv1.SayHelloRequest{})
+	if code := status.Code(err); code != codes.InvalidArgument {
+		t.Errorf("SayHello without a name = %v, want %v", code, codes.InvalidArgument)
+	}
+}
+`,
+}
+
+var goWorkerFiles = map[string]string{
+	"cmd/{{.Name}}/main.go": `// Command {{.Name}} runs a job on an interval until it is interrupted.
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"{{.Module}}/internal/worker"
+)
+
+func main() {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	interval, err := parseInterval(os.Getenv("INTERVAL"))
+	if err != nil {
+		logger.Error("invalid INTERVAL", "error", err)
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	w := &worker.Worker{
+		Interval: interval,
+		Logger:   logger,
+		Job: func(ctx context.Context) error {
+			// Replace with the work to do
+			logger.Info("working")
+			return nil
+		},
+	}
+	logger.Info("worker started", "interval", interval)
+	w.Run(ctx)
+	logger.Info("worker stopped")
+}
+
+// parseInterval parses a duration like 30s, defaulting to a minute.
+func parseInterval(s string) (time.Duration, error) {
+	if s == "" {
+		return time.Minute, nil
+	}
+	interval, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if interval <= 0 {
+		return 0, fmt.Errorf("interval must be positive, got %s", s)
+	}
+	return interval, nil
+}
+`,
+	"internal/worker/worker.go": `// Package worker runs a job on an interval until it is stopped.
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Job is one run of the work. It should return soon after ctx is done.
+type Job func(ctx context.Context) error
+
+// Worker runs Job every Interval.
+type Worker struct {
+	Interval time.Duration
+	Job      Job
+	Logger   *slog.Logger
+}
+
+// Run runs the job right away and then every interval until ctx is done.
+// A run in progress finishes first. Failed runs are logged and the job is
+// tried again on the next tick.
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+	for ctx.Err() == nil {
+		w.runOnce(ctx)
+		select {
+		case <-ctx.Done():
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *Worker) runOnce(ctx context.Context) {
+	start := time.Now()
+	if err := w.Job(ctx); err != nil {
+		w.Logger.Error("job failed", "error", err, "duration", time.Since(start))
+		return
+	}
+	w.Logger.Info("job done", "duration", time.Since(start))
+}
+`,
+	"internal/worker/worker_test.go": `package worker
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+// runUntil runs the worker until the job has run n times and returns how
+// many times it ran.
+func runUntil(t *testing.T, n int, job Job) int {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	runs := 0
+	w := &Worker{
+		Interval: time.Millisecond,
+		Logger:   slog.New(slog.NewTextHandler(io.Discard, nil)),
+		Job: func(ctx context.Context) error {
+			runs++
+			if runs == n {
+				cancel()
+			}
+			return job(ctx)
+		},
+	}
+
+	done := make(chan struct{})
+	go func() {
+		w.Run(ctx)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after its context was canceled")
+	}
+	return runs
+}
+
+func TestRunRepeatsUntilCanceled(t *testing.T) {
+	if runs := runUntil(t, 3, func(context.Context) error { return nil }); runs != 3 {
+		t.Errorf("job ran %d times, want 3", runs)
+	}
+}
+
+func TestRunContinuesAfterFailure(t *testing.T) {
+	job := func(context.Context) error { return errors.New("failed") }
+	if runs := runUntil(t, 2, job); runs != 2 {
+		t.Errorf("job ran %d times, want 2", runs)
+	}
+}
+`,
+}
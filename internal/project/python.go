@@ -0,0 +1,263 @@
+package project
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/manifoldco/promptui"
+
+	"project-starter/internal/prereq"
+	"project-starter/internal/setup"
+)
+
+// pythonManagerRequirements are the tools each manager needs besides
+// Python itself. Poetry 2 is the first to read the [project] table.
+var pythonManagerRequirements = map[string][]prereq.Requirement{
+	"uv":     {{Tool: "uv"}},
+	"poetry": {{Tool: "poetry", Minimum: "2.0"}},
+	"pip":    nil,
+}
+
+func initPythonProject(path, projectName string) (*exec.Cmd, error) {
+	variantPrompt := promptui.Select{
+		Label: "Select the kind of Python project",
+		Items: []string{"Library", "Service"},
+	}
+	_, variant, err := variantPrompt.Run()
+	if err != nil {
+		return nil, fmt.Errorf("project kind selection failed: %v", err)
+	}
+
+	managerPrompt := promptui.Select{
+		Label: "Select the package manager",
+		Items: setup.PythonManagers,
+	}
+	_, manager, err := managerPrompt.Run()
+	if err != nil {
+		return nil, fmt.Errorf("package manager selection failed: %v", err)
+	}
+	for _, status := range prereq.Check(pythonManagerRequirements[manager]) {
+		if !status.OK {
+			return nil, fmt.Errorf("%s is %s, install it with: %s", status.Tool, status.Problem, status.Hint)
+		}
+	}
+
+	distribution, pkg, err := pythonNames(projectName)
+	if err != nil {
+		return nil, err
+	}
+	p := setup.PythonProject{Package: pkg, Manager: manager, Service: variant == "Service"}
+	if err := createPythonProjectStructure(path, distribution, p); err != nil {
+		fmt.Printf("Error creating Python project structure: %v\n", err)
+		return nil, err
+	}
+
+	switch manager {
+	case "uv":
+		return exec.Command("uv", "sync"), nil
+	case "poetry":
+		cmd := exec.Command("poetry", "install")
+		// Keep the venv in the project where editors, the .gitignore and
+		// the Dockerfile expect it
+		cmd.Env = append(os.Environ(), "POETRY_VIRTUALENVS_IN_PROJECT=true")
+		return cmd, nil
+	default:
+		venv := exec.Command(prereq.Tools["python"].Command, "-m", "venv", ".venv")
+		venv.Dir = path
+		if out, err := venv.CombinedOutput(); err != nil {
+			return nil, fmt.Errorf("error creating virtual environment: %v\n%s", err, out)
+		}
+		// The install runs in the project directory, so a relative
+		// workspace root would be applied twice
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return nil, fmt.Errorf("error resolving project path: %v", err)
+		}
+		python := filepath.Join(abs, ".venv", "bin", "python")
+		if runtime.GOOS == "windows" {
+			python = filepath.Join(abs, ".venv", "Scripts", "python.exe")
+		}
+		return exec.Command(python, "-m", "pip", "install", "-e", ".[dev]"), nil
+	}
+}
+
+// nonNameChars are the runs of characters package names can't have.
+var nonNameChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// pythonNames derives the distribution name, like my-app, and the import
+// name, like my_app, from the project name.
+func pythonNames(projectName string) (string, string, error) {
+	distribution := strings.Trim(nonNameChars.ReplaceAllString(strings.ToLower(projectName), "-"), "-")
+	if distribution == "" || distribution[0] >= '0' && distribution[0] <= '9' {
+		return "", "", fmt.Errorf("project name %q must start with a letter to be a Python package", projectName)
+	}
+	return distribution, strings.ReplaceAll(distribution, "-", "_"), nil
+}
+
+func createPythonProjectStructure(projectPath, distribution string, p setup.PythonProject) error {
+	pkgDir := filepath.Join("src", p.Package)
+	files := map[string]string{
+		"pyproject.toml": getPyprojectContent(distribution, p),
+	}
+	if p.Service {
+		files[filepath.Join(pkgDir, "__init__.py")] = fmt.Sprintf(`"""%s service."""
+
+__version__ = "0.1.0"
+`, distribution)
+		files[filepath.Join(pkgDir, "app.py")] = fmt.Sprintf(`"""HTTP API of %s."""
+
+from fastapi import FastAPI
+
+app = FastAPI(title="%s")
+
+
+@app.get("/healthz")
+def healthz() -> dict[str, str]:
+    """Report that the service is up, for load balancers and orchestrators."""
+    return {"status": "ok"}
+`, distribution, distribution)
+		files[filepath.Join(pkgDir, "__main__.py")] = fmt.Sprintf(`"""Run the service with python -m %[1]s."""
+
+import os
+
+import uvicorn
+
+
+def main() -> None:
+    uvicorn.run(
+        "%[1]s.app:app",
+        host=os.environ.get("HOST", "0.0.0.0"),
+        port=int(os.environ.get("PORT", "8000")),
+    )
+
+
+if __name__ == "__main__":
+    main()
+`, p.Package)
+		files[filepath.Join("tests", "test_app.py")] = fmt.Sprintf(`from fastapi.testclient import TestClient
+
+from %s.app import app
+
+client = TestClient(app)
+
+
+def test_healthz() -> None:
+    response = client.get("/healthz")
+    assert response.status_code == 200
+    assert response.json() == {"status": "ok"}
+`, p.Package)
+	} else {
+		files[filepath.Join(pkgDir, "__init__.py")] = fmt.Sprintf(`"""%s library."""
+
+__version__ = "0.1.0"
+
+
+def greet(name: str) -> str:
+    """Return a greeting for name."""
+    return f"Hello, {name}!"
+`, distribution)
+		// Tell type checkers the package has type hints
+		files[filepath.Join(pkgDir, "py.typed")] = ""
+		files[filepath.Join("tests", "test_"+p.Package+".py")] = fmt.Sprintf(`from %[1]s import greet
+
+
+def test_greet() -> None:
+    assert greet("world") == "Hello, world!"
+`, p.Package)
+	}
+
+	for path, content := range files {
+		err := os.MkdirAll(filepath.Dir(filepath.Join(projectPath, path)), os.ModePerm)
+		if err != nil {
+			return fmt.Errorf("error creating directory for %s: %v", path, err)
+		}
+		err = os.WriteFile(filepath.Join(projectPath, path), []byte(content), 0644)
+		if err != nil {
+			return fmt.Errorf("error creating file %s: %v", path, err)
+		}
+	}
+	return nil
+}
+
+// getPyprojectContent declares the project in the [project] table every
+// manager reads, with development dependencies where each manager keeps
+// them, and the pytest and ruff configuration.
+func getPyprojectContent(distribution string, p setup.PythonProject) string {
+	var dependencies []string
+	devDependencies := []string{"pytest>=8", "ruff>=0.6"}
+	if p.Service {
+		dependencies = []string{"fastapi>=0.115", "uvicorn>=0.30"}
+		// The FastAPI test client
+		devDependencies = append(devDependencies, "httpx>=0.27")
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `[project]
+name = "%s"
+version = "0.1.0"
+description = ""
+requires-python = ">=3.10"
+dependencies = %s
+`, distribution, tomlList(dependencies))
+	if p.Service {
+		fmt.Fprintf(&b, "\n[project.scripts]\n%s = \"%s.__main__:main\"\n", distribution, p.Package)
+	}
+
+	switch p.Manager {
+	case "uv":
+		fmt.Fprintf(&b, "\n[dependency-groups]\ndev = %s\n", tomlList(devDependencies))
+	case "poetry":
+		fmt.Fprintf(&b, "\n[tool.poetry]\npackages = [{ include = \"%s\", from = \"src\" }]\n", p.Package)
+		b.WriteString("\n[tool.poetry.group.dev.dependencies]\n")
+		for _, dep := range devDependencies {
+			name, version, _ := strings.Cut(dep, ">=")
+			fmt.Fprintf(&b, "%s = \">=%s\"\n", name, version)
+		}
+	default:
+		fmt.Fprintf(&b, "\n[project.optional-dependencies]\ndev = %s\n", tomlList(devDependencies))
+	}
+
+	if p.Manager == "poetry" {
+		b.WriteString(`
+[build-system]
+requires = ["poetry-core>=2.0,<3.0"]
+build-backend = "poetry.core.masonry.api"
+`)
+	} else {
+		fmt.Fprintf(&b, `
+[build-system]
+requires = ["hatchling"]
+build-backend = "hatchling.build"
+
+[tool.hatch.build.targets.wheel]
+packages = ["src/%s"]
+`, p.Package)
+	}
+
+	b.WriteString(`
+[tool.pytest.ini_options]
+testpaths = ["tests"]
+addopts = "-ra"
+
+[tool.ruff]
+line-length = 100
+target-version = "py310"
+src = ["src"]
+
+[tool.ruff.lint]
+select = ["E", "F", "W", "I", "B", "UP"]
+`)
+	return b.String()
+}
+
+func tomlList(items []string) string {
+	if len(items) == 0 {
+		return "[]"
+	}
+	return `["` + strings.Join(items, `", "`) + `"]`
+}
@@ -0,0 +1,26 @@
+package project
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"project-starter/internal/deps"
+	"project-starter/internal/sbom"
+)
+
+// WriteSBOM writes a software bill of materials for the project at
+// projectPath to w, in one of sbom.Formats. toolVersion is the version of
+// project-starter recorded as the generating tool.
+func WriteSBOM(w io.Writer, projectPath, format, toolVersion string) error {
+	abs, err := filepath.Abs(projectPath)
+	if err != nil {
+		return err
+	}
+	dependencies, err := deps.Read(abs)
+	if err != nil {
+		return fmt.Errorf("error reading dependencies: %v", err)
+	}
+	project := sbom.Project{Name: filepath.Base(abs), Tool: "project-starter", ToolVersion: toolVersion}
+	return sbom.Write(w, format, project, dependencies)
+}
@@ -0,0 +1,41 @@
+package project
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/briandowns/spinner"
+	"github.com/fatih/color"
+
+	"project-starter/internal/config"
+	"project-starter/internal/secrets"
+)
+
+// ScanSecrets reports possible secrets under path and returns an error if
+// there are any, so it can gate CI jobs.
+func ScanSecrets(path string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	scanner, err := secrets.NewScanner(cfg.Secrets)
+	if err != nil {
+		return err
+	}
+
+	s := spinner.New(spinner.CharSets[9], 100*time.Millisecond)
+	s.Suffix = " Scanning for secrets..."
+	s.Start()
+	findings, err := scanner.ScanDir(path)
+	s.Stop()
+	if err != nil {
+		return fmt.Errorf("error scanning for secrets: %v", err)
+	}
+
+	if len(findings) == 0 {
+		color.Green("No secrets found in %s", path)
+		return nil
+	}
+	displayFindings(findings)
+	return fmt.Errorf("found %d possible secrets in %d files", len(findings), len(secrets.ByPath(findings)))
+}
@@ -1,24 +1,57 @@
 package project
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
 	"time"
 
 	"github.com/AlecAivazis/survey/v2"
-	"github.com/briandowns/spinner"
 	"github.com/dustin/go-humanize"
 	"github.com/fatih/color"
 	"github.com/schollz/progressbar/v3"
+
+	"project-starter/internal/debt"
+	"project-starter/internal/deps"
+	"project-starter/internal/gitstats"
+	"project-starter/internal/ignore"
+	"project-starter/internal/languages"
+	"project-starter/internal/walk"
 )
 
 type ProjectStats struct {
-	LastModified time.Time
-	TotalSize    int64
-	FileCount    int
+	LastModified time.Time `json:"last_modified"`
+	TotalSize    int64     `json:"total_size"`
+	FileCount    int       `json:"file_count"`
+	// Languages leaves out ignored files such as dependencies and build
+	// output, unlike the totals above.
+	Languages []languages.Stats `json:"languages"`
+	// Git is nil for projects that are not a git repository.
+	Git          *gitstats.Stats `json:"git"`
+	Dependencies deps.Summary    `json:"dependencies"`
+	// DependenciesError is why the dependencies couldn't be read, such as
+	// a malformed lockfile. Dependencies is empty then.
+	DependenciesError string `json:"dependencies_error,omitempty"`
+	// Debt counts TODO, FIXME, HACK and XXX markers in the same files as
+	// Languages.
+	Debt debt.Summary `json:"debt"`
+	// Unreadable are the source files left out of Languages and Debt
+	// because they couldn't be read, relative to the project.
+	Unreadable []string `json:"unreadable,omitempty"`
 }
 
+// gitWeeks is how many weeks of commit activity the statistics show.
+const gitWeeks = 12
+
 func ViewProjectStatistics(dirPath string) error {
 	projects, err := GetDirectories(dirPath)
 	if err != nil {
@@ -39,58 +72,145 @@ func ViewProjectStatistics(dirPath string) error {
 
 	projectPath := filepath.Join(dirPath, selectedProject)
 
-	// Create and start a new spinner
-	s := spinner.New(spinner.CharSets[9], 100*time.Millisecond)
-	s.Suffix = " Gathering project statistics..."
-	s.Start()
-
-	stats, err := getProjectStats(projectPath)
-	s.Stop()
-
+	stats, err := getProjectStats(projectPath, false)
 	if err != nil {
 		return fmt.Errorf("error getting project statistics: %v", err)
 	}
 
-	displayProjectStats(selectedProject, stats)
+	displayProjectStats(os.Stdout, selectedProject, stats)
+	abs, err := filepath.Abs(projectPath)
+	if err != nil {
+		return err
+	}
+	saveStatsSnapshot(abs, stats)
 	return nil
 }
 
-func displayProjectStats(projectName string, stats ProjectStats) {
-	color.Cyan("\nProject Statistics for %s:", projectName)
-	color.Yellow("Last Modified: %s", stats.LastModified.Format("2006-01-02 15:04:05"))
-	color.Yellow("Total Size: %s", humanize.Bytes(uint64(stats.TotalSize)))
-	color.Yellow("Number of Files: %d", stats.FileCount)
+func displayProjectStats(w io.Writer, projectName string, stats ProjectStats) {
+	cyan, yellow := color.New(color.FgCyan), color.New(color.FgYellow)
+	cyan.Fprintf(w, "\nProject Statistics for %s:\n", projectName)
+	yellow.Fprintf(w, "Last Modified: %s\n", stats.LastModified.Format("2006-01-02 15:04:05"))
+	yellow.Fprintf(w, "Total Size: %s\n", humanize.Bytes(uint64(stats.TotalSize)))
+	yellow.Fprintf(w, "Number of Files: %d\n", stats.FileCount)
+
+	if len(stats.Languages) > 0 {
+		fmt.Fprintln(w)
+		displayLanguages(w, stats.Languages)
+	}
+	if stats.Git != nil {
+		displayGitStats(w, stats.Git)
+	}
+	if stats.DependenciesError != "" {
+		cyan.Fprintln(w, "\nDependencies:")
+		yellow.Fprintf(w, "Couldn't read dependencies: %s\n", stats.DependenciesError)
+	} else if d := stats.Dependencies; d.Direct+d.Transitive > 0 {
+		cyan.Fprintln(w, "\nDependencies:")
+		yellow.Fprintf(w, "%d direct (%d for development), %d transitive\n", d.Direct, d.Dev, d.Transitive)
+		yellow.Fprintf(w, "Ecosystems: %s\n", formatCounts(d.Ecosystems))
+		yellow.Fprintf(w, "Licenses: %s\n", formatCounts(d.Licenses))
+	}
+	if stats.Debt.Total > 0 {
+		displayDebt(w, stats.Debt)
+	}
+	if len(stats.Unreadable) > 0 {
+		yellow.Fprintf(w, "\nSkipped %d source files that couldn't be read: %s\n", len(stats.Unreadable), strings.Join(stats.Unreadable, ", "))
+	}
 }
 
-func getProjectStats(projectPath string) (ProjectStats, error) {
-	var stats ProjectStats
-	var err error
+func displayGitStats(w io.Writer, git *gitstats.Stats) {
+	cyan, yellow := color.New(color.FgCyan), color.New(color.FgYellow)
+	cyan.Fprintln(w, "\nGit:")
+	branch := git.Branch
+	if git.Detached {
+		branch = "detached at " + branch
+	}
+	if git.Upstream != "" && (git.Ahead > 0 || git.Behind > 0) {
+		branch += fmt.Sprintf(" (%d ahead, %d behind %s)", git.Ahead, git.Behind, git.Upstream)
+	}
+	yellow.Fprintf(w, "Branch: %s\n", branch)
+	if git.Dirty {
+		yellow.Fprintf(w, "Working tree: dirty (%d uncommitted changes)\n", git.ChangedFiles)
+	} else {
+		yellow.Fprintln(w, "Working tree: clean")
+	}
 
-	stats.LastModified, err = getLastModifiedTime(projectPath)
-	if err != nil {
-		return stats, err
+	if git.Commits == 0 {
+		yellow.Fprintln(w, "No commits yet")
+		return
 	}
+	yellow.Fprintf(w, "Commits: %d (first %s, last %s)\n", git.Commits,
+		git.FirstCommit.Format("2006-01-02"), git.LastCommit.Format("2006-01-02"))
+	yellow.Fprintf(w, "Contributors: %d (%d active in the last %d weeks)\n", len(git.Contributors), git.ActiveContributors, len(git.WeeklyCommits))
 
-	// First, count the total number of files
-	totalFiles := 0
-	err = filepath.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
+	weekly := 0
+	for _, n := range git.WeeklyCommits {
+		weekly += n
+	}
+	yellow.Fprintf(w, "Commits per week: %s (%d in the last %d weeks)\n", gitstats.Sparkline(git.WeeklyCommits), weekly, len(git.WeeklyCommits))
+
+	if len(git.TopChurn) > 0 {
+		cyan.Fprintln(w, "\nMost changed files:")
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "FILE\tCOMMITS\tADDED\tDELETED")
+		for _, f := range git.TopChurn {
+			fmt.Fprintf(tw, "%s\t%d\t+%s\t-%s\n", f.Path, f.Commits, humanize.Comma(int64(f.Added)), humanize.Comma(int64(f.Deleted)))
 		}
-		if !info.IsDir() {
-			totalFiles++
+		tw.Flush()
+	}
+}
+
+func displayLanguages(w io.Writer, stats []languages.Stats) {
+	var total languages.Stats
+	for _, l := range stats {
+		total.Files += l.Files
+		total.Code += l.Code
+		total.Comments += l.Comments
+		total.Blanks += l.Blanks
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "LANGUAGE\tFILES\tCODE\tCOMMENTS\tBLANKS\tLINES\t%")
+	for _, l := range stats {
+		percent := 0.0
+		if total.Code > 0 {
+			percent = float64(l.Code) / float64(total.Code) * 100
 		}
-		return nil
-	})
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%s\t%s\t%.1f\n", l.Name, l.Files, humanize.Comma(int64(l.Code)),
+			humanize.Comma(int64(l.Comments)), humanize.Comma(int64(l.Blanks)), humanize.Comma(int64(l.Lines())), percent)
+	}
+	fmt.Fprintf(tw, "TOTAL\t%d\t%s\t%s\t%s\t%s\t\n", total.Files, humanize.Comma(int64(total.Code)),
+		humanize.Comma(int64(total.Comments)), humanize.Comma(int64(total.Blanks)), humanize.Comma(int64(total.Lines())))
+	tw.Flush()
+}
+
+// getProjectStats gathers the statistics of the project at projectPath in a
+// single walk. Source files are read for line counts by a pool of workers
+// while the walk continues. The progress bar is hidden when quiet is set.
+func getProjectStats(projectPath string, quiet bool) (ProjectStats, error) {
+	var stats ProjectStats
+
+	root, err := os.Stat(projectPath)
+	if err != nil {
+		return stats, err
+	}
+	stats.LastModified = root.ModTime()
+	ignored, err := ignore.Load(projectPath, ".gitignore")
 	if err != nil {
 		return stats, err
 	}
 
-	// Create a progress bar
-	bar := progressbar.NewOptions(totalFiles,
+	// The number of files is unknown until the walk finishes, so the bar
+	// counts files instead of showing a percentage
+	bar := progressbar.NewOptions(-1,
 		progressbar.OptionEnableColorCodes(true),
 		progressbar.OptionSetWidth(15),
-		progressbar.OptionSetDescription("[cyan][1/2][reset] Counting files..."),
+		progressbar.OptionSetDescription("[cyan]Gathering project statistics...[reset]"),
+		progressbar.OptionShowCount(),
+		progressbar.OptionSetItsString("files"),
+		progressbar.OptionShowIts(),
+		progressbar.OptionThrottle(100*time.Millisecond),
+		progressbar.OptionClearOnFinish(),
+		progressbar.OptionSetVisibility(!quiet),
 		progressbar.OptionSetTheme(progressbar.Theme{
 			Saucer:        "[green]=[reset]",
 			SaucerHead:    "[green]>[reset]",
@@ -98,37 +218,155 @@ func getProjectStats(projectPath string) (ProjectStats, error) {
 			BarStart:      "[",
 			BarEnd:        "]",
 		}))
+	defer bar.Finish()
+
+	counter := languages.NewCounter()
+	sources := make(chan sourceFile)
+	var (
+		workers sync.WaitGroup
+		countMu sync.Mutex
+		markers []debt.Marker
+	)
+	for i := 0; i < runtime.NumCPU(); i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for s := range sources {
+				found, err := scanSource(counter, s)
+				countMu.Lock()
+				if err != nil {
+					// One file the walk found but can't read, such as one
+					// removed since or with a line too long to scan, only
+					// leaves that file out
+					stats.Unreadable = append(stats.Unreadable, s.rel)
+				}
+				markers = append(markers, found...)
+				countMu.Unlock()
+			}
+		}()
+	}
 
-	// Now, gather the statistics with the progress bar
-	err = filepath.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
+	// ignoredDirs records which directories the language count skips, so
+	// files only need their own name checked
+	ignoredDirs := make(map[string]bool)
+	err = walk.Walk(projectPath, walk.Options{}, func(filePath, rel string, d fs.DirEntry) error {
+		info, err := d.Info()
 		if err != nil {
 			return err
 		}
-		if !info.IsDir() {
-			stats.FileCount++
-			stats.TotalSize += info.Size()
-			bar.Add(1)
+		if info.ModTime().After(stats.LastModified) {
+			stats.LastModified = info.ModTime()
+		}
+		if d.IsDir() {
+			ignoredDirs[rel] = ignoredDirs[path.Dir(rel)] || ignored.MatchDir(rel)
+			return nil
+		}
+
+		stats.FileCount++
+		stats.TotalSize += info.Size()
+		bar.Add(1)
+		if info.Mode().IsRegular() && !ignoredDirs[path.Dir(rel)] && !ignored.MatchFile(rel) {
+			sources <- sourceFile{filePath, rel, info.ModTime()}
 		}
 		return nil
 	})
+	close(sources)
+	workers.Wait()
+	if err != nil {
+		return stats, err
+	}
+	sort.Strings(stats.Unreadable)
+	stats.Languages = counter.Result()
+	blameMarkers(projectPath, markers)
+	stats.Debt = debt.Summarize(markers)
 
-	// Update progress bar description for size calculation
-	bar.Describe("[cyan][2/2][reset] Calculating total size...")
-	bar.Add(totalFiles - stats.FileCount) // Complete the bar
+	// Like a missing git history, broken manifests only leave their
+	// section out
+	dependencies, err := deps.Read(projectPath)
+	if err != nil {
+		stats.DependenciesError = err.Error()
+	}
+	stats.Dependencies = deps.Summarize(dependencies)
 
-	return stats, err
+	bar.Describe("[cyan]Reading git history...[reset]")
+	stats.Git, err = gitstats.Read(projectPath, gitWeeks)
+	if errors.Is(err, gitstats.ErrNotRepository) {
+		return stats, nil
+	}
+	if err != nil {
+		return stats, fmt.Errorf("error reading git history: %v", err)
+	}
+	return stats, nil
 }
 
-func getLastModifiedTime(dirPath string) (time.Time, error) {
-	var lastModTime time.Time
-	err := filepath.Walk(dirPath, func(path string, info os.FileInfo, err error) error {
+// sourceFile is a file whose lines and debt markers the statistics count.
+type sourceFile struct {
+	path, rel string
+	modified  time.Time
+}
+
+// scanSource adds the lines of a source file in a known language to
+// counter and returns its debt markers, reading the file once.
+func scanSource(counter *languages.Counter, s sourceFile) ([]debt.Marker, error) {
+	lang := languages.Detect(s.path)
+	if lang == nil {
+		return nil, nil
+	}
+	file, err := os.Open(s.path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var markers []debt.Marker
+	counts, err := languages.Scan(file, lang, func(line int, text string) {
+		if m, ok := debt.Match(text, s.rel, line, s.modified); ok {
+			markers = append(markers, m)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	counter.Add(lang.Name, counts)
+	return markers, nil
+}
+
+// diskUsage is what a walk over a project finds without reading files.
+type diskUsage struct {
+	lastModified time.Time
+	size         int64
+	files        int
+}
+
+// getDiskUsage walks dirPath for its latest modification time, the size of
+// its files and how many there are.
+func getDiskUsage(dirPath string) (diskUsage, error) {
+	var usage diskUsage
+	root, err := os.Stat(dirPath)
+	if err != nil {
+		return usage, err
+	}
+	usage.lastModified = root.ModTime()
+	err = walk.Walk(dirPath, walk.Options{}, func(_, _ string, d fs.DirEntry) error {
+		info, err := d.Info()
 		if err != nil {
 			return err
 		}
-		if info.ModTime().After(lastModTime) {
-			lastModTime = info.ModTime()
+		if info.ModTime().After(usage.lastModified) {
+			usage.lastModified = info.ModTime()
+		}
+		if !d.IsDir() {
+			usage.files++
+			usage.size += info.Size()
 		}
 		return nil
 	})
-	return lastModTime, err
+	return usage, err
+}
+
+// getLastModifiedTime returns the latest modification time of dirPath and
+// everything below it.
+func getLastModifiedTime(dirPath string) (time.Time, error) {
+	usage, err := getDiskUsage(dirPath)
+	return usage.lastModified, err
 }
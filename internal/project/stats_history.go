@@ -0,0 +1,279 @@
+package project
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/fatih/color"
+
+	"project-starter/internal/config"
+	"project-starter/internal/gitstats"
+)
+
+// StatsSnapshot is the part of a statistics run kept in a project's history.
+type StatsSnapshot struct {
+	Time      time.Time `json:"time"`
+	Path      string    `json:"path"`
+	TotalSize int64     `json:"total_size"`
+	FileCount int       `json:"file_count"`
+	Code      int       `json:"code"`
+	Comments  int       `json:"comments"`
+	Blanks    int       `json:"blanks"`
+	// Languages are lines of code by language.
+	Languages              map[string]int `json:"languages"`
+	DependenciesDirect     int            `json:"dependencies_direct"`
+	DependenciesTransitive int            `json:"dependencies_transitive"`
+	DependenciesDev        int            `json:"dependencies_dev"`
+	DebtMarkers            int            `json:"debt_markers"`
+	// Commits and Contributors are zero for projects outside git.
+	Commits      int `json:"commits"`
+	Contributors int `json:"contributors"`
+}
+
+func newStatsSnapshot(projectPath string, stats ProjectStats, now time.Time) StatsSnapshot {
+	s := StatsSnapshot{
+		Time:                   now.UTC().Truncate(time.Second),
+		Path:                   projectPath,
+		TotalSize:              stats.TotalSize,
+		FileCount:              stats.FileCount,
+		Languages:              make(map[string]int),
+		DependenciesDirect:     stats.Dependencies.Direct,
+		DependenciesTransitive: stats.Dependencies.Transitive,
+		DependenciesDev:        stats.Dependencies.Dev,
+		DebtMarkers:            stats.Debt.Total,
+	}
+	for _, l := range stats.Languages {
+		s.Code += l.Code
+		s.Comments += l.Comments
+		s.Blanks += l.Blanks
+		s.Languages[l.Name] = l.Code
+	}
+	if stats.Git != nil {
+		s.Commits = stats.Git.Commits
+		s.Contributors = len(stats.Git.Contributors)
+	}
+	return s
+}
+
+// statsHistoryPath returns the history file of the project at the absolute
+// projectPath. Files are named after the project and a hash of its path,
+// so projects with the same name don't share one.
+func statsHistoryPath(projectPath string) (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(projectPath))
+	name := fmt.Sprintf("%s-%s.jsonl", filepath.Base(projectPath), hex.EncodeToString(sum[:4]))
+	return filepath.Join(dir, "history", name), nil
+}
+
+// saveStatsSnapshot records the statistics in the project's history. A
+// history that can't be written, such as on a read-only CI runner, is
+// warned about on standard error without failing the run.
+func saveStatsSnapshot(projectPath string, stats ProjectStats) {
+	if err := recordStatsSnapshot(projectPath, stats); err != nil {
+		color.New(color.FgYellow).Fprintf(os.Stderr, "Warning: statistics history not saved: %v\n", err)
+	}
+}
+
+// recordStatsSnapshot appends the statistics of the project at the
+// absolute projectPath to its history, one JSON object per line.
+func recordStatsSnapshot(projectPath string, stats ProjectStats) error {
+	path, err := statsHistoryPath(projectPath)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+	line, err := json.Marshal(newStatsSnapshot(projectPath, stats, time.Now()))
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// LoadStatsHistory returns the recorded statistics of the project at
+// projectPath, oldest first. Lines that can't be parsed, such as one cut
+// short by a crash, are skipped.
+func LoadStatsHistory(projectPath string) ([]StatsSnapshot, error) {
+	abs, err := filepath.Abs(projectPath)
+	if err != nil {
+		return nil, err
+	}
+	path, err := statsHistoryPath(abs)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var history []StatsSnapshot
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var s StatsSnapshot
+		if err := json.Unmarshal(scanner.Bytes(), &s); err == nil {
+			history = append(history, s)
+		}
+	}
+	return history, scanner.Err()
+}
+
+// trendMetric is a row of the trend table.
+type trendMetric struct {
+	name   string
+	value  func(StatsSnapshot) int64
+	format func(int64) string
+}
+
+var trendMetrics = []trendMetric{
+	{"Size", func(s StatsSnapshot) int64 { return s.TotalSize }, func(v int64) string { return humanize.Bytes(uint64(v)) }},
+	{"Files", func(s StatsSnapshot) int64 { return int64(s.FileCount) }, humanize.Comma},
+	{"Lines of code", func(s StatsSnapshot) int64 { return int64(s.Code) }, humanize.Comma},
+	{"Comment lines", func(s StatsSnapshot) int64 { return int64(s.Comments) }, humanize.Comma},
+	{"Dependencies", func(s StatsSnapshot) int64 { return int64(s.DependenciesDirect + s.DependenciesTransitive) }, humanize.Comma},
+	{"Direct dependencies", func(s StatsSnapshot) int64 { return int64(s.DependenciesDirect) }, humanize.Comma},
+	{"Tech debt markers", func(s StatsSnapshot) int64 { return int64(s.DebtMarkers) }, humanize.Comma},
+	{"Commits", func(s StatsSnapshot) int64 { return int64(s.Commits) }, humanize.Comma},
+}
+
+// trendPoints is how many of the latest snapshots the sparklines show.
+const trendPoints = 40
+
+// PrintStatsTrend writes the recorded statistics of the project at
+// projectPath since the given time to w, as a table of how each metric
+// changed with sparklines, as json or as csv with one row per snapshot.
+func PrintStatsTrend(w io.Writer, projectPath, format string, since time.Time) error {
+	if format != "table" && format != "json" && format != "csv" {
+		return fmt.Errorf("unknown format %q, expected table, json or csv", format)
+	}
+	abs, err := filepath.Abs(projectPath)
+	if err != nil {
+		return err
+	}
+	all, err := LoadStatsHistory(abs)
+	if err != nil {
+		return fmt.Errorf("error reading statistics history: %v", err)
+	}
+	history := []StatsSnapshot{}
+	for _, s := range all {
+		if !s.Time.Before(since) {
+			history = append(history, s)
+		}
+	}
+
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(struct {
+			Name      string          `json:"name"`
+			Path      string          `json:"path"`
+			Snapshots []StatsSnapshot `json:"snapshots"`
+		}{filepath.Base(abs), abs, history})
+	case "csv":
+		return writeStatsHistoryCSV(w, history)
+	}
+
+	if len(history) == 0 {
+		color.Yellow("No statistics recorded for %s yet, every run of project-starter stats adds one", abs)
+		return nil
+	}
+	first, latest := history[0], history[len(history)-1]
+	color.Cyan("Statistics of %s: %d snapshots from %s to %s", filepath.Base(abs), len(history),
+		first.Time.Local().Format("2006-01-02"), latest.Time.Local().Format("2006-01-02"))
+
+	recent := history
+	if len(recent) > trendPoints {
+		recent = recent[len(recent)-trendPoints:]
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "\nMETRIC\tFIRST\tLATEST\tCHANGE\tTREND")
+	for _, m := range trendMetrics {
+		values := make([]int64, len(recent))
+		for i, s := range recent {
+			values[i] = m.value(s)
+		}
+		from, to := m.value(first), m.value(latest)
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", m.name, m.format(from), m.format(to), formatChange(from, to), trendSparkline(values))
+	}
+	return tw.Flush()
+}
+
+// formatChange describes the change between two values like "+12.5%".
+func formatChange(from, to int64) string {
+	switch {
+	case from == to:
+		return "="
+	case from == 0:
+		return "new"
+	}
+	return fmt.Sprintf("%+.1f%%", float64(to-from)/float64(from)*100)
+}
+
+// trendSparkline draws values scaled between their minimum and maximum,
+// so slow growth is visible, unlike activity charts that start at zero.
+func trendSparkline(values []int64) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return gitstats.SparklineRange(values, slices.Min(values), slices.Max(values))
+}
+
+// statsHistoryCSVHeader lists the CSV columns. Columns are only ever
+// appended.
+var statsHistoryCSVHeader = []string{
+	"time", "path", "total_size", "file_count", "code", "comments", "blanks",
+	"dependencies_direct", "dependencies_transitive", "dependencies_dev",
+	"debt_markers", "commits", "contributors",
+}
+
+func writeStatsHistoryCSV(w io.Writer, history []StatsSnapshot) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(statsHistoryCSVHeader); err != nil {
+		return err
+	}
+	for _, s := range history {
+		row := []string{
+			s.Time.UTC().Format(time.RFC3339), s.Path, strconv.FormatInt(s.TotalSize, 10), strconv.Itoa(s.FileCount),
+			strconv.Itoa(s.Code), strconv.Itoa(s.Comments), strconv.Itoa(s.Blanks),
+			strconv.Itoa(s.DependenciesDirect), strconv.Itoa(s.DependenciesTransitive), strconv.Itoa(s.DependenciesDev),
+			strconv.Itoa(s.DebtMarkers), strconv.Itoa(s.Commits), strconv.Itoa(s.Contributors),
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,136 @@
+package project
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// statsSchemaVersion is bumped when fields of StatsReport change meaning or
+// are removed. New fields may be added without a bump.
+const statsSchemaVersion = 1
+
+// StatsReport is the machine readable form of a project's statistics.
+type StatsReport struct {
+	SchemaVersion int       `json:"schema_version"`
+	Name          string    `json:"name"`
+	Path          string    `json:"path"`
+	Generated     time.Time `json:"generated"`
+	ProjectStats
+}
+
+// GetStatsReport gathers the statistics of the project at projectPath
+// without any progress output. With history set they are added to the
+// project's history as well.
+func GetStatsReport(projectPath string, history bool) (*StatsReport, error) {
+	abs, err := filepath.Abs(projectPath)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := getProjectStats(abs, true)
+	if err != nil {
+		return nil, fmt.Errorf("error getting project statistics: %v", err)
+	}
+	if history {
+		saveStatsSnapshot(abs, stats)
+	}
+	return &StatsReport{
+		SchemaVersion: statsSchemaVersion,
+		Name:          filepath.Base(abs),
+		Path:          abs,
+		Generated:     time.Now().UTC(),
+		ProjectStats:  stats,
+	}, nil
+}
+
+// PrintStats writes the statistics of the project at projectPath to w as
+// json, csv or the colored table the interactive menu shows, adding them to
+// the project's history when history is set.
+func PrintStats(w io.Writer, projectPath, format string, history bool) error {
+	if format != "table" && format != "json" && format != "csv" {
+		return fmt.Errorf("unknown format %q, expected table, json or csv", format)
+	}
+	report, err := GetStatsReport(projectPath, history)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case "table":
+		displayProjectStats(w, report.Name, report.ProjectStats)
+		return nil
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	default:
+		return writeStatsCSV(w, []*StatsReport{report})
+	}
+}
+
+// statsCSVHeader lists the CSV columns. Columns are only ever appended.
+var statsCSVHeader = []string{
+	"schema_version", "name", "path", "generated",
+	"last_modified", "total_size", "file_count",
+	"code", "comments", "blanks", "primary_language", "languages",
+	"git_branch", "git_dirty", "git_changed_files", "git_ahead", "git_behind",
+	"git_commits", "git_first_commit", "git_last_commit", "git_contributors", "git_active_contributors",
+	"dependencies_direct", "dependencies_transitive", "dependencies_dev",
+	"debt_markers",
+}
+
+// writeStatsCSV writes one row per report. Languages are summarized as
+// "Go:1200;Shell:40" with lines of code, most first.
+func writeStatsCSV(w io.Writer, reports []*StatsReport) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(statsCSVHeader); err != nil {
+		return err
+	}
+
+	formatTime := func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.UTC().Format(time.RFC3339)
+	}
+	for _, r := range reports {
+		var code, comments, blanks int
+		var languages []string
+		for _, l := range r.Languages {
+			code += l.Code
+			comments += l.Comments
+			blanks += l.Blanks
+			languages = append(languages, fmt.Sprintf("%s:%d", l.Name, l.Code))
+		}
+		primary := ""
+		if len(r.Languages) > 0 {
+			primary = r.Languages[0].Name
+		}
+
+		row := []string{
+			strconv.Itoa(r.SchemaVersion), r.Name, r.Path, formatTime(r.Generated),
+			formatTime(r.LastModified), strconv.FormatInt(r.TotalSize, 10), strconv.Itoa(r.FileCount),
+			strconv.Itoa(code), strconv.Itoa(comments), strconv.Itoa(blanks), primary, strings.Join(languages, ";"),
+		}
+		if g := r.Git; g != nil {
+			row = append(row, g.Branch, strconv.FormatBool(g.Dirty), strconv.Itoa(g.ChangedFiles), strconv.Itoa(g.Ahead), strconv.Itoa(g.Behind),
+				strconv.Itoa(g.Commits), formatTime(g.FirstCommit), formatTime(g.LastCommit), strconv.Itoa(len(g.Contributors)), strconv.Itoa(g.ActiveContributors))
+		} else {
+			row = append(row, "", "", "", "", "", "", "", "", "", "")
+		}
+		d := r.Dependencies
+		row = append(row, strconv.Itoa(d.Direct), strconv.Itoa(d.Transitive), strconv.Itoa(d.Dev))
+		row = append(row, strconv.Itoa(r.Debt.Total))
+
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
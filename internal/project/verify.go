@@ -0,0 +1,78 @@
+package project
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/briandowns/spinner"
+	"github.com/fatih/color"
+
+	"project-starter/internal/archive"
+)
+
+// VerifyBackup checks every entry of a backup archive against its manifest
+// and, when projectPath is set, compares the backup with the live project.
+func VerifyBackup(archivePath, projectPath string) error {
+	s := spinner.New(spinner.CharSets[9], 100*time.Millisecond)
+	s.Suffix = " Verifying backup..."
+	s.Start()
+
+	report, err := archive.Verify(archivePath)
+	if err != nil {
+		s.Stop()
+		return fmt.Errorf("error verifying backup: %v", err)
+	}
+
+	var liveProblems []archive.Problem
+	if projectPath != "" {
+		s.Suffix = " Comparing backup with project..."
+		ignored, err := loadBackupIgnore(projectPath)
+		if err != nil {
+			s.Stop()
+			return err
+		}
+		liveProblems, err = archive.CompareWithDirectory(report.Manifest, projectPath, ignored)
+		if err != nil {
+			s.Stop()
+			return fmt.Errorf("error comparing with project: %v", err)
+		}
+	}
+	s.Stop()
+
+	color.Cyan("\nBackup %s (created %s):", archivePath, report.Manifest.Created.Local().Format("2006-01-02 15:04:05"))
+	if len(report.Problems) == 0 {
+		color.Green("All %d entries are intact.", report.Checked)
+	} else {
+		color.Yellow("%d of %d entries are intact.", report.Checked, len(report.Manifest.Files))
+		displayProblems(report.Problems)
+	}
+
+	if projectPath != "" {
+		color.Cyan("\nCompared with %s:", projectPath)
+		if len(liveProblems) == 0 {
+			color.Green("Backup matches the project.")
+		} else {
+			displayProblems(liveProblems)
+		}
+	}
+
+	if len(report.Problems) > 0 {
+		return fmt.Errorf("backup is damaged or incomplete")
+	}
+	if len(liveProblems) > 0 {
+		return fmt.Errorf("backup differs from the project in %d files", len(liveProblems))
+	}
+	return nil
+}
+
+func displayProblems(problems []archive.Problem) {
+	for _, p := range problems {
+		line := fmt.Sprintf("  %-8s %s (%s)", p.Kind, p.Path, p.Detail)
+		switch p.Kind {
+		case archive.ProblemCorrupt, archive.ProblemMissing:
+			color.Red("%s", line)
+		default:
+			color.Yellow("%s", line)
+		}
+	}
+}
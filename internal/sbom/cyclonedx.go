@@ -0,0 +1,132 @@
+package sbom
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"project-starter/internal/deps"
+)
+
+// The CycloneDX 1.5 JSON format, limited to the fields written here.
+// https://cyclonedx.org/docs/1.5/json/
+
+type cdxDocument struct {
+	BOMFormat    string          `json:"bomFormat"`
+	SpecVersion  string          `json:"specVersion"`
+	SerialNumber string          `json:"serialNumber"`
+	Version      int             `json:"version"`
+	Metadata     cdxMetadata     `json:"metadata"`
+	Components   []cdxComponent  `json:"components"`
+	Dependencies []cdxDependency `json:"dependencies"`
+}
+
+type cdxMetadata struct {
+	Timestamp string       `json:"timestamp"`
+	Tools     cdxTools     `json:"tools"`
+	Component cdxComponent `json:"component"`
+}
+
+type cdxTools struct {
+	Components []cdxComponent `json:"components"`
+}
+
+type cdxComponent struct {
+	Type     string       `json:"type"`
+	BOMRef   string       `json:"bom-ref,omitempty"`
+	Group    string       `json:"group,omitempty"`
+	Name     string       `json:"name"`
+	Version  string       `json:"version,omitempty"`
+	Scope    string       `json:"scope,omitempty"`
+	Hashes   []cdxHash    `json:"hashes,omitempty"`
+	Licenses []cdxLicense `json:"licenses,omitempty"`
+	PURL     string       `json:"purl,omitempty"`
+}
+
+type cdxHash struct {
+	Alg     string `json:"alg"`
+	Content string `json:"content"`
+}
+
+// cdxLicense holds either a single license or an SPDX expression.
+type cdxLicense struct {
+	License    *cdxLicenseChoice `json:"license,omitempty"`
+	Expression string            `json:"expression,omitempty"`
+}
+
+type cdxLicenseChoice struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+}
+
+type cdxDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn"`
+}
+
+// newCycloneDX describes the project as the metadata component, which
+// depends on its direct dependencies. Lockfiles are not read for the edges
+// between packages, so transitive dependencies are listed as components
+// without edges.
+func newCycloneDX(project Project, dependencies []deps.Dependency, now time.Time, id string) *cdxDocument {
+	root := cdxComponent{Type: "application", BOMRef: "project:" + project.Name, Name: project.Name}
+	doc := &cdxDocument{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.5",
+		SerialNumber: "urn:uuid:" + id,
+		Version:      1,
+		Metadata: cdxMetadata{
+			Timestamp: now.Format(time.RFC3339),
+			Tools: cdxTools{Components: []cdxComponent{
+				{Type: "application", Name: project.Tool, Version: project.ToolVersion},
+			}},
+			Component: root,
+		},
+		Components: []cdxComponent{},
+	}
+
+	direct := []string{}
+	used := map[string]bool{root.BOMRef: true}
+	for _, d := range dependencies {
+		// Dependencies without a resolved version share their package URL
+		// when they are declared with different ranges, but bom-refs must
+		// be unique
+		ref := PURL(d)
+		for base, n := ref, 2; used[ref]; n++ {
+			ref = fmt.Sprintf("%s#%d", base, n)
+		}
+		used[ref] = true
+
+		c := cdxComponent{
+			Type:    "library",
+			BOMRef:  ref,
+			Name:    d.Name,
+			Version: resolvedVersion(d),
+			Scope:   "required",
+			PURL:    PURL(d),
+		}
+		// npm scopes are the group, as in the package URL
+		if d.Ecosystem == deps.EcosystemNPM && strings.HasPrefix(d.Name, "@") {
+			c.Group, c.Name, _ = strings.Cut(d.Name, "/")
+		}
+		if d.Dev {
+			c.Scope = "optional"
+		}
+		if hash, ok := hashOf(d); ok {
+			c.Hashes = []cdxHash{{Alg: hash.Algorithm, Content: hash.Hex}}
+		}
+		if license, ok := normalizeLicense(d.License); ok && isExpression(license) {
+			c.Licenses = []cdxLicense{{Expression: license}}
+		} else if ok {
+			c.Licenses = []cdxLicense{{License: &cdxLicenseChoice{ID: license}}}
+		} else if license != "" {
+			c.Licenses = []cdxLicense{{License: &cdxLicenseChoice{Name: license}}}
+		}
+		doc.Components = append(doc.Components, c)
+		if d.Direct {
+			direct = append(direct, c.BOMRef)
+		}
+	}
+	doc.Dependencies = []cdxDependency{{Ref: root.BOMRef, DependsOn: direct}}
+	return doc
+}
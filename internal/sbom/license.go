@@ -0,0 +1,56 @@
+package sbom
+
+import (
+	"strings"
+)
+
+// spdxLicenses are the SPDX license identifiers common among open source
+// packages. Licenses outside this list are reported by name only.
+var spdxLicenses = map[string]bool{
+	"0BSD": true, "AFL-2.1": true, "AGPL-3.0": true, "AGPL-3.0-only": true, "AGPL-3.0-or-later": true,
+	"Apache-1.1": true, "Apache-2.0": true, "Artistic-2.0": true, "BlueOak-1.0.0": true,
+	"BSD-2-Clause": true, "BSD-3-Clause": true, "BSD-3-Clause-Clear": true, "BSD-4-Clause": true, "BSL-1.0": true,
+	"CC-BY-3.0": true, "CC-BY-4.0": true, "CC-BY-SA-4.0": true, "CC0-1.0": true, "CDDL-1.0": true, "CDDL-1.1": true,
+	"EPL-1.0": true, "EPL-2.0": true, "GPL-2.0": true, "GPL-2.0-only": true, "GPL-2.0-or-later": true,
+	"GPL-3.0": true, "GPL-3.0-only": true, "GPL-3.0-or-later": true, "ISC": true,
+	"LGPL-2.0": true, "LGPL-2.0-only": true, "LGPL-2.0-or-later": true, "LGPL-2.1": true, "LGPL-2.1-only": true,
+	"LGPL-2.1-or-later": true, "LGPL-3.0": true, "LGPL-3.0-only": true, "LGPL-3.0-or-later": true,
+	"MIT": true, "MIT-0": true, "MPL-1.1": true, "MPL-2.0": true, "MS-PL": true, "NCSA": true, "OFL-1.1": true,
+	"OpenSSL": true, "PostgreSQL": true, "Python-2.0": true, "Ruby": true, "Unicode-3.0": true,
+	"Unicode-DFS-2016": true, "Unlicense": true, "UPL-1.0": true, "W3C": true, "WTFPL": true, "X11": true,
+	"Zlib": true,
+}
+
+// normalizeLicense turns the license a package declares into an SPDX
+// expression, rewriting the old Cargo form "MIT/Apache-2.0". It reports
+// false for licenses that are not valid expressions of known identifiers.
+func normalizeLicense(license string) (string, bool) {
+	license = strings.TrimSpace(license)
+	if license == "" {
+		return "", false
+	}
+	if strings.Contains(license, "/") && !strings.Contains(license, " ") {
+		license = strings.ReplaceAll(license, "/", " OR ")
+	}
+
+	afterWith := false
+	for _, token := range strings.Fields(strings.NewReplacer("(", " ", ")", " ").Replace(license)) {
+		switch {
+		case token == "AND" || token == "OR":
+		case token == "WITH":
+			afterWith = true
+			continue
+		case afterWith:
+			// Exceptions such as LLVM-exception aren't checked
+		case !spdxLicenses[strings.TrimSuffix(token, "+")]:
+			return license, false
+		}
+		afterWith = false
+	}
+	return license, true
+}
+
+// isExpression reports whether license combines several identifiers.
+func isExpression(license string) bool {
+	return strings.ContainsAny(license, " ()")
+}
@@ -0,0 +1,103 @@
+package sbom
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"project-starter/internal/deps"
+)
+
+// purlTypes map dependency ecosystems to package URL types.
+var purlTypes = map[string]string{
+	deps.EcosystemGo:    "golang",
+	deps.EcosystemNPM:   "npm",
+	deps.EcosystemCargo: "cargo",
+}
+
+// PURL returns the package URL of a dependency, such as
+// pkg:npm/%40babel/core@7.24.0. Each path segment is percent-encoded, so the
+// @ of npm scopes becomes %40. Dependencies without a resolved version have
+// none in the URL.
+func PURL(d deps.Dependency) string {
+	segments := strings.Split(d.Name, "/")
+	for i, s := range segments {
+		segments[i] = escapePURL(s)
+	}
+	purl := "pkg:" + purlTypes[d.Ecosystem] + "/" + strings.Join(segments, "/")
+	if version := resolvedVersion(d); version != "" {
+		purl += "@" + escapePURL(version)
+	}
+	return purl
+}
+
+// resolvedVersion returns the version a lockfile resolved the dependency
+// to, or "" when only the manifest declares it, where it may be a range.
+func resolvedVersion(d deps.Dependency) string {
+	if !d.Locked {
+		return ""
+	}
+	return d.Version
+}
+
+// escapePURL percent-encodes every character RFC 3986 doesn't leave
+// unreserved, including the + of Go's +incompatible versions.
+func escapePURL(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.IndexByte("-._~", c) >= 0 {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// Hash is a checksum decoded from a lockfile.
+type Hash struct {
+	// Algorithm is the name CycloneDX uses, such as SHA-256.
+	Algorithm string
+	Hex       string
+}
+
+// sriAlgorithms are the algorithms of npm integrity strings.
+var sriAlgorithms = map[string]string{
+	"sha1":   "SHA-1",
+	"sha256": "SHA-256",
+	"sha384": "SHA-384",
+	"sha512": "SHA-512",
+}
+
+// hashOf decodes the checksum a lockfile pins a dependency to. Go's h1:
+// hashes are left out: they hash the module's file list rather than any
+// file a consumer could download and check.
+func hashOf(d deps.Dependency) (Hash, bool) {
+	switch {
+	case d.Checksum == "":
+		return Hash{}, false
+	case d.Ecosystem == deps.EcosystemNPM:
+		// An integrity string may list several hashes; the first is used
+		algorithm, sum, ok := strings.Cut(strings.Fields(d.Checksum)[0], "-")
+		if !ok || sriAlgorithms[algorithm] == "" {
+			return Hash{}, false
+		}
+		return decodeBase64(sriAlgorithms[algorithm], sum)
+	case d.Ecosystem == deps.EcosystemCargo:
+		if _, err := hex.DecodeString(d.Checksum); err != nil || len(d.Checksum) != 64 {
+			return Hash{}, false
+		}
+		return Hash{Algorithm: "SHA-256", Hex: strings.ToLower(d.Checksum)}, true
+	}
+	return Hash{}, false
+}
+
+func decodeBase64(algorithm, sum string) (Hash, bool) {
+	data, err := base64.StdEncoding.DecodeString(sum)
+	if err != nil {
+		return Hash{}, false
+	}
+	return Hash{Algorithm: algorithm, Hex: hex.EncodeToString(data)}, true
+}
@@ -0,0 +1,64 @@
+package sbom
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"project-starter/internal/deps"
+)
+
+// Formats of the documents Write produces.
+const (
+	FormatCycloneDX = "cyclonedx-json"
+	FormatSPDX      = "spdx-json"
+)
+
+var Formats = []string{FormatCycloneDX, FormatSPDX}
+
+// Project describes the software the SBOM is for.
+type Project struct {
+	Name string
+	// Tool and ToolVersion name the program generating the document.
+	Tool        string
+	ToolVersion string
+}
+
+// Write writes an SBOM of the project's dependencies to w in the given
+// format. Everything in it comes from the dependency inventory, so it is
+// produced offline.
+func Write(w io.Writer, format string, project Project, dependencies []deps.Dependency) error {
+	if format != FormatCycloneDX && format != FormatSPDX {
+		return fmt.Errorf("unknown format %q, expected %s or %s", format, FormatCycloneDX, FormatSPDX)
+	}
+	id, err := newUUID()
+	if err != nil {
+		return err
+	}
+
+	var doc interface{}
+	now := time.Now().UTC().Truncate(time.Second)
+	if format == FormatCycloneDX {
+		doc = newCycloneDX(project, dependencies, now, id)
+	} else {
+		doc = newSPDX(project, dependencies, now, id)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(doc)
+}
+
+// newUUID returns a random version 4 UUID, which both formats use to make
+// every document unique.
+func newUUID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
@@ -0,0 +1,135 @@
+package sbom
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"project-starter/internal/deps"
+)
+
+// The SPDX 2.3 JSON format, limited to the fields written here.
+// https://spdx.github.io/spdx-spec/v2.3/
+
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	Name                  string            `json:"name"`
+	SPDXID                string            `json:"SPDXID"`
+	VersionInfo           string            `json:"versionInfo,omitempty"`
+	DownloadLocation      string            `json:"downloadLocation"`
+	FilesAnalyzed         bool              `json:"filesAnalyzed"`
+	Checksums             []spdxChecksum    `json:"checksums,omitempty"`
+	LicenseConcluded      string            `json:"licenseConcluded"`
+	LicenseDeclared       string            `json:"licenseDeclared"`
+	CopyrightText         string            `json:"copyrightText"`
+	ExternalRefs          []spdxExternalRef `json:"externalRefs,omitempty"`
+	PrimaryPackagePurpose string            `json:"primaryPackagePurpose,omitempty"`
+}
+
+type spdxChecksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+type spdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+// noAssertion is SPDX for a value that wasn't determined.
+const noAssertion = "NOASSERTION"
+
+// spdxIDInvalid matches the characters SPDX identifiers can't contain.
+var spdxIDInvalid = regexp.MustCompile(`[^a-zA-Z0-9.-]+`)
+
+// newSPDX describes the project as the package the document describes,
+// which depends on its direct dependencies. As in the CycloneDX document,
+// transitive dependencies have no relationships.
+func newSPDX(project Project, dependencies []deps.Dependency, now time.Time, id string) *spdxDocument {
+	rootID := "SPDXRef-Project-" + spdxIDInvalid.ReplaceAllString(project.Name, "-")
+	doc := &spdxDocument{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              project.Name,
+		DocumentNamespace: fmt.Sprintf("https://spdx.org/spdxdocs/%s-%s", spdxIDInvalid.ReplaceAllString(project.Name, "-"), id),
+		CreationInfo: spdxCreationInfo{
+			Created:  now.Format(time.RFC3339),
+			Creators: []string{fmt.Sprintf("Tool: %s-%s", project.Tool, project.ToolVersion)},
+		},
+		Packages: []spdxPackage{{
+			Name:                  project.Name,
+			SPDXID:                rootID,
+			DownloadLocation:      noAssertion,
+			LicenseConcluded:      noAssertion,
+			LicenseDeclared:       noAssertion,
+			CopyrightText:         noAssertion,
+			PrimaryPackagePurpose: "APPLICATION",
+		}},
+		Relationships: []spdxRelationship{
+			{SPDXElementID: "SPDXRef-DOCUMENT", RelationshipType: "DESCRIBES", RelatedSPDXElement: rootID},
+		},
+	}
+
+	used := map[string]bool{rootID: true}
+	for _, d := range dependencies {
+		pkgID := "SPDXRef-Package-" + spdxIDInvalid.ReplaceAllString(d.Ecosystem+"-"+d.Name+"-"+d.Version, "-")
+		for base, n := pkgID, 2; used[pkgID]; n++ {
+			pkgID = fmt.Sprintf("%s-%d", base, n)
+		}
+		used[pkgID] = true
+
+		pkg := spdxPackage{
+			Name:             d.Name,
+			SPDXID:           pkgID,
+			VersionInfo:      resolvedVersion(d),
+			DownloadLocation: noAssertion,
+			LicenseConcluded: noAssertion,
+			LicenseDeclared:  noAssertion,
+			CopyrightText:    noAssertion,
+			ExternalRefs: []spdxExternalRef{
+				{ReferenceCategory: "PACKAGE-MANAGER", ReferenceType: "purl", ReferenceLocator: PURL(d)},
+			},
+			PrimaryPackagePurpose: "LIBRARY",
+		}
+		if hash, ok := hashOf(d); ok {
+			// SPDX spells the algorithms without the dash
+			pkg.Checksums = []spdxChecksum{{Algorithm: strings.ReplaceAll(hash.Algorithm, "-", ""), ChecksumValue: hash.Hex}}
+		}
+		if license, ok := normalizeLicense(d.License); ok {
+			pkg.LicenseDeclared = license
+		}
+		doc.Packages = append(doc.Packages, pkg)
+
+		switch {
+		case d.Direct && d.Dev:
+			doc.Relationships = append(doc.Relationships, spdxRelationship{SPDXElementID: pkgID, RelationshipType: "DEV_DEPENDENCY_OF", RelatedSPDXElement: rootID})
+		case d.Direct:
+			doc.Relationships = append(doc.Relationships, spdxRelationship{SPDXElementID: rootID, RelationshipType: "DEPENDS_ON", RelatedSPDXElement: pkgID})
+		}
+	}
+	return doc
+}
@@ -0,0 +1,29 @@
+package secrets
+
+import "fmt"
+
+// Action is what to do with files that contain secrets.
+type Action string
+
+const (
+	// ActionExclude leaves the files out.
+	ActionExclude Action = "exclude"
+	// ActionRedact replaces the secrets in the files. Files flagged by
+	// name only are left out, since there is no specific value to replace.
+	ActionRedact Action = "redact"
+	// ActionAbort stops the operation.
+	ActionAbort Action = "abort"
+	// ActionIgnore keeps the files as they are.
+	ActionIgnore Action = "ignore"
+)
+
+var Actions = []Action{ActionExclude, ActionRedact, ActionAbort, ActionIgnore}
+
+func ParseAction(s string) (Action, error) {
+	for _, a := range Actions {
+		if string(a) == s {
+			return a, nil
+		}
+	}
+	return "", fmt.Errorf("unknown secrets action %q, expected exclude, redact, abort or ignore", s)
+}
@@ -0,0 +1,156 @@
+package secrets
+
+import (
+	"math"
+	"regexp"
+)
+
+// Rule detects a kind of secret in file content.
+type Rule struct {
+	ID          string
+	Description string
+	// Pattern matches the secret. If it has a capture group, the first group
+	// is the secret and the rest of the match is context.
+	Pattern *regexp.Regexp
+	// Files restricts the rule to files whose name matches one of these
+	// globs. Empty means every file.
+	Files []string
+	// MinEntropy drops matches whose secret has a lower Shannon entropy in
+	// bits per character, so placeholders like "password123" are not
+	// reported by the broad rules.
+	MinEntropy float64
+}
+
+// FileRule flags files whose name alone suggests they hold credentials.
+type FileRule struct {
+	ID          string
+	Description string
+	// Names are globs matched against the file name, or against the whole
+	// relative path when they contain a slash.
+	Names []string
+	// Except are globs for look-alikes that are safe, such as .env.example.
+	Except []string
+}
+
+var fileRules = []FileRule{
+	{
+		ID:          "env-file",
+		Description: "environment file",
+		Names:       []string{".env", ".env.*", "*.env"},
+		Except:      []string{"*.example", "*.sample", "*.template", "*.dist", ".env.*.example"},
+	},
+	{
+		ID:          "key-file",
+		Description: "private key or certificate file",
+		Names:       []string{"*.pem", "*.key", "*.p12", "*.pfx", "*.jks", "*.keystore", "*.ppk", "id_rsa", "id_dsa", "id_ecdsa", "id_ed25519"},
+	},
+	{
+		ID:          "credentials-file",
+		Description: "credentials file",
+		Names:       []string{".aws/credentials", ".netrc", ".pgpass", ".git-credentials", "*.kdbx", "*.tfstate", "credentials.json", "service-account*.json"},
+	},
+}
+
+var rules = []Rule{
+	{
+		ID:          "private-key",
+		Description: "private key",
+		Pattern:     regexp.MustCompile(`-----BEGIN[ A-Z0-9]*PRIVATE KEY(?: BLOCK)?-----[\s\S]*?-----END[ A-Z0-9]*PRIVATE KEY(?: BLOCK)?-----`),
+	},
+	{
+		ID:          "aws-access-key-id",
+		Description: "AWS access key ID",
+		Pattern:     regexp.MustCompile(`\b((?:AKIA|ASIA|ABIA|ACCA)[0-9A-Z]{16})\b`),
+	},
+	{
+		ID:          "aws-secret-access-key",
+		Description: "AWS secret access key",
+		Pattern:     regexp.MustCompile(`(?i)aws_?secret_?access_?key["']?\s*[:=]\s*["']?([A-Za-z0-9/+=]{40})`),
+	},
+	{
+		ID:          "github-token",
+		Description: "GitHub token",
+		Pattern:     regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{36,255}|github_pat_[A-Za-z0-9_]{22,255})\b`),
+	},
+	{
+		ID:          "gitlab-token",
+		Description: "GitLab token",
+		Pattern:     regexp.MustCompile(`\b(glpat-[A-Za-z0-9_\-]{20,})`),
+	},
+	{
+		ID:          "slack-token",
+		Description: "Slack token",
+		Pattern:     regexp.MustCompile(`\b(xox[baprs]-[A-Za-z0-9\-]{10,})`),
+	},
+	{
+		ID:          "slack-webhook",
+		Description: "Slack webhook URL",
+		Pattern:     regexp.MustCompile(`https://hooks\.slack\.com/services/[A-Za-z0-9_/]+`),
+	},
+	{
+		ID:          "stripe-key",
+		Description: "Stripe live key",
+		Pattern:     regexp.MustCompile(`\b((?:sk|rk)_live_[A-Za-z0-9]{20,})\b`),
+	},
+	{
+		ID:          "google-api-key",
+		Description: "Google API key",
+		Pattern:     regexp.MustCompile(`\b(AIza[0-9A-Za-z_\-]{35})`),
+	},
+	{
+		ID:          "npm-token",
+		Description: "npm token",
+		Pattern:     regexp.MustCompile(`\b(npm_[A-Za-z0-9]{36})\b`),
+	},
+	{
+		ID:          "openai-api-key",
+		Description: "OpenAI style API key",
+		Pattern:     regexp.MustCompile(`\b(sk-(?:proj-|ant-)?[A-Za-z0-9_\-]{32,})`),
+		MinEntropy:  3.5,
+	},
+	{
+		ID:          "jwt",
+		Description: "JSON web token",
+		Pattern:     regexp.MustCompile(`\b(eyJ[A-Za-z0-9_\-]{10,}\.eyJ[A-Za-z0-9_\-]{10,}\.[A-Za-z0-9_\-]{10,})`),
+	},
+	{
+		ID:          "url-credentials",
+		Description: "password in URL",
+		Pattern:     regexp.MustCompile(`[a-zA-Z][a-zA-Z0-9+.\-]*://[^\s:/@'"]+:([^\s:/@'"]{3,})@[^\s'"]+`),
+		MinEntropy:  3,
+	},
+	{
+		ID:          "env-secret",
+		Description: "secret in environment file",
+		Pattern:     regexp.MustCompile(`(?im)^[ \t]*(?:export[ \t]+)?[A-Z0-9_]*(?:KEY|SECRET|TOKEN|PASS|PASSWORD|PWD|CREDENTIALS?|AUTH|PRIVATE|DSN)[A-Z0-9_]*[ \t]*=[ \t]*["']?([^\s"'#]{6,})`),
+		Files:       []string{".env", ".env.*", "*.env"},
+	},
+	{
+		ID:          "generic-secret",
+		Description: "hard-coded secret",
+		Pattern:     regexp.MustCompile(`(?i)[\w.\-]*(?:api[_\-]?key|secret|token|passw(?:or)?d|pwd|credential|private[_\-]?key)[\w.\-]*["']?\s*(?::=|=>|:|=)\s*["']([^"'\s]{8,})["']`),
+		MinEntropy:  3.5,
+	},
+}
+
+// placeholder matches values that are obviously not real secrets.
+var placeholder = regexp.MustCompile(`(?i)example|placeholder|changeme|change_me|dummy|sample|your[_\-]|^x+$|^\*+$|\$\{|\{\{|<[a-z_\-]+>|process\.env|os\.getenv`)
+
+// entropy returns the Shannon entropy of s in bits per character.
+func entropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	counts := make(map[rune]int)
+	n := 0
+	for _, r := range s {
+		counts[r]++
+		n++
+	}
+	var h float64
+	for _, c := range counts {
+		p := float64(c) / float64(n)
+		h -= p * math.Log2(p)
+	}
+	return h
+}
@@ -0,0 +1,355 @@
+package secrets
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"project-starter/internal/config"
+	"project-starter/internal/walk"
+)
+
+// maxScanSize is the largest file whose content is scanned. Bigger files are
+// data or build output rather than configuration.
+const maxScanSize = 1 << 20
+
+// allowMarker on a line suppresses findings on it.
+const allowMarker = "secrets:allow"
+
+// skipDirs hold dependencies and build output, which are noisy to scan and
+// regenerated rather than edited.
+var skipDirs = map[string]bool{
+	".git":         true,
+	"node_modules": true,
+	"target":       true,
+	".next":        true,
+	"dist":         true,
+	"build":        true,
+	"vendor":       true,
+	"__pycache__":  true,
+	".venv":        true,
+}
+
+// Finding is a possible secret in a project.
+type Finding struct {
+	// Path is relative to the scanned directory and uses forward slashes.
+	Path        string
+	RuleID      string
+	Description string
+	// Line is 1-based, or 0 when the file was flagged by its name.
+	Line int
+	// Secret is the matched value; Start and End are its byte offsets in
+	// the file.
+	Secret     string
+	Start, End int
+}
+
+// InContent reports whether the finding points at content that can be
+// redacted, rather than at the file as a whole.
+func (f Finding) InContent() bool {
+	return f.Line > 0
+}
+
+// Masked returns the secret with all but its first characters hidden.
+func (f Finding) Masked() string {
+	if f.Secret == "" {
+		return ""
+	}
+	visible := 4
+	if len(f.Secret) <= 8 {
+		visible = 1
+	}
+	return f.Secret[:visible] + strings.Repeat("*", 8)
+}
+
+func (f Finding) String() string {
+	if !f.InContent() {
+		return fmt.Sprintf("%s: %s", f.Path, f.Description)
+	}
+	return fmt.Sprintf("%s:%d: %s (%s)", f.Path, f.Line, f.Description, f.Masked())
+}
+
+// Scanner finds secrets using the built-in rules minus the ones disabled in
+// the config.
+type Scanner struct {
+	rules         []Rule
+	fileRules     []FileRule
+	allowPaths    []string
+	allowPatterns []*regexp.Regexp
+}
+
+func NewScanner(cfg config.SecretsConfig) (*Scanner, error) {
+	disabled := make(map[string]bool)
+	for _, id := range cfg.DisabledRules {
+		disabled[id] = true
+	}
+
+	s := &Scanner{allowPaths: cfg.AllowPaths}
+	for _, rule := range rules {
+		if !disabled[rule.ID] {
+			s.rules = append(s.rules, rule)
+		}
+	}
+	for _, rule := range fileRules {
+		if !disabled[rule.ID] {
+			s.fileRules = append(s.fileRules, rule)
+		}
+	}
+	for _, pattern := range cfg.AllowPatterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid secrets allow pattern %q: %v", pattern, err)
+		}
+		s.allowPatterns = append(s.allowPatterns, re)
+	}
+	return s, nil
+}
+
+// Allowed reports whether the file at the slash separated relative path is
+// on the allowlist.
+func (s *Scanner) Allowed(relPath string) bool {
+	return matchAny(s.allowPaths, relPath, true)
+}
+
+// ScanName checks the file name at relPath against the file rules.
+func (s *Scanner) ScanName(relPath string) []Finding {
+	var findings []Finding
+	for _, rule := range s.fileRules {
+		if matchAny(rule.Names, relPath, false) && !matchAny(rule.Except, relPath, false) {
+			findings = append(findings, Finding{Path: relPath, RuleID: rule.ID, Description: rule.Description})
+		}
+	}
+	return findings
+}
+
+// ScanContent checks data, the content of the file at relPath, against the
+// content rules.
+func (s *Scanner) ScanContent(relPath string, data []byte) []Finding {
+	var findings []Finding
+	covered := func(start, end int) bool {
+		for _, f := range findings {
+			if start < f.End && end > f.Start {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, rule := range s.rules {
+		if len(rule.Files) > 0 && !matchAny(rule.Files, relPath, false) {
+			continue
+		}
+		for _, m := range rule.Pattern.FindAllSubmatchIndex(data, -1) {
+			start, end := m[0], m[1]
+			if len(m) >= 4 && m[2] >= 0 {
+				start, end = m[2], m[3]
+			}
+			secret := string(data[start:end])
+
+			// Earlier rules are more specific, so one match is reported once
+			if covered(start, end) || !s.isSecret(rule, secret) || allowedLine(data, start) {
+				continue
+			}
+			findings = append(findings, Finding{
+				Path:        relPath,
+				RuleID:      rule.ID,
+				Description: rule.Description,
+				Line:        bytes.Count(data[:start], []byte("\n")) + 1,
+				Secret:      secret,
+				Start:       start,
+				End:         end,
+			})
+		}
+	}
+	return findings
+}
+
+func (s *Scanner) isSecret(rule Rule, secret string) bool {
+	if placeholder.MatchString(secret) {
+		return false
+	}
+	if rule.MinEntropy > 0 && entropy(secret) < rule.MinEntropy {
+		return false
+	}
+	for _, re := range s.allowPatterns {
+		if re.MatchString(secret) {
+			return false
+		}
+	}
+	return true
+}
+
+func allowedLine(data []byte, offset int) bool {
+	lineStart := bytes.LastIndexByte(data[:offset], '\n') + 1
+	lineEnd := bytes.IndexByte(data[offset:], '\n')
+	if lineEnd < 0 {
+		lineEnd = len(data)
+	} else {
+		lineEnd += offset
+	}
+	return bytes.Contains(data[lineStart:lineEnd], []byte(allowMarker))
+}
+
+// ScanFile checks the name and, for text files of reasonable size, the
+// content of the file at filePath. relPath is the name used in findings.
+func (s *Scanner) ScanFile(filePath, relPath string, info fs.FileInfo) ([]Finding, error) {
+	if s.Allowed(relPath) || !info.Mode().IsRegular() {
+		return nil, nil
+	}
+	findings := s.ScanName(relPath)
+	if info.Size() > maxScanSize {
+		return findings, nil
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if isBinary(data) {
+		return findings, nil
+	}
+	return append(findings, s.ScanContent(relPath, data)...), nil
+}
+
+// ScanDir scans every file under root, skipping dependency and build
+// directories. Findings are sorted by path and line.
+func (s *Scanner) ScanDir(root string) ([]Finding, error) {
+	type job struct {
+		path, rel string
+		info      fs.FileInfo
+	}
+	jobs := make(chan job)
+
+	var (
+		mu       sync.Mutex
+		findings []Finding
+		firstErr error
+		workers  sync.WaitGroup
+	)
+	for i := 0; i < runtime.NumCPU(); i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for j := range jobs {
+				found, err := s.ScanFile(j.path, j.rel, j.info)
+				mu.Lock()
+				if err != nil && firstErr == nil {
+					firstErr = err
+				}
+				findings = append(findings, found...)
+				mu.Unlock()
+			}
+		}()
+	}
+
+	skip := func(_ string, d fs.DirEntry) bool { return skipDirs[d.Name()] }
+	err := walk.Walk(root, walk.Options{Skip: skip}, func(filePath, rel string, d fs.DirEntry) error {
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		jobs <- job{path: filePath, rel: rel, info: info}
+		return nil
+	})
+	close(jobs)
+	workers.Wait()
+
+	if err == nil {
+		err = firstErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	SortFindings(findings)
+	return findings, nil
+}
+
+func SortFindings(findings []Finding) {
+	sort.Slice(findings, func(i, j int) bool {
+		if findings[i].Path != findings[j].Path {
+			return findings[i].Path < findings[j].Path
+		}
+		return findings[i].Line < findings[j].Line
+	})
+}
+
+// Redact replaces the secrets of content findings in data with a marker.
+func Redact(data []byte, findings []Finding) []byte {
+	var spans []Finding
+	for _, f := range findings {
+		if f.InContent() && f.End <= len(data) {
+			spans = append(spans, f)
+		}
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].Start < spans[j].Start })
+
+	var out bytes.Buffer
+	last := 0
+	for _, f := range spans {
+		if f.Start < last {
+			continue
+		}
+		out.Write(data[last:f.Start])
+		out.WriteString("REDACTED")
+		last = f.End
+	}
+	out.Write(data[last:])
+	return out.Bytes()
+}
+
+// ByPath groups findings by file.
+func ByPath(findings []Finding) map[string][]Finding {
+	files := make(map[string][]Finding)
+	for _, f := range findings {
+		files[f.Path] = append(files[f.Path], f)
+	}
+	return files
+}
+
+// matchAny reports whether relPath matches one of globs. Globs without a
+// slash match the file name, or with dirs set any path element; globs with
+// one match the end of the path at any depth.
+func matchAny(globs []string, relPath string, dirs bool) bool {
+	elements := []string{path.Base(relPath)}
+	if dirs {
+		elements = strings.Split(relPath, "/")
+	}
+	for _, glob := range globs {
+		if !strings.Contains(glob, "/") {
+			for _, element := range elements {
+				if ok, _ := path.Match(glob, element); ok {
+					return true
+				}
+			}
+			continue
+		}
+		for target := relPath; ; {
+			if ok, _ := path.Match(glob, target); ok {
+				return true
+			}
+			i := strings.IndexByte(target, '/')
+			if i < 0 {
+				break
+			}
+			target = target[i+1:]
+		}
+	}
+	return false
+}
+
+func isBinary(data []byte) bool {
+	if len(data) > 8000 {
+		data = data[:8000]
+	}
+	return bytes.IndexByte(data, 0) >= 0
+}
@@ -16,7 +16,7 @@ func SetupCICD(projectPath, projectType string) error {
 	}
 
 	cicdFilePath := filepath.Join(cicdDir, "ci-cd.yml")
-	cicdContent := getCICDContent(projectType)
+	cicdContent := getCICDContent(projectPath, projectType)
 	err = os.WriteFile(cicdFilePath, []byte(cicdContent), 0644)
 	if err != nil {
 		return fmt.Errorf("failed to create CI/CD configuration file: %v", err)
@@ -26,7 +26,7 @@ func SetupCICD(projectPath, projectType string) error {
 	return nil
 }
 
-func getCICDContent(projectType string) string {
+func getCICDContent(projectPath, projectType string) string {
 	switch projectType {
 	case "Go":
 		return `name: Go CI/CD
@@ -41,13 +41,15 @@ jobs:
   build:
     runs-on: ubuntu-latest
     steps:
-    - uses: actions/checkout@v2
+    - uses: actions/checkout@v4
     - name: Set up Go
-      uses: actions/setup-go@v2
+      uses: actions/setup-go@v5
       with:
-        go-version: 1.16
+        go-version-file: go.mod
     - name: Build
       run: go build -v ./...
+    - name: Vet
+      run: go vet ./...
     - name: Test
       run: go test -v ./...`
 	case "Next.js":
@@ -71,6 +73,8 @@ jobs:
     - run: npm ci
     - run: npm run build
     - run: npm test`
+	case "Python":
+		return getPythonCICDContent(DetectPythonProject(projectPath))
 	// Add more project types as needed
 	default:
 		return "# Add your CI/CD configuration here"
@@ -12,30 +12,33 @@ func SetupDocker(projectPath, projectType string) error {
 	dockerfilePath := filepath.Join(projectPath, "Dockerfile")
 	dockerComposeFilePath := filepath.Join(projectPath, "docker-compose.yml")
 
-	dockerfileContent := getDockerfileContent(projectType)
+	dockerfileContent := getDockerfileContent(projectPath, projectType)
 	err := os.WriteFile(dockerfilePath, []byte(dockerfileContent), 0644)
 	if err != nil {
 		return fmt.Errorf("failed to create Dockerfile: %v", err)
 	}
 
-	dockerComposeContent := getDockerComposeContent(projectType)
+	dockerComposeContent := getDockerComposeContent(projectPath, projectType)
 	err = os.WriteFile(dockerComposeFilePath, []byte(dockerComposeContent), 0644)
 	if err != nil {
 		return fmt.Errorf("failed to create docker-compose.yml: %v", err)
 	}
 
+	if projectType == "Python" {
+		err = os.WriteFile(filepath.Join(projectPath, ".dockerignore"), []byte(pythonDockerignore), 0644)
+		if err != nil {
+			return fmt.Errorf("failed to create .dockerignore: %v", err)
+		}
+	}
+
 	color.Green("Docker support added successfully.")
 	return nil
 }
 
-func getDockerfileContent(projectType string) string {
+func getDockerfileContent(projectPath, projectType string) string {
 	switch projectType {
 	case "Go":
-		return `FROM golang:1.16-alpine
-WORKDIR /app
-COPY . .
-RUN go build -o main .
-CMD ["./main"]`
+		return getGoDockerfile(DetectGoProject(projectPath))
 	case "Next.js":
 		return `FROM node:14-alpine
 WORKDIR /app
@@ -44,13 +47,15 @@ RUN npm install
 COPY . .
 RUN npm run build
 CMD ["npm", "start"]`
+	case "Python":
+		return getPythonDockerfile(DetectPythonProject(projectPath))
 	// Add more project types as needed
 	default:
 		return "# Add your Dockerfile content here"
 	}
 }
 
-func getDockerComposeContent(projectType string) string {
+func getDockerComposeContent(projectPath, projectType string) string {
 	switch projectType {
 	case "Go":
 		return `version: '3'
@@ -66,6 +71,8 @@ services:
     build: .
     ports:
       - "3000:3000"`
+	case "Python":
+		return getPythonDockerComposeContent(DetectPythonProject(projectPath))
 	// Add more project types as needed
 	default:
 		return "# Add your docker-compose.yml content here"
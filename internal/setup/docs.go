@@ -0,0 +1,175 @@
+package setup
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// Licenses are the licenses GetLicenseContent can write, by SPDX ID.
+var Licenses = []string{"MIT", "ISC", "BSD-3-Clause"}
+
+func SetupReadme(projectPath, projectName, projectType string) error {
+	readmePath := filepath.Join(projectPath, "README.md")
+	err := os.WriteFile(readmePath, []byte(GetReadmeContent(projectPath, projectName, projectType)), 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create README.md: %v", err)
+	}
+
+	color.Green("README added successfully.")
+	return nil
+}
+
+func SetupLicense(projectPath, license, holder string) error {
+	content, err := GetLicenseContent(license, time.Now().Year(), holder)
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(filepath.Join(projectPath, "LICENSE"), []byte(content), 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create LICENSE: %v", err)
+	}
+
+	color.Green("%s license added successfully.", license)
+	return nil
+}
+
+// GetReadmeContent returns a README with the commands to run, test and
+// build a project of the given type. Go libraries have nothing to run.
+func GetReadmeContent(projectPath, projectName, projectType string) string {
+	var commands string
+	switch projectType {
+	case "Go":
+		if main := DetectGoProject(projectPath).Main; main != "" {
+			commands = fmt.Sprintf("go run %s\ngo test ./...\ngo build %s", main, main)
+		} else {
+			commands = "go test ./...\ngo build ./..."
+		}
+	case "Next.js", "Vite", "Vue", "Node.js":
+		commands = "npm install\nnpm run dev\nnpm test\nnpm run build"
+	case "Rust":
+		commands = "cargo run\ncargo test\ncargo build --release"
+	case "Python":
+		commands = "uv sync  # or poetry install, or pip install -e \".[dev]\" in a venv\npytest\nruff check ."
+	// Add more project types as needed
+	default:
+		commands = "# Add the commands to run and test the project here"
+	}
+
+	return fmt.Sprintf(`# %s
+
+A short description of what %s does.
+
+## Getting started
+
+`+"```sh\n%s\n```"+`
+
+## License
+
+See [LICENSE](LICENSE).
+`, projectName, projectName, commands)
+}
+
+// GetLicenseContent returns the text of one of the Licenses for the given
+// copyright year and holder.
+func GetLicenseContent(license string, year int, holder string) (string, error) {
+	var text string
+	switch license {
+	case "MIT":
+		text = `MIT License
+
+Copyright (c) {year} {holder}
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+`
+	case "ISC":
+		text = `ISC License
+
+Copyright (c) {year} {holder}
+
+Permission to use, copy, modify, and/or distribute this software for any
+purpose with or without fee is hereby granted, provided that the above
+copyright notice and this permission notice appear in all copies.
+
+THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+`
+	case "BSD-3-Clause":
+		text = `BSD 3-Clause License
+
+Copyright (c) {year}, {holder}
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+   list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+   this list of conditions and the following disclaimer in the documentation
+   and/or other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its
+   contributors may be used to endorse or promote products derived from
+   this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+`
+	default:
+		return "", fmt.Errorf("unknown license %q, expected one of %s", license, strings.Join(Licenses, ", "))
+	}
+	return strings.NewReplacer("{year}", fmt.Sprint(year), "{holder}", holder).Replace(text), nil
+}
+
+// DefaultCopyrightHolder returns the git user name, or the login name when
+// git has none.
+func DefaultCopyrightHolder(projectPath string) string {
+	cmd := exec.Command("git", "config", "user.name")
+	cmd.Dir = projectPath
+	if out, err := cmd.Output(); err == nil && strings.TrimSpace(string(out)) != "" {
+		return strings.TrimSpace(string(out))
+	}
+	if u, err := user.Current(); err == nil {
+		if u.Name != "" {
+			return u.Name
+		}
+		return u.Username
+	}
+	return "the authors"
+}
@@ -1,12 +1,19 @@
 package setup
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
+	"strings"
 
+	"github.com/AlecAivazis/survey/v2"
 	"github.com/fatih/color"
+
+	"project-starter/internal/config"
+	"project-starter/internal/secrets"
 )
 
 func SetupGit(projectPath, projectType string) error {
@@ -24,10 +31,113 @@ func SetupGit(projectPath, projectType string) error {
 		return fmt.Errorf("failed to create .gitignore file: %v", err)
 	}
 
+	if err := checkSecrets(projectPath); err != nil {
+		return err
+	}
+
 	color.Green("Git repository initialized and .gitignore created successfully.")
 	return nil
 }
 
+// checkSecrets looks for files with secrets that the new repository doesn't
+// ignore, before anything is committed. The user can add them to .gitignore,
+// keep them, or abort, which removes the repository again. Redacting is not
+// offered since it would rewrite the working files.
+func checkSecrets(projectPath string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	scanner, err := secrets.NewScanner(cfg.Secrets)
+	if err != nil {
+		return err
+	}
+	findings, err := scanner.ScanDir(projectPath)
+	if err != nil {
+		return fmt.Errorf("failed to scan for secrets: %v", err)
+	}
+
+	files := secrets.ByPath(findings)
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	paths, err = unignored(projectPath, paths)
+	if err != nil {
+		return err
+	}
+	if len(paths) == 0 {
+		return nil
+	}
+	sort.Strings(paths)
+
+	color.Yellow("These files may contain secrets and are not ignored by git:")
+	for _, path := range paths {
+		for _, f := range files[path] {
+			color.Yellow("  %s", f)
+		}
+	}
+
+	var selected string
+	err = survey.AskOne(&survey.Select{
+		Message: "What should be done with them?",
+		Options: []string{"Add them to .gitignore", "Keep them", "Abort"},
+	}, &selected)
+	if err != nil {
+		return fmt.Errorf("prompt failed: %v", err)
+	}
+
+	switch selected {
+	case "Add them to .gitignore":
+		file, err := os.OpenFile(filepath.Join(projectPath, ".gitignore"), os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to update .gitignore: %v", err)
+		}
+		defer file.Close()
+		content := "\n\n# Files with secrets\n"
+		for _, path := range paths {
+			content += "/" + path + "\n"
+		}
+		if _, err := file.WriteString(content); err != nil {
+			return fmt.Errorf("failed to update .gitignore: %v", err)
+		}
+	case "Abort":
+		if err := os.RemoveAll(filepath.Join(projectPath, ".git")); err != nil {
+			return fmt.Errorf("failed to remove git repository: %v", err)
+		}
+		return fmt.Errorf("git initialization aborted because of secrets in %d files", len(paths))
+	}
+	return nil
+}
+
+// unignored returns the paths git would not ignore.
+func unignored(projectPath string, paths []string) ([]string, error) {
+	if len(paths) == 0 {
+		return nil, nil
+	}
+	cmd := exec.Command("git", "check-ignore", "--stdin")
+	cmd.Dir = projectPath
+	cmd.Stdin = strings.NewReader(strings.Join(paths, "\n") + "\n")
+	out, err := cmd.Output()
+	// check-ignore exits with 1 when none of the paths are ignored
+	var exitErr *exec.ExitError
+	if err != nil && !(errors.As(err, &exitErr) && exitErr.ExitCode() == 1) {
+		return nil, fmt.Errorf("failed to check ignored files: %v", err)
+	}
+
+	ignored := make(map[string]bool)
+	for _, line := range strings.Split(string(out), "\n") {
+		ignored[line] = true
+	}
+	var result []string
+	for _, path := range paths {
+		if !ignored[path] {
+			result = append(result, path)
+		}
+	}
+	return result, nil
+}
+
 func GetGitignoreContent(projectType string) string {
 	switch projectType {
 	case "Go":
@@ -79,6 +189,29 @@ yarn-error.log*
 
 # Vercel
 .vercel`
+	case "Python":
+		return `# Byte-compiled files
+__pycache__/
+*.py[cod]
+
+# Virtual environments
+.venv/
+venv/
+
+# Distribution and packaging
+build/
+dist/
+*.egg-info/
+
+# Testing and linting
+.pytest_cache/
+.ruff_cache/
+.mypy_cache/
+.coverage
+htmlcov/
+
+# Local env files
+.env`
 	// Add more project types as needed
 	default:
 		return "# Add your .gitignore content here"
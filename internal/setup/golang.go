@@ -0,0 +1,75 @@
+package setup
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// GoProject is what the setup options need to know about a Go project.
+type GoProject struct {
+	// GoVersion is the major and minor version of the go directive, such as
+	// 1.24, or empty without a go.mod.
+	GoVersion string
+	// Main is the package to build, such as ./cmd/server, or empty for
+	// libraries.
+	Main string
+}
+
+var goDirective = regexp.MustCompile(`(?m)^go\s+(\d+\.\d+)`)
+
+// DetectGoProject reads the go directive of go.mod and finds the main
+// package, at the root or under cmd.
+func DetectGoProject(projectPath string) GoProject {
+	var p GoProject
+	if goMod, err := os.ReadFile(filepath.Join(projectPath, "go.mod")); err == nil {
+		if match := goDirective.FindSubmatch(goMod); match != nil {
+			p.GoVersion = string(match[1])
+		}
+	}
+
+	if isMainPackage(filepath.Join(projectPath, "main.go")) {
+		p.Main = "."
+	} else if mains, _ := filepath.Glob(filepath.Join(projectPath, "cmd", "*", "main.go")); len(mains) > 0 {
+		p.Main = "./cmd/" + filepath.Base(filepath.Dir(mains[0]))
+	}
+	return p
+}
+
+var packageMain = regexp.MustCompile(`(?m)^package main\b`)
+
+func isMainPackage(path string) bool {
+	data, err := os.ReadFile(path)
+	return err == nil && packageMain.Match(data)
+}
+
+// getGoDockerfile builds the main package with the Go version go.mod asks
+// for into a static binary on a minimal image. Libraries have nothing to
+// run, so their image runs the tests.
+func getGoDockerfile(p GoProject) string {
+	image := "golang:1-alpine"
+	if p.GoVersion != "" {
+		image = fmt.Sprintf("golang:%s-alpine", p.GoVersion)
+	}
+	if p.Main == "" {
+		return fmt.Sprintf(`FROM %s
+WORKDIR /src
+COPY go.mod go.sum* ./
+RUN go mod download
+COPY . .
+CMD ["go", "test", "./..."]
+`, image)
+	}
+	return fmt.Sprintf(`FROM %s AS build
+WORKDIR /src
+COPY go.mod go.sum* ./
+RUN go mod download
+COPY . .
+RUN CGO_ENABLED=0 go build -o /out/app %s
+
+FROM gcr.io/distroless/static-debian12
+COPY --from=build /out/app /app
+ENTRYPOINT ["/app"]
+`, image, p.Main)
+}
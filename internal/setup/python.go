@@ -0,0 +1,186 @@
+package setup
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// PythonManagers are the tools Python projects can be managed with. pip
+// installs into a venv created by the standard library.
+var PythonManagers = []string{"uv", "poetry", "pip"}
+
+// pythonImage is the base image of generated Python Dockerfiles.
+const pythonImage = "python:3.13-slim"
+
+// PythonProject is what the setup options need to know about a Python
+// project created from a template.
+type PythonProject struct {
+	// Package is the import name of the package under src.
+	Package string
+	// Manager is one of PythonManagers.
+	Manager string
+	// Service is set for projects that run, rather than libraries.
+	Service bool
+}
+
+// DetectPythonProject reads how the Python project at projectPath is laid
+// out. The manager is recognized by its lock file or its sections in
+// pyproject.toml, and services by the __main__.py the template gives them.
+func DetectPythonProject(projectPath string) PythonProject {
+	p := PythonProject{Manager: "pip"}
+	pyproject, _ := os.ReadFile(filepath.Join(projectPath, "pyproject.toml"))
+	switch {
+	case fileExists(filepath.Join(projectPath, "poetry.lock")) || strings.Contains(string(pyproject), "[tool.poetry"):
+		p.Manager = "poetry"
+	case fileExists(filepath.Join(projectPath, "uv.lock")) || strings.Contains(string(pyproject), "[dependency-groups]"):
+		p.Manager = "uv"
+	}
+
+	packages, _ := filepath.Glob(filepath.Join(projectPath, "src", "*", "__init__.py"))
+	if len(packages) > 0 {
+		dir := filepath.Dir(packages[0])
+		p.Package = filepath.Base(dir)
+		p.Service = fileExists(filepath.Join(dir, "__main__.py"))
+	}
+	return p
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// getPythonDockerfile installs the project with its manager. Services run
+// their package; libraries run their tests, as there is nothing else to
+// run.
+func getPythonDockerfile(p PythonProject) string {
+	var install string
+	switch p.Manager {
+	case "uv":
+		flags := "--locked"
+		if p.Service {
+			flags += " --no-dev"
+		}
+		install = fmt.Sprintf(`COPY --from=ghcr.io/astral-sh/uv:0.8 /uv /uvx /bin/
+ENV UV_COMPILE_BYTECODE=1 UV_LINK_MODE=copy
+COPY pyproject.toml uv.lock ./
+RUN uv sync %[1]s --no-install-project
+COPY . .
+RUN uv sync %[1]s
+ENV PATH="/app/.venv/bin:$PATH"`, flags)
+	case "poetry":
+		flags := ""
+		if p.Service {
+			flags = " --only main"
+		}
+		install = fmt.Sprintf(`ENV POETRY_VIRTUALENVS_IN_PROJECT=true
+RUN pip install --no-cache-dir "poetry>=2,<3"
+COPY pyproject.toml poetry.lock ./
+RUN poetry install --no-root%[1]s
+COPY . .
+RUN poetry install%[1]s
+ENV PATH="/app/.venv/bin:$PATH"`, flags)
+	default:
+		target := `".[dev]"`
+		if p.Service {
+			target = "."
+		}
+		install = `COPY . .
+RUN pip install --no-cache-dir ` + target
+	}
+
+	run := `CMD ["python", "-m", "pytest"]`
+	if p.Service {
+		run = fmt.Sprintf("EXPOSE 8000\nCMD [\"python\", \"-m\", \"%s\"]", p.Package)
+	}
+	return fmt.Sprintf("FROM %s\nWORKDIR /app\n%s\n%s\n", pythonImage, install, run)
+}
+
+func getPythonDockerComposeContent(p PythonProject) string {
+	if !p.Service {
+		return `services:
+  app:
+    build: .
+`
+	}
+	return `services:
+  app:
+    build: .
+    ports:
+      - "8000:8000"
+`
+}
+
+// pythonDockerignore keeps the local virtualenv and caches out of the
+// image, where they would shadow what the Dockerfile installs.
+const pythonDockerignore = `.git
+.venv
+__pycache__
+*.py[cod]
+.pytest_cache
+.ruff_cache
+dist
+build
+`
+
+// getPythonCICDContent lints and tests on every supported Python version.
+func getPythonCICDContent(p PythonProject) string {
+	var steps string
+	switch p.Manager {
+	case "uv":
+		steps = `    - uses: astral-sh/setup-uv@v6
+      with:
+        python-version: ${{ matrix.python-version }}
+    - run: uv sync --locked
+    - name: Lint
+      run: |
+        uv run ruff check .
+        uv run ruff format --check .
+    - name: Test
+      run: uv run pytest`
+	case "poetry":
+		steps = `    - uses: actions/setup-python@v5
+      with:
+        python-version: ${{ matrix.python-version }}
+    - run: pipx install "poetry>=2,<3"
+    - run: poetry install
+    - name: Lint
+      run: |
+        poetry run ruff check .
+        poetry run ruff format --check .
+    - name: Test
+      run: poetry run pytest`
+	default:
+		steps = `    - uses: actions/setup-python@v5
+      with:
+        python-version: ${{ matrix.python-version }}
+        cache: pip
+    - run: python -m pip install -e ".[dev]"
+    - name: Lint
+      run: |
+        ruff check .
+        ruff format --check .
+    - name: Test
+      run: pytest`
+	}
+
+	return `name: Python CI/CD
+
+on:
+  push:
+    branches: [ main ]
+  pull_request:
+    branches: [ main ]
+
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    strategy:
+      matrix:
+        python-version: ["3.10", "3.11", "3.12", "3.13"]
+    steps:
+    - uses: actions/checkout@v4
+` + steps + "\n"
+}
@@ -47,6 +47,11 @@ describe('Home', () => {
     expect(heading).toBeInTheDocument()
   })
 })`
+	case "Python":
+		return `def test_sample():
+    # Add your test here
+    assert True
+`
 	// Add more project types as needed
 	default:
 		return "# Add your test content here"
@@ -59,6 +64,8 @@ func getFileExtension(projectType string) string {
 		return "go"
 	case "Next.js":
 		return "js"
+	case "Python":
+		return "py"
 	// Add more project types as needed
 	default:
 		return "txt"
@@ -0,0 +1,194 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// LocalStore keeps backups in a directory, such as a mounted network share or
+// an external disk.
+type LocalStore struct {
+	dir string
+}
+
+func NewLocalStore(dir string) (*LocalStore, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("local store needs a path")
+	}
+	return &LocalStore{dir: dir}, nil
+}
+
+func (s *LocalStore) String() string {
+	return s.dir
+}
+
+// Upload copies to key.part first and renames it when complete. A leftover
+// .part file from an earlier attempt of the same file is appended to rather
+// than rewritten.
+func (s *LocalStore) Upload(ctx context.Context, localPath, key string, progress func(n int64)) error {
+	target := filepath.Join(s.dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(target), os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create %s: %v", filepath.Dir(target), err)
+	}
+
+	src, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+
+	partPath := target + ".part"
+	dst, err := os.OpenFile(partPath, os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	sourcePath := partPath + sourceSuffix
+	marker, _ := os.ReadFile(sourcePath)
+	sameSource := isSource(marker, info)
+	offset, err := resumeOffset(src, dst, sameSource)
+	if err != nil {
+		return err
+	}
+	if !sameSource {
+		if err := os.WriteFile(sourcePath, sourceMarker(info), 0644); err != nil {
+			return err
+		}
+	}
+	if progress != nil && offset > 0 {
+		progress(offset)
+	}
+
+	if _, err := io.Copy(dst, withProgress(contextReader{ctx, src}, progress)); err != nil {
+		return fmt.Errorf("failed to copy to %s: %v", partPath, err)
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(partPath, target); err != nil {
+		return err
+	}
+	os.Remove(sourcePath)
+	return nil
+}
+
+// sourceSuffix is appended to the name of a .part file for the marker of
+// the local file it is a copy of.
+const sourceSuffix = ".source"
+
+// partSource is the marker of the file a .part was copied from. A file
+// with a different size or modification time is a different backup, whose
+// upload must not continue the old .part.
+type partSource struct {
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mtime"`
+}
+
+func sourceMarker(info fs.FileInfo) []byte {
+	data, _ := json.Marshal(partSource{Size: info.Size(), ModTime: info.ModTime()})
+	return data
+}
+
+// isSource reports whether marker was written for the file info describes.
+func isSource(marker []byte, info fs.FileInfo) bool {
+	var source partSource
+	return json.Unmarshal(marker, &source) == nil && source.Size == info.Size() && source.ModTime.Equal(info.ModTime())
+}
+
+// isPartial reports whether key is an unfinished upload or its marker.
+func isPartial(key string) bool {
+	return strings.HasSuffix(key, ".part") || strings.HasSuffix(key, ".part"+sourceSuffix)
+}
+
+// resumeOffset positions src and dst after the part already copied to dst,
+// or truncates dst when it isn't a copy of src.
+func resumeOffset(src io.Seeker, dst interface {
+	io.Seeker
+	Truncate(size int64) error
+}, sameSource bool) (int64, error) {
+	offset, err := dst.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, err
+	}
+	size, err := src.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, err
+	}
+	if !sameSource || offset > size {
+		// The partial upload belongs to a different file, start over
+		if err := dst.Truncate(0); err != nil {
+			return 0, err
+		}
+		offset = 0
+	}
+	if _, err := dst.Seek(offset, io.SeekStart); err != nil {
+		return 0, err
+	}
+	if _, err := src.Seek(offset, io.SeekStart); err != nil {
+		return 0, err
+	}
+	return offset, nil
+}
+
+func (s *LocalStore) List(_ context.Context, prefix string) ([]Object, error) {
+	var objects []Object
+	err := filepath.WalkDir(s.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == s.dir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() || isPartial(path) {
+			return nil
+		}
+		rel, err := filepath.Rel(s.dir, path)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		objects = append(objects, Object{Key: key, Size: info.Size(), Modified: info.ModTime()})
+		return nil
+	})
+	return objects, err
+}
+
+func (s *LocalStore) Open(_ context.Context, key string) (io.ReadCloser, error) {
+	return os.Open(filepath.Join(s.dir, filepath.FromSlash(key)))
+}
+
+func (s *LocalStore) Delete(_ context.Context, key string) error {
+	return os.Remove(filepath.Join(s.dir, filepath.FromSlash(key)))
+}
+
+// contextReader stops a copy once ctx is canceled.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c contextReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLocalStore(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	testStore(t, store)
+}
+
+func TestLocalStoreResume(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewLocalStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testPartResume(t, store, dir)
+}
+
+func TestLocalStoreResumesCanceledUpload(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewLocalStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := testData(1 << 20)
+	localPath := writeTestFile(t, data)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	err = store.Upload(ctx, localPath, "backup.tar.zst", func(int64) { cancel() })
+	if err == nil {
+		t.Fatal("Upload succeeded after its context was canceled")
+	}
+	part, err := os.Stat(filepath.Join(dir, "backup.tar.zst.part"))
+	if err != nil {
+		t.Fatalf("canceled upload left no .part: %v", err)
+	}
+	if part.Size() == 0 || part.Size() >= int64(len(data)) {
+		t.Fatalf("canceled upload left %d of %d bytes", part.Size(), len(data))
+	}
+
+	var first int64 = -1
+	err = store.Upload(context.Background(), localPath, "backup.tar.zst", func(n int64) {
+		if first < 0 {
+			first = n
+		}
+	})
+	if err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	if first != part.Size() {
+		t.Errorf("second upload started at %d, want %d", first, part.Size())
+	}
+	if got := readObject(t, store, "backup.tar.zst"); !bytes.Equal(got, data) {
+		t.Errorf("resumed upload differs from the file")
+	}
+}
@@ -0,0 +1,455 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"project-starter/internal/config"
+)
+
+const (
+	defaultPartSize = 16 << 20
+	minPartSize     = 5 << 20
+	maxParts        = 10000
+	partAttempts    = 3
+)
+
+// S3Store uploads backups to an S3 bucket or an S3-compatible service such as
+// MinIO. Archives larger than one part are sent as multipart uploads whose
+// state is kept next to the local archive, so a failed upload resumes with
+// the parts the service has not received yet.
+type S3Store struct {
+	endpoint  *url.URL
+	pathStyle bool
+	bucket    string
+	prefix    string
+	region    string
+	accessKey string
+	secretKey string
+	partSize  int64
+	client    *http.Client
+}
+
+func NewS3Store(cfg config.StoreConfig) (*S3Store, error) {
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("s3 store needs a bucket")
+	}
+	if cfg.AccessKey == "" || cfg.SecretKey == "" {
+		return nil, fmt.Errorf("s3 store needs access_key and secret_key")
+	}
+
+	region := cfg.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	store := &S3Store{
+		bucket:    cfg.Bucket,
+		prefix:    strings.Trim(cfg.Path, "/"),
+		region:    region,
+		accessKey: cfg.AccessKey,
+		secretKey: cfg.SecretKey,
+		partSize:  defaultPartSize,
+		client:    &http.Client{},
+	}
+	if cfg.PartSizeMB > 0 {
+		store.partSize = int64(cfg.PartSizeMB) << 20
+		if store.partSize < minPartSize {
+			return nil, fmt.Errorf("s3 part_size_mb must be at least 5")
+		}
+	}
+
+	var err error
+	if cfg.Endpoint != "" {
+		store.endpoint, err = url.Parse(cfg.Endpoint)
+		store.pathStyle = true
+	} else {
+		store.endpoint, err = url.Parse(fmt.Sprintf("https://%s.s3.%s.amazonaws.com", cfg.Bucket, region))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid s3 endpoint: %v", err)
+	}
+	return store, nil
+}
+
+func (s *S3Store) String() string {
+	return "s3://" + path.Join(s.bucket, s.prefix)
+}
+
+func (s *S3Store) objectKey(key string) string {
+	if s.prefix == "" {
+		return key
+	}
+	return s.prefix + "/" + key
+}
+
+func (s *S3Store) objectURL(objectKey string, query url.Values) *url.URL {
+	u := *s.endpoint
+	p := "/" + objectKey
+	if s.pathStyle {
+		p = path.Join("/", s.bucket, objectKey)
+	}
+	u.Path = strings.TrimSuffix(s.endpoint.Path, "/") + p
+	u.RawPath = s3EscapePath(u.Path)
+	u.RawQuery = strings.ReplaceAll(query.Encode(), "+", "%20")
+	return &u
+}
+
+func (s *S3Store) do(ctx context.Context, method string, u *url.URL, body []byte, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = int64(len(body))
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("User-Agent", "project-starter-cli")
+
+	payloadHash := emptyPayloadHash
+	if len(body) > 0 {
+		payloadHash = hashHex(body)
+	}
+	signV4(req, s.accessKey, s.secretKey, s.region, payloadHash, time.Now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		return nil, parseS3Error(resp)
+	}
+	return resp, nil
+}
+
+type s3Error struct {
+	Status  string
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+func (e *s3Error) Error() string {
+	if e.Code == "" {
+		return e.Status
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+func parseS3Error(resp *http.Response) error {
+	e := &s3Error{Status: resp.Status}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	xml.Unmarshal(data, e)
+	return e
+}
+
+func isS3Code(err error, code string) bool {
+	var e *s3Error
+	return errors.As(err, &e) && e.Code == code
+}
+
+// multipartState is saved next to the local archive while a multipart upload
+// is in progress.
+type multipartState struct {
+	Key      string    `json:"key"`
+	UploadID string    `json:"upload_id"`
+	PartSize int64     `json:"part_size"`
+	Size     int64     `json:"size"`
+	ModTime  time.Time `json:"mtime"`
+}
+
+type completedPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+	Size       int64  `xml:"Size"`
+}
+
+func (s *S3Store) Upload(ctx context.Context, localPath, key string, progress func(n int64)) error {
+	file, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	objectKey := s.objectKey(key)
+	if info.Size() <= s.partSize {
+		data, err := io.ReadAll(file)
+		if err != nil {
+			return err
+		}
+		resp, err := s.do(ctx, http.MethodPut, s.objectURL(objectKey, nil), data, nil)
+		if err != nil {
+			return fmt.Errorf("upload failed: %v", err)
+		}
+		resp.Body.Close()
+		if progress != nil {
+			progress(int64(len(data)))
+		}
+		return nil
+	}
+
+	statePath := localPath + ".upload.json"
+	state, done, err := s.resumeMultipart(ctx, statePath, objectKey, info)
+	if err != nil {
+		return err
+	}
+
+	partSize := state.PartSize
+	partCount := int((info.Size() + partSize - 1) / partSize)
+	parts := make([]completedPart, 0, partCount)
+	buf := make([]byte, partSize)
+	for number := 1; number <= partCount; number++ {
+		offset := int64(number-1) * partSize
+		size := min(partSize, info.Size()-offset)
+
+		if part, ok := done[number]; ok && part.Size == size {
+			parts = append(parts, part)
+			if progress != nil {
+				progress(size)
+			}
+			continue
+		}
+
+		data := buf[:size]
+		if _, err := file.ReadAt(data, offset); err != nil {
+			return err
+		}
+		etag, err := s.uploadPart(ctx, objectKey, state.UploadID, number, data)
+		if err != nil {
+			return fmt.Errorf("upload of part %d/%d failed, run the upload again to resume: %v", number, partCount, err)
+		}
+		parts = append(parts, completedPart{PartNumber: number, ETag: etag, Size: size})
+		if progress != nil {
+			progress(size)
+		}
+	}
+
+	if err := s.completeMultipart(ctx, objectKey, state.UploadID, parts); err != nil {
+		return err
+	}
+	os.Remove(statePath)
+	return nil
+}
+
+// resumeMultipart continues the upload recorded in statePath if it is for the
+// same file and key, and starts a new one otherwise. It returns the parts the
+// service already has.
+func (s *S3Store) resumeMultipart(ctx context.Context, statePath, objectKey string, info os.FileInfo) (*multipartState, map[int]completedPart, error) {
+	var state multipartState
+	if data, err := os.ReadFile(statePath); err == nil && json.Unmarshal(data, &state) == nil &&
+		state.Key == objectKey && state.Size == info.Size() && state.ModTime.Equal(info.ModTime()) {
+		done, err := s.listParts(ctx, objectKey, state.UploadID)
+		if err == nil {
+			return &state, done, nil
+		}
+		if !isS3Code(err, "NoSuchUpload") {
+			return nil, nil, fmt.Errorf("failed to list uploaded parts: %v", err)
+		}
+	}
+
+	partSize := s.partSize
+	for info.Size()/partSize >= maxParts {
+		partSize *= 2
+	}
+
+	resp, err := s.do(ctx, http.MethodPost, s.objectURL(objectKey, url.Values{"uploads": {""}}), nil, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to start multipart upload: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		UploadID string `xml:"UploadId"`
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse multipart upload response: %v", err)
+	}
+
+	state = multipartState{
+		Key:      objectKey,
+		UploadID: result.UploadID,
+		PartSize: partSize,
+		Size:     info.Size(),
+		ModTime:  info.ModTime(),
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := os.WriteFile(statePath, data, 0644); err != nil {
+		return nil, nil, fmt.Errorf("failed to save upload state: %v", err)
+	}
+	return &state, map[int]completedPart{}, nil
+}
+
+func (s *S3Store) listParts(ctx context.Context, objectKey, uploadID string) (map[int]completedPart, error) {
+	done := make(map[int]completedPart)
+	marker := ""
+	for {
+		query := url.Values{"uploadId": {uploadID}}
+		if marker != "" {
+			query.Set("part-number-marker", marker)
+		}
+		resp, err := s.do(ctx, http.MethodGet, s.objectURL(objectKey, query), nil, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var result struct {
+			Parts                []completedPart `xml:"Part"`
+			IsTruncated          bool            `xml:"IsTruncated"`
+			NextPartNumberMarker string          `xml:"NextPartNumberMarker"`
+		}
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, part := range result.Parts {
+			done[part.PartNumber] = part
+		}
+		if !result.IsTruncated {
+			return done, nil
+		}
+		marker = result.NextPartNumberMarker
+	}
+}
+
+func (s *S3Store) uploadPart(ctx context.Context, objectKey, uploadID string, number int, data []byte) (string, error) {
+	query := url.Values{"partNumber": {strconv.Itoa(number)}, "uploadId": {uploadID}}
+
+	var err error
+	for attempt := 1; attempt <= partAttempts; attempt++ {
+		var resp *http.Response
+		resp, err = s.do(ctx, http.MethodPut, s.objectURL(objectKey, query), data, nil)
+		if err == nil {
+			resp.Body.Close()
+			return resp.Header.Get("ETag"), nil
+		}
+		if attempt == partAttempts {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(time.Duration(attempt) * time.Second):
+		}
+	}
+	return "", err
+}
+
+func (s *S3Store) completeMultipart(ctx context.Context, objectKey, uploadID string, parts []completedPart) error {
+	sort.Slice(parts, func(i, j int) bool { return parts[i].PartNumber < parts[j].PartNumber })
+
+	type part struct {
+		PartNumber int    `xml:"PartNumber"`
+		ETag       string `xml:"ETag"`
+	}
+	body := struct {
+		XMLName xml.Name `xml:"CompleteMultipartUpload"`
+		Parts   []part   `xml:"Part"`
+	}{}
+	for _, p := range parts {
+		body.Parts = append(body.Parts, part{PartNumber: p.PartNumber, ETag: p.ETag})
+	}
+	data, err := xml.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.do(ctx, http.MethodPost, s.objectURL(objectKey, url.Values{"uploadId": {uploadID}}), data,
+		http.Header{"Content-Type": {"application/xml"}})
+	if err != nil {
+		return fmt.Errorf("failed to complete multipart upload: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// S3 can report a failure in the body of a 200 response
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	var e s3Error
+	if xml.Unmarshal(respBody, &e) == nil && e.Code != "" {
+		return fmt.Errorf("failed to complete multipart upload: %v", &e)
+	}
+	return nil
+}
+
+func (s *S3Store) List(ctx context.Context, prefix string) ([]Object, error) {
+	var objects []Object
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {s.objectKey(prefix)}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		bucketURL := s.objectURL("", query)
+		resp, err := s.do(ctx, http.MethodGet, bucketURL, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var result struct {
+			Contents []struct {
+				Key          string    `xml:"Key"`
+				Size         int64     `xml:"Size"`
+				LastModified time.Time `xml:"LastModified"`
+			} `xml:"Contents"`
+			IsTruncated           bool   `xml:"IsTruncated"`
+			NextContinuationToken string `xml:"NextContinuationToken"`
+		}
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, c := range result.Contents {
+			key := c.Key
+			if s.prefix != "" {
+				key = strings.TrimPrefix(key, s.prefix+"/")
+			}
+			objects = append(objects, Object{Key: key, Size: c.Size, Modified: c.LastModified})
+		}
+		if !result.IsTruncated {
+			return objects, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+func (s *S3Store) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	resp, err := s.do(ctx, http.MethodGet, s.objectURL(s.objectKey(key), nil), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, s.objectURL(s.objectKey(key), nil), nil, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
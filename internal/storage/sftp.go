@@ -0,0 +1,260 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"project-starter/internal/config"
+)
+
+// SFTPStore uploads backups to a directory on an SSH server.
+type SFTPStore struct {
+	host      string
+	dir       string
+	sshConfig *ssh.ClientConfig
+}
+
+func NewSFTPStore(cfg config.StoreConfig) (*SFTPStore, error) {
+	if cfg.Host == "" || cfg.User == "" {
+		return nil, fmt.Errorf("sftp store needs a host and user")
+	}
+
+	var auth []ssh.AuthMethod
+	if cfg.KeyFile != "" {
+		key, err := os.ReadFile(expandHome(cfg.KeyFile))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read key file: %v", err)
+		}
+		signer, err := ssh.ParsePrivateKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse key file: %v", err)
+		}
+		auth = append(auth, ssh.PublicKeys(signer))
+	}
+	if cfg.Password != "" {
+		auth = append(auth, ssh.Password(cfg.Password))
+	}
+	if len(auth) == 0 {
+		return nil, fmt.Errorf("sftp store needs a key_file or password")
+	}
+
+	knownHostsPath := cfg.KnownHosts
+	if knownHostsPath == "" {
+		knownHostsPath = "~/.ssh/known_hosts"
+	}
+	hostKeyCallback, err := knownhosts.New(expandHome(knownHostsPath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load known hosts: %v", err)
+	}
+
+	host := cfg.Host
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		host = net.JoinHostPort(host, "22")
+	}
+
+	return &SFTPStore{
+		host: host,
+		dir:  cfg.Path,
+		sshConfig: &ssh.ClientConfig{
+			User:            cfg.User,
+			Auth:            auth,
+			HostKeyCallback: hostKeyCallback,
+		},
+	}, nil
+}
+
+func (s *SFTPStore) String() string {
+	return fmt.Sprintf("sftp://%s@%s/%s", s.sshConfig.User, s.host, strings.TrimPrefix(s.dir, "/"))
+}
+
+func (s *SFTPStore) connect(ctx context.Context) (*sftp.Client, func(), error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", s.host)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to %s: %v", s.host, err)
+	}
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, s.host, s.sshConfig)
+	if err != nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("ssh handshake with %s failed: %v", s.host, err)
+	}
+	sshClient := ssh.NewClient(sshConn, chans, reqs)
+	client, err := sftp.NewClient(sshClient)
+	if err != nil {
+		sshClient.Close()
+		return nil, nil, fmt.Errorf("failed to start sftp session: %v", err)
+	}
+
+	// Close the connection when ctx is canceled to unblock transfers
+	stop := context.AfterFunc(ctx, func() { sshClient.Close() })
+	return client, func() {
+		stop()
+		// Closing the connection first ends the sftp receive loop, which
+		// client.Close otherwise waits on until the server hangs up
+		sshClient.Close()
+		client.Close()
+	}, nil
+}
+
+func (s *SFTPStore) remotePath(key string) string {
+	return path.Join(s.dir, key)
+}
+
+// Upload writes to key.part and renames it when complete. A leftover .part
+// file from an interrupted upload of the same file is continued from its
+// current size.
+func (s *SFTPStore) Upload(ctx context.Context, localPath, key string, progress func(n int64)) error {
+	client, closeClient, err := s.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeClient()
+
+	target := s.remotePath(key)
+	if err := client.MkdirAll(path.Dir(target)); err != nil {
+		return fmt.Errorf("failed to create %s: %v", path.Dir(target), err)
+	}
+
+	src, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+
+	partPath := target + ".part"
+	dst, err := client.OpenFile(partPath, os.O_WRONLY|os.O_CREATE)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %v", partPath, err)
+	}
+	defer dst.Close()
+
+	sourcePath := partPath + sourceSuffix
+	sameSource := false
+	if marker, err := client.Open(sourcePath); err == nil {
+		data, _ := io.ReadAll(marker)
+		marker.Close()
+		sameSource = isSource(data, info)
+	}
+	offset, err := resumeOffset(src, dst, sameSource)
+	if err != nil {
+		return err
+	}
+	if !sameSource {
+		if err := writeSFTPFile(client, sourcePath, sourceMarker(info)); err != nil {
+			return fmt.Errorf("failed to write %s: %v", sourcePath, err)
+		}
+	}
+	if progress != nil && offset > 0 {
+		progress(offset)
+	}
+
+	if _, err := dst.ReadFrom(withProgress(src, progress)); err != nil {
+		return fmt.Errorf("failed to upload to %s: %v", partPath, err)
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+
+	client.Remove(target)
+	if err := client.Rename(partPath, target); err != nil {
+		return fmt.Errorf("failed to rename %s: %v", partPath, err)
+	}
+	client.Remove(sourcePath)
+	return nil
+}
+
+func writeSFTPFile(client *sftp.Client, name string, data []byte) error {
+	file, err := client.Create(name)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func (s *SFTPStore) List(ctx context.Context, prefix string) ([]Object, error) {
+	client, closeClient, err := s.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer closeClient()
+
+	var objects []Object
+	walker := client.Walk(s.dir)
+	for walker.Step() {
+		if err := walker.Err(); err != nil {
+			if os.IsNotExist(err) && walker.Path() == s.dir {
+				return nil, nil
+			}
+			return nil, err
+		}
+		info := walker.Stat()
+		if info.IsDir() || isPartial(walker.Path()) {
+			continue
+		}
+		key := strings.TrimPrefix(strings.TrimPrefix(walker.Path(), s.dir), "/")
+		if strings.HasPrefix(key, prefix) {
+			objects = append(objects, Object{Key: key, Size: info.Size(), Modified: info.ModTime()})
+		}
+	}
+	return objects, nil
+}
+
+func (s *SFTPStore) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	client, closeClient, err := s.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	file, err := client.Open(s.remotePath(key))
+	if err != nil {
+		closeClient()
+		return nil, err
+	}
+	return &sftpReadCloser{File: file, closeClient: closeClient}, nil
+}
+
+func (s *SFTPStore) Delete(ctx context.Context, key string) error {
+	client, closeClient, err := s.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeClient()
+	return client.Remove(s.remotePath(key))
+}
+
+type sftpReadCloser struct {
+	*sftp.File
+	closeClient func()
+}
+
+func (r *sftpReadCloser) Close() error {
+	err := r.File.Close()
+	r.closeClient()
+	return err
+}
+
+func expandHome(p string) string {
+	if p == "~" || strings.HasPrefix(p, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, p[1:])
+		}
+	}
+	return p
+}
@@ -0,0 +1,156 @@
+package storage
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"project-starter/internal/config"
+)
+
+// startSFTPServer serves the local filesystem over SFTP on a random port for
+// the user backup with the password secret. It returns the address and a
+// known_hosts file with the server's key.
+func startSFTPServer(t *testing.T) (string, string) {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverConfig := &ssh.ServerConfig{
+		PasswordCallback: func(conn ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if conn.User() == "backup" && string(password) == "secret" {
+				return nil, nil
+			}
+			return nil, errors.New("wrong password")
+		},
+	}
+	serverConfig.AddHostKey(signer)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveSFTP(conn, serverConfig)
+		}
+	}()
+
+	addr := listener.Addr().String()
+	knownHosts := filepath.Join(t.TempDir(), "known_hosts")
+	line := knownhosts.Line([]string{knownhosts.Normalize(addr)}, signer.PublicKey())
+	if err := os.WriteFile(knownHosts, []byte(line+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return addr, knownHosts
+}
+
+func serveSFTP(conn net.Conn, serverConfig *ssh.ServerConfig) {
+	defer conn.Close()
+	_, channels, requests, err := ssh.NewServerConn(conn, serverConfig)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(requests)
+	for newChannel := range channels {
+		if newChannel.ChannelType() != "session" {
+			newChannel.Reject(ssh.UnknownChannelType, "only sessions are supported")
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			for req := range requests {
+				// The payload is the subsystem name with its length first
+				ok := req.Type == "subsystem" && len(req.Payload) > 4 && string(req.Payload[4:]) == "sftp"
+				req.Reply(ok, nil)
+				if !ok {
+					continue
+				}
+				server, err := sftp.NewServer(channel)
+				if err != nil {
+					channel.Close()
+					return
+				}
+				go func() {
+					server.Serve()
+					channel.Close()
+				}()
+			}
+		}()
+	}
+}
+
+func newTestSFTPStore(t *testing.T, dir string) *SFTPStore {
+	t.Helper()
+	addr, knownHosts := startSFTPServer(t)
+	store, err := NewSFTPStore(config.StoreConfig{
+		Type:       "sftp",
+		Host:       addr,
+		User:       "backup",
+		Password:   "secret",
+		KnownHosts: knownHosts,
+		Path:       dir,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func TestSFTPStore(t *testing.T) {
+	testStore(t, newTestSFTPStore(t, t.TempDir()))
+}
+
+func TestSFTPStoreResume(t *testing.T) {
+	dir := t.TempDir()
+	testPartResume(t, newTestSFTPStore(t, dir), dir)
+}
+
+func TestSFTPStoreRejectsUnknownHost(t *testing.T) {
+	store := newTestSFTPStore(t, t.TempDir())
+	// Trust a different key than the server has
+	_, other, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	knownHosts := filepath.Join(t.TempDir(), "known_hosts")
+	line := knownhosts.Line([]string{knownhosts.Normalize(store.host)}, signer.PublicKey())
+	if err := os.WriteFile(knownHosts, []byte(line+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	store, err = NewSFTPStore(config.StoreConfig{
+		Host: store.host, User: "backup", Password: "secret", KnownHosts: knownHosts, Path: store.dir,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.List(context.Background(), ""); err == nil {
+		t.Error("List connected to a server whose key doesn't match known_hosts")
+	}
+}
@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"project-starter/internal/config"
+)
+
+// BackupStore is a destination for backup archives. Keys use forward slashes
+// regardless of the backend.
+type BackupStore interface {
+	// Upload copies the local file to key, calling progress with the number
+	// of bytes sent. An interrupted upload continues where it stopped when
+	// Upload is called again with the same file and key, if the backend
+	// supports it.
+	Upload(ctx context.Context, localPath, key string, progress func(n int64)) error
+	// List returns the objects whose key starts with prefix.
+	List(ctx context.Context, prefix string) ([]Object, error)
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the object at key.
+	Delete(ctx context.Context, key string) error
+	// String describes the store for messages.
+	String() string
+}
+
+type Object struct {
+	Key      string
+	Size     int64
+	Modified time.Time
+}
+
+// New creates the store described by cfg.
+func New(cfg config.StoreConfig) (BackupStore, error) {
+	switch cfg.Type {
+	case "local":
+		return NewLocalStore(cfg.Path)
+	case "sftp":
+		return NewSFTPStore(cfg)
+	case "webdav":
+		return NewWebDAVStore(cfg)
+	case "s3":
+		return NewS3Store(cfg)
+	default:
+		return nil, fmt.Errorf("unknown backup store type %q", cfg.Type)
+	}
+}
+
+// progressWriter reports bytes written through it.
+type progressWriter struct {
+	progress func(n int64)
+}
+
+func (p progressWriter) Write(b []byte) (int, error) {
+	if p.progress != nil {
+		p.progress(int64(len(b)))
+	}
+	return len(b), nil
+}
+
+// withProgress wraps r so reads are reported to progress.
+func withProgress(r io.Reader, progress func(n int64)) io.Reader {
+	return io.TeeReader(r, progressWriter{progress: progress})
+}
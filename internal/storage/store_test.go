@@ -0,0 +1,142 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testData returns n bytes that don't compress or repeat, so a part sent
+// twice or out of order shows in the result.
+func testData(n int) []byte {
+	data := make([]byte, n)
+	rand.New(rand.NewSource(int64(n))).Read(data)
+	return data
+}
+
+func writeTestFile(t *testing.T, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "backup.tar.zst")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func readObject(t *testing.T, store BackupStore, key string) []byte {
+	t.Helper()
+	r, err := store.Open(context.Background(), key)
+	if err != nil {
+		t.Fatalf("Open(%s): %v", key, err)
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("reading %s: %v", key, err)
+	}
+	return data
+}
+
+// testStore uploads, lists, downloads and deletes backups the way every
+// store must support.
+func testStore(t *testing.T, store BackupStore) {
+	ctx := context.Background()
+	data := testData(300 << 10)
+	localPath := writeTestFile(t, data)
+
+	var sent int64
+	err := store.Upload(ctx, localPath, "app/2024-05-01.tar.zst", func(n int64) { sent += n })
+	if err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	if sent != int64(len(data)) {
+		t.Errorf("progress reported %d bytes, want %d", sent, len(data))
+	}
+	if err := store.Upload(ctx, writeTestFile(t, []byte("other")), "other/2024-05-01.tar.zst", nil); err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+
+	objects, err := store.List(ctx, "app/")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(objects) != 1 || objects[0].Key != "app/2024-05-01.tar.zst" || objects[0].Size != int64(len(data)) {
+		t.Fatalf("List(app/) = %+v, want app/2024-05-01.tar.zst of %d bytes", objects, len(data))
+	}
+	if objects[0].Modified.IsZero() {
+		t.Errorf("List(app/) has no modification time")
+	}
+
+	if got := readObject(t, store, "app/2024-05-01.tar.zst"); !bytes.Equal(got, data) {
+		t.Errorf("downloaded %d bytes that differ from the %d uploaded", len(got), len(data))
+	}
+
+	if err := store.Delete(ctx, "app/2024-05-01.tar.zst"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if objects, err := store.List(ctx, ""); err != nil {
+		t.Fatalf("List: %v", err)
+	} else if len(objects) != 1 || objects[0].Key != "other/2024-05-01.tar.zst" {
+		t.Errorf("List after Delete = %+v, want only other/2024-05-01.tar.zst", objects)
+	}
+}
+
+// testPartResume checks that a store writing key.part files below dir
+// continues the .part of an interrupted upload of the same file, and starts
+// over when the file changed since.
+func testPartResume(t *testing.T, store BackupStore, dir string) {
+	ctx := context.Background()
+	data := testData(200 << 10)
+	localPath := writeTestFile(t, data)
+	info, err := os.Stat(localPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	partPath := filepath.Join(dir, "app", "backup.tar.zst.part")
+	if err := os.MkdirAll(filepath.Dir(partPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	half := int64(len(data) / 2)
+	if err := os.WriteFile(partPath, data[:half], 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(partPath+sourceSuffix, sourceMarker(info), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var reports []int64
+	if err := store.Upload(ctx, localPath, "app/backup.tar.zst", func(n int64) { reports = append(reports, n) }); err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	if len(reports) == 0 || reports[0] != half {
+		t.Errorf("progress started with %v, want the %d bytes already sent", reports, half)
+	}
+	if got := readObject(t, store, "app/backup.tar.zst"); !bytes.Equal(got, data) {
+		t.Errorf("resumed upload differs from the file")
+	}
+	for _, leftover := range []string{partPath, partPath + sourceSuffix} {
+		if _, err := os.Stat(leftover); !os.IsNotExist(err) {
+			t.Errorf("%s is left after the upload", filepath.Base(leftover))
+		}
+	}
+
+	// A .part of an earlier backup written to the same key
+	stale := testData(50 << 10)
+	if err := os.WriteFile(partPath, stale, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(partPath+sourceSuffix, []byte(`{"size":1,"mtime":"2020-01-01T00:00:00Z"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Upload(ctx, localPath, "app/backup.tar.zst", nil); err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	if got := readObject(t, store, "app/backup.tar.zst"); !bytes.Equal(got, data) {
+		t.Errorf("upload over a stale .part differs from the file")
+	}
+}
@@ -0,0 +1,234 @@
+package storage
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"project-starter/internal/config"
+)
+
+// WebDAVStore uploads backups to a WebDAV collection (Nextcloud, ownCloud,
+// Apache mod_dav and similar). WebDAV has no standard way to resume a PUT, so
+// an interrupted upload starts over.
+type WebDAVStore struct {
+	base     *url.URL
+	user     string
+	password string
+	client   *http.Client
+}
+
+func NewWebDAVStore(cfg config.StoreConfig) (*WebDAVStore, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("webdav store needs a url")
+	}
+	base, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webdav url: %v", err)
+	}
+	base.Path = path.Join("/", base.Path, cfg.Path) + "/"
+
+	return &WebDAVStore{
+		base:     base,
+		user:     cfg.User,
+		password: cfg.Password,
+		client:   &http.Client{},
+	}, nil
+}
+
+func (s *WebDAVStore) String() string {
+	return s.base.Redacted()
+}
+
+func (s *WebDAVStore) url(key string) string {
+	u := *s.base
+	u.Path = path.Join(s.base.Path, key)
+	if strings.HasSuffix(key, "/") {
+		u.Path += "/"
+	}
+	return u.String()
+}
+
+func (s *WebDAVStore) do(ctx context.Context, method, target string, body io.Reader, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	if s.user != "" {
+		req.SetBasicAuth(s.user, s.password)
+	}
+	req.Header.Set("User-Agent", "project-starter-cli")
+	return s.client.Do(req)
+}
+
+// mkcol creates the collections leading up to key.
+func (s *WebDAVStore) mkcol(ctx context.Context, key string) error {
+	dir := path.Dir(key)
+	if dir == "." || dir == "/" {
+		dir = ""
+	}
+	var current string
+	for _, part := range append([]string{""}, strings.Split(dir, "/")...) {
+		if part != "" {
+			current = path.Join(current, part)
+		}
+		resp, err := s.do(ctx, "MKCOL", s.url(current+"/"), nil, nil)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		// 405 means the collection already exists
+		if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusMethodNotAllowed {
+			return fmt.Errorf("MKCOL %s: %s", current, resp.Status)
+		}
+	}
+	return nil
+}
+
+func (s *WebDAVStore) Upload(ctx context.Context, localPath, key string, progress func(n int64)) error {
+	if err := s.mkcol(ctx, key); err != nil {
+		return fmt.Errorf("failed to create collection: %v", err)
+	}
+
+	file, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.url(key), withProgress(file, progress))
+	if err != nil {
+		return err
+	}
+	req.ContentLength = info.Size()
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("User-Agent", "project-starter-cli")
+	if s.user != "" {
+		req.SetBasicAuth(s.user, s.password)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("upload failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("upload failed: %s", resp.Status)
+	}
+	return nil
+}
+
+type multistatus struct {
+	Responses []struct {
+		Href     string `xml:"href"`
+		Propstat []struct {
+			Prop struct {
+				ContentLength int64  `xml:"getcontentlength"`
+				LastModified  string `xml:"getlastmodified"`
+				ResourceType  struct {
+					Collection *struct{} `xml:"collection"`
+				} `xml:"resourcetype"`
+			} `xml:"prop"`
+		} `xml:"propstat"`
+	} `xml:"response"`
+}
+
+const propfindBody = `<?xml version="1.0" encoding="utf-8"?>
+<propfind xmlns="DAV:"><prop><getcontentlength/><getlastmodified/><resourcetype/></prop></propfind>`
+
+// List walks the collection tree below the store path with PROPFIND.
+func (s *WebDAVStore) List(ctx context.Context, prefix string) ([]Object, error) {
+	var objects []Object
+	pending := []string{""}
+	for len(pending) > 0 {
+		dir := pending[0]
+		pending = pending[1:]
+
+		resp, err := s.do(ctx, "PROPFIND", s.url(dir+"/"), strings.NewReader(propfindBody), http.Header{
+			"Depth":        {"1"},
+			"Content-Type": {"application/xml"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode == http.StatusNotFound && dir == "" {
+			resp.Body.Close()
+			return nil, nil
+		}
+		if resp.StatusCode != http.StatusMultiStatus {
+			resp.Body.Close()
+			return nil, fmt.Errorf("PROPFIND %s: %s", dir, resp.Status)
+		}
+
+		var result multistatus
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse PROPFIND response: %v", err)
+		}
+
+		for _, r := range result.Responses {
+			href, err := url.PathUnescape(r.Href)
+			if err != nil {
+				continue
+			}
+			if u, err := url.Parse(href); err == nil && u.IsAbs() {
+				href = u.Path
+			}
+			key := strings.Trim(strings.TrimPrefix(href, s.base.Path), "/")
+			if key == "" || key == dir || len(r.Propstat) == 0 {
+				continue
+			}
+
+			prop := r.Propstat[0].Prop
+			if prop.ResourceType.Collection != nil {
+				pending = append(pending, key)
+				continue
+			}
+			if !strings.HasPrefix(key, prefix) {
+				continue
+			}
+			modified, _ := time.Parse(http.TimeFormat, prop.LastModified)
+			objects = append(objects, Object{Key: key, Size: prop.ContentLength, Modified: modified})
+		}
+	}
+	return objects, nil
+}
+
+func (s *WebDAVStore) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	resp, err := s.do(ctx, http.MethodGet, s.url(key), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("GET %s: %s", key, resp.Status)
+	}
+	return resp.Body, nil
+}
+
+func (s *WebDAVStore) Delete(ctx context.Context, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, s.url(key), nil, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("DELETE %s: %s", key, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang.org/x/net/webdav"
+
+	"project-starter/internal/config"
+)
+
+// newTestWebDAVStore serves an in-memory WebDAV tree below /remote.php/dav,
+// as Nextcloud does, for the user backup with the password secret.
+func newTestWebDAVStore(t *testing.T) *WebDAVStore {
+	t.Helper()
+	handler := &webdav.Handler{
+		Prefix:     "/remote.php/dav",
+		FileSystem: webdav.NewMemFS(),
+		LockSystem: webdav.NewMemLS(),
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, password, ok := r.BasicAuth(); !ok || user != "backup" || password != "secret" {
+			w.Header().Set("WWW-Authenticate", `Basic realm="backups"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	store, err := NewWebDAVStore(config.StoreConfig{
+		Type:     "webdav",
+		URL:      server.URL + "/remote.php/dav",
+		Path:     "backups",
+		User:     "backup",
+		Password: "secret",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func TestWebDAVStore(t *testing.T) {
+	testStore(t, newTestWebDAVStore(t))
+}
+
+func TestWebDAVStoreListsMissingCollection(t *testing.T) {
+	objects, err := newTestWebDAVStore(t).List(context.Background(), "")
+	if err != nil || len(objects) != 0 {
+		t.Errorf("List of a store nothing was uploaded to = %v, %v, want nothing", objects, err)
+	}
+}
+
+// WebDAV can't resume a PUT, so uploading again after an interruption
+// sends the whole file.
+func TestWebDAVStoreRestartsCanceledUpload(t *testing.T) {
+	store := newTestWebDAVStore(t)
+	data := testData(1 << 20)
+	localPath := writeTestFile(t, data)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	err := store.Upload(ctx, localPath, "app/backup.tar.zst", func(int64) { cancel() })
+	if err == nil {
+		t.Fatal("Upload succeeded after its context was canceled")
+	}
+
+	var sent int64
+	if err := store.Upload(context.Background(), localPath, "app/backup.tar.zst", func(n int64) { sent += n }); err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	if sent != int64(len(data)) {
+		t.Errorf("second upload sent %d bytes, want all %d", sent, len(data))
+	}
+	if got := readObject(t, store, "app/backup.tar.zst"); !bytes.Equal(got, data) {
+		t.Errorf("uploaded object differs from the file")
+	}
+}
+
+func TestWebDAVStoreRejectsWrongPassword(t *testing.T) {
+	store := newTestWebDAVStore(t)
+	store.password = "wrong"
+	if err := store.Upload(context.Background(), writeTestFile(t, []byte("data")), "backup.tar.zst", nil); err == nil {
+		t.Error("Upload succeeded with the wrong password")
+	}
+}
@@ -0,0 +1,139 @@
+package walk
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+)
+
+// SkipDir can be returned by the callback for a directory to skip its
+// contents, like filepath.SkipDir, which it is.
+var SkipDir = filepath.SkipDir
+
+type Options struct {
+	// Concurrency bounds how many directories are read at the same time.
+	// Zero means one per CPU.
+	Concurrency int
+	// Skip is called for each directory below the root before it is read.
+	// Returning true leaves the directory and its contents out entirely.
+	Skip func(rel string, d fs.DirEntry) bool
+}
+
+// Func is called for each entry below the root. path includes the root and
+// rel is relative to it with forward slashes.
+type Func func(path, rel string, d fs.DirEntry) error
+
+// listing is a directory read in the background.
+type listing struct {
+	entries []fs.DirEntry
+	err     error
+	done    chan struct{}
+}
+
+type walker struct {
+	opts Options
+	sem  chan struct{}
+	fn   Func
+}
+
+// Walk calls fn for every file and directory below root, depth first and
+// in lexical order like filepath.WalkDir, so callers get a deterministic
+// order. While fn runs on the entries of one directory, its subdirectories
+// are already being read by up to opts.Concurrency goroutines, so a walk
+// over a slow or cold disk is not bound by one ReadDir at a time.
+//
+// fn is always called from the goroutine that called Walk. File info is not
+// read unless fn calls d.Info.
+func Walk(root string, opts Options, fn Func) error {
+	if opts.Concurrency < 1 {
+		opts.Concurrency = runtime.NumCPU()
+	}
+	w := &walker{opts: opts, sem: make(chan struct{}, opts.Concurrency), fn: fn}
+	err := w.visit(root, "", w.read(root))
+	if errors.Is(err, SkipDir) {
+		return nil
+	}
+	return err
+}
+
+func (w *walker) read(dir string) *listing {
+	l := &listing{done: make(chan struct{})}
+	go func() {
+		w.sem <- struct{}{}
+		l.entries, l.err = readDir(dir)
+		<-w.sem
+		close(l.done)
+	}()
+	return l
+}
+
+func readDir(dir string) ([]fs.DirEntry, error) {
+	f, err := os.Open(dir)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	entries, err := f.ReadDir(-1)
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, err
+}
+
+func (w *walker) visit(dir, rel string, l *listing) error {
+	<-l.done
+	if l.err != nil {
+		return l.err
+	}
+
+	// Start reading the subdirectories while fn handles this one
+	subdirs := make(map[string]*listing)
+	for _, entry := range l.entries {
+		if entry.IsDir() && !w.skip(rel, entry) {
+			subdirs[entry.Name()] = w.read(filepath.Join(dir, entry.Name()))
+		}
+	}
+
+	for _, entry := range l.entries {
+		path := filepath.Join(dir, entry.Name())
+		entryRel := join(rel, entry.Name())
+		if entry.IsDir() {
+			sub, ok := subdirs[entry.Name()]
+			if !ok {
+				continue
+			}
+			if err := w.fn(path, entryRel, entry); err != nil {
+				if errors.Is(err, SkipDir) {
+					continue
+				}
+				return err
+			}
+			if err := w.visit(path, entryRel, sub); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := w.fn(path, entryRel, entry); err != nil {
+			// As in filepath.WalkDir, SkipDir from a file skips the rest of
+			// its directory
+			if errors.Is(err, SkipDir) {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *walker) skip(rel string, d fs.DirEntry) bool {
+	return w.opts.Skip != nil && w.opts.Skip(join(rel, d.Name()), d)
+}
+
+func join(rel, name string) string {
+	if rel == "" {
+		return name
+	}
+	return rel + "/" + name
+}